@@ -1,6 +1,9 @@
 package main
 
 import (
+	"fmt"
+	"log"
+	"spotigram/internal/blobstore"
 	"spotigram/internal/cache"
 	"spotigram/internal/config"
 	"spotigram/internal/database"
@@ -9,6 +12,7 @@ import (
 	"spotigram/internal/server"
 	serverConfig "spotigram/internal/server/config"
 	serviceAbstractions "spotigram/internal/service/abstractions"
+	"spotigram/internal/service/usecases"
 )
 
 func main() {
@@ -46,11 +50,52 @@ func main() {
 	serviceAbstractions.SongChunkRepositoryInstance =
 		infrastructure.NewCqlSongChunkRepository()
 
+	serviceAbstractions.ReadTimeRepositoryInstance =
+		infrastructure.NewSqlReadTimeRepository()
+
+	serviceAbstractions.BlockRepositoryInstance =
+		infrastructure.NewSqlBlockRepository()
+
+	serviceAbstractions.ChatMuteRepositoryInstance =
+		infrastructure.NewSqlChatMuteRepository()
+
+	serviceAbstractions.ReportRepositoryInstance =
+		infrastructure.NewSqlReportRepository()
+
+	serviceAbstractions.AuditLogRepositoryInstance =
+		infrastructure.NewSqlAuditLogRepository()
+
+	serviceAbstractions.ListenRepositoryInstance =
+		infrastructure.NewSqlListenRepository()
+
+	serviceAbstractions.FollowRepositoryInstance =
+		infrastructure.NewSqlFollowRepository()
+
+	serviceAbstractions.UploadJobRepositoryInstance =
+		infrastructure.NewSqlUploadJobRepository()
+
+	serviceAbstractions.AlbumRepositoryInstance =
+		infrastructure.NewSqlAlbumRepository()
+
+	serviceAbstractions.AccountRepositoryInstance =
+		infrastructure.NewSqlAccountRepository()
+
+	serviceAbstractions.BlobStoreInstance = blobstore.NewBlobStore(&cfg)
+
 	cache.ConnectRedis(&cfg)
 	serviceAbstractions.JWTCacheInstance = cache.NewJWTCache()
 	defer cache.RedisClient.Close()
 
 	serverConfig.SetupConfig(&cfg)
+	usecases.SetupPagination(&cfg)
+	usecases.SetupStreamUrls(&cfg)
+	if err := usecases.MigrateBlobs(); err != nil {
+		panic(fmt.Errorf("error moving files into the blob store: %v", err))
+	}
+	if err := usecases.RunAccountCleanups(); err != nil {
+		log.Printf("Cleanup of deleted accounts failed, retried on the next deletion: %v", err)
+	}
+	usecases.StartSongIngestion(&cfg)
 	serviceAbstractions.ServerInstance =
 		server.NewFiberServer(cfg.App.RequestSizeLimit)
 
@@ -0,0 +1,45 @@
+// Computes the waveform peaks of the songs uploaded
+// before waveforms were generated during ingestion.
+// Files still kept in the database are moved to the
+// blob store first, as the server does on start.
+package main
+
+import (
+	"fmt"
+	"spotigram/internal/blobstore"
+	"spotigram/internal/config"
+	"spotigram/internal/database"
+	"spotigram/internal/infrastructure"
+	infrastructureAbstractions "spotigram/internal/infrastructure/abstractions"
+	serviceAbstractions "spotigram/internal/service/abstractions"
+	"spotigram/internal/service/usecases"
+)
+
+func main() {
+	cfg := config.GetConfig()
+
+	infrastructureAbstractions.SqlDatabaseProviderInstance =
+		database.NewPostgresSqlDatabaseProvider(&cfg)
+	defer infrastructureAbstractions.SqlDatabaseProviderInstance.GetDb().Close()
+
+	serviceAbstractions.SongRepositoryInstance =
+		infrastructure.NewSqlSongRepository()
+	serviceAbstractions.UserRepositoryInstance =
+		infrastructure.NewSqlUserRepository()
+	serviceAbstractions.AlbumRepositoryInstance =
+		infrastructure.NewSqlAlbumRepository()
+
+	serviceAbstractions.BlobStoreInstance = blobstore.NewBlobStore(&cfg)
+
+	if err := usecases.MigrateBlobs(); err != nil {
+		panic(err)
+	}
+
+	done, err := usecases.BackfillWaveforms(func(songId string, err error) {
+		fmt.Printf("Song %s: %v\n", songId, err)
+	})
+	fmt.Printf("Generated %d waveforms\n", done)
+	if err != nil {
+		panic(err)
+	}
+}
@@ -4,6 +4,7 @@ go 1.18
 
 require (
 	github.com/chai2010/webp v1.1.1
+	github.com/dhowden/tag v0.0.0-20240417053706-3d75831295e8
 	github.com/go-playground/validator/v10 v10.19.0
 	github.com/gocql/gocql v1.6.0
 	github.com/gofiber/fiber/v2 v2.52.2
@@ -21,7 +22,6 @@ require (
 	github.com/andybalholm/brotli v1.0.5 // indirect
 	github.com/cespare/xxhash/v2 v2.2.0 // indirect
 	github.com/dgryski/go-rendezvous v0.0.0-20200823014737-9f7001d12a5f // indirect
-	github.com/dhowden/tag v0.0.0-20240417053706-3d75831295e8 // indirect
 	github.com/fasthttp/websocket v1.5.7 // indirect
 	github.com/fsnotify/fsnotify v1.7.0 // indirect
 	github.com/gabriel-vasile/mimetype v1.4.3 // indirect
@@ -0,0 +1,135 @@
+package blobstore
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"spotigram/internal/customerrors"
+)
+
+// A blob store keeping every blob in a file under the root directory,
+// keys are slash separated paths relative to it.
+type LocalBlobStore struct {
+	Root string
+}
+
+// Returns the file path of a key.
+// May return ErrInternal for keys escaping the root.
+func (l *LocalBlobStore) path(key string) (string, error) {
+	if key == "" || strings.Contains(key, "..") {
+		return "", &customerrors.ErrInternal{Message: "invalid blob key"}
+	}
+	return filepath.Join(l.Root, filepath.FromSlash(key)), nil
+}
+
+// Stores size bytes read from r under the key,
+// the blob is written to a temporary file first
+// so that readers never see a partial one.
+// May return ErrInternal on failure.
+func (l *LocalBlobStore) Put(key string, r io.Reader, size int64) error {
+	path, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return &customerrors.ErrInternal{Message: err.Error()}
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".blob-*")
+	if err != nil {
+		return &customerrors.ErrInternal{Message: err.Error()}
+	}
+	defer os.Remove(tmp.Name())
+
+	written, err := io.Copy(tmp, r)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return &customerrors.ErrInternal{Message: err.Error()}
+	}
+	if written != size {
+		return &customerrors.ErrInternal{Message: "blob size mismatch"}
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return &customerrors.ErrInternal{Message: err.Error()}
+	}
+	return nil
+}
+
+// Opens the blob of the key for reading, the caller closes it.
+// May return ErrInternal or ErrNotFound on failure.
+func (l *LocalBlobStore) Get(key string) (io.ReadCloser, int64, error) {
+	path, err := l.path(key)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, 0, &customerrors.ErrNotFound{Message: "blob not found"}
+	} else if err != nil {
+		return nil, 0, &customerrors.ErrInternal{Message: err.Error()}
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, 0, &customerrors.ErrInternal{Message: err.Error()}
+	}
+	return f, info.Size(), nil
+}
+
+// Opens length bytes of the blob of the key starting at offset,
+// the caller closes it.
+// May return ErrInternal or ErrNotFound on failure.
+func (l *LocalBlobStore) GetRange(key string, offset int64, length int64) (io.ReadCloser, error) {
+	f, _, err := l.Get(key)
+	if err != nil {
+		return nil, err
+	}
+
+	file := f.(*os.File)
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		file.Close()
+		return nil, &customerrors.ErrInternal{Message: err.Error()}
+	}
+	return readCloser{io.LimitReader(file, length), file}, nil
+}
+
+// Returns the size of the blob of the key.
+// May return ErrInternal or ErrNotFound on failure.
+func (l *LocalBlobStore) Stat(key string) (int64, error) {
+	path, err := l.path(key)
+	if err != nil {
+		return 0, err
+	}
+
+	info, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, &customerrors.ErrNotFound{Message: "blob not found"}
+	} else if err != nil {
+		return 0, &customerrors.ErrInternal{Message: err.Error()}
+	}
+	return info.Size(), nil
+}
+
+// Deletes the blob of the key, missing blobs are ignored.
+// May return ErrInternal on failure.
+func (l *LocalBlobStore) Delete(key string) error {
+	path, err := l.path(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return &customerrors.ErrInternal{Message: err.Error()}
+	}
+	return nil
+}
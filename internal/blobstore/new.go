@@ -0,0 +1,39 @@
+package blobstore
+
+import (
+	"fmt"
+	"io"
+	"spotigram/internal/config"
+	"spotigram/internal/service/abstractions"
+)
+
+// Returns the blob store of the type set in the config,
+// the bucket of an s3 store is created if needed.
+func NewBlobStore(cfg *config.Config) abstractions.BlobStore {
+	switch cfg.BlobStore.Type {
+	case "", "local":
+		return &LocalBlobStore{Root: cfg.BlobStore.LocalPath}
+
+	case "s3":
+		store := &S3BlobStore{
+			Endpoint:  cfg.BlobStore.S3Endpoint,
+			Region:    cfg.BlobStore.S3Region,
+			Bucket:    cfg.BlobStore.S3Bucket,
+			AccessKey: cfg.BlobStore.S3AccessKey,
+			SecretKey: cfg.BlobStore.S3SecretKey,
+		}
+		if err := store.EnsureBucket(); err != nil {
+			panic(fmt.Errorf("error creating blob store bucket: %v", err))
+		}
+		return store
+	}
+
+	panic(fmt.Errorf("unknown blob store type %q", cfg.BlobStore.Type))
+}
+
+// A reader closing another value once done,
+// used to limit a blob stream while keeping it closable.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,242 @@
+package blobstore
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"spotigram/internal/customerrors"
+)
+
+// A blob store keeping every blob as an object of a bucket
+// of an S3 compatible server, such as MinIO.
+// Requests use path style urls signed with AWS signature version 4.
+type S3BlobStore struct {
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	Client    *http.Client
+}
+
+// Creates the bucket of the store if it does not exist yet.
+// May return ErrInternal on failure.
+func (s *S3BlobStore) EnsureBucket() error {
+	res, err := s.do(http.MethodPut, "", nil, nil, 0)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	// Conflict means the bucket already exists
+	if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusConflict {
+		return s3Error(res)
+	}
+	return nil
+}
+
+// Stores size bytes read from r under the key.
+// May return ErrInternal on failure.
+func (s *S3BlobStore) Put(key string, r io.Reader, size int64) error {
+	res, err := s.do(http.MethodPut, key, nil, r, size)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return s3Error(res)
+	}
+	return nil
+}
+
+// Opens the blob of the key for reading, the caller closes it.
+// May return ErrInternal or ErrNotFound on failure.
+func (s *S3BlobStore) Get(key string) (io.ReadCloser, int64, error) {
+	res, err := s.do(http.MethodGet, key, nil, nil, 0)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if res.StatusCode == http.StatusNotFound {
+		res.Body.Close()
+		return nil, 0, &customerrors.ErrNotFound{Message: "blob not found"}
+	} else if res.StatusCode != http.StatusOK {
+		defer res.Body.Close()
+		return nil, 0, s3Error(res)
+	}
+	return res.Body, res.ContentLength, nil
+}
+
+// Opens length bytes of the blob of the key starting at offset,
+// the caller closes it.
+// May return ErrInternal or ErrNotFound on failure.
+func (s *S3BlobStore) GetRange(key string, offset int64, length int64) (io.ReadCloser, error) {
+	header := http.Header{}
+	header.Set("Range", fmt.Sprintf("bytes=%d-%d", offset, offset+length-1))
+	res, err := s.do(http.MethodGet, key, header, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	switch res.StatusCode {
+	case http.StatusPartialContent:
+		return res.Body, nil
+	case http.StatusOK:
+		// The server ignored the range and sends the whole blob
+		if offset == 0 {
+			return readCloser{io.LimitReader(res.Body, length), res.Body}, nil
+		}
+		res.Body.Close()
+		return nil, &customerrors.ErrInternal{Message: "blob store ignored the range"}
+	case http.StatusNotFound:
+		res.Body.Close()
+		return nil, &customerrors.ErrNotFound{Message: "blob not found"}
+	}
+	defer res.Body.Close()
+	return nil, s3Error(res)
+}
+
+// Returns the size of the blob of the key.
+// May return ErrInternal or ErrNotFound on failure.
+func (s *S3BlobStore) Stat(key string) (int64, error) {
+	res, err := s.do(http.MethodHead, key, nil, nil, 0)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotFound {
+		return 0, &customerrors.ErrNotFound{Message: "blob not found"}
+	} else if res.StatusCode != http.StatusOK {
+		return 0, s3Error(res)
+	}
+	return res.ContentLength, nil
+}
+
+// Deletes the blob of the key, missing blobs are ignored.
+// May return ErrInternal on failure.
+func (s *S3BlobStore) Delete(key string) error {
+	res, err := s.do(http.MethodDelete, key, nil, nil, 0)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusNoContent &&
+		res.StatusCode != http.StatusOK && res.StatusCode != http.StatusNotFound {
+		return s3Error(res)
+	}
+	return nil
+}
+
+// Sends a signed request for an object of the bucket,
+// or for the bucket itself if the key is empty.
+// The headers, if any, are signed along with the request.
+// May return ErrInternal on failure.
+func (s *S3BlobStore) do(method string, key string, header http.Header, body io.Reader, size int64) (*http.Response, error) {
+	path := "/" + s.Bucket
+	if key != "" {
+		for _, segment := range strings.Split(key, "/") {
+			path += "/" + url.PathEscape(segment)
+		}
+	}
+
+	req, err := http.NewRequest(method, strings.TrimSuffix(s.Endpoint, "/")+path, body)
+	if err != nil {
+		return nil, &customerrors.ErrInternal{Message: err.Error()}
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	if body != nil {
+		req.ContentLength = size
+		if size == 0 {
+			// Otherwise the empty body would be sent chunked
+			req.Body = http.NoBody
+		}
+	}
+
+	// Streamed bodies are not hashed
+	signV4(req, "UNSIGNED-PAYLOAD", s.Region, s.AccessKey, s.SecretKey, time.Now().UTC())
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, &customerrors.ErrInternal{Message: err.Error()}
+	}
+	return res, nil
+}
+
+// Returns the error of a failed S3 response.
+func s3Error(res *http.Response) error {
+	message, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+	return &customerrors.ErrInternal{
+		Message: fmt.Sprintf("blob store responded %d: %s", res.StatusCode, message)}
+}
+
+// Signs a request with AWS signature version 4 for the s3 service,
+// every header already set on the request is signed along with the host.
+func signV4(req *http.Request, payloadHash string, region string, accessKey string, secretKey string, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.Query().Encode(),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + region + "/s3/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" +
+		hex.EncodeToString(requestHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+secretKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+accessKey+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+signature)
+}
+
+// Returns the HMAC-SHA256 of the data with the key.
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
@@ -43,6 +43,8 @@ func (j *JWTRedisCache) GetToken(uuid string) (string, error) {
 
 // Sets key-value pair with an expiration time.
 // Key is meant to be a uuid of a JWT, value is meant to be user uuid.
+// The token is also added to the user's token set, which
+// lives as long as the longest living token in it.
 // May return ErrInternal on failure.
 func (j *JWTRedisCache) SetToken(
 	key string, value string, expiresIn time.Duration) error {
@@ -52,5 +54,51 @@ func (j *JWTRedisCache) SetToken(
 	if err != nil {
 		return &customerrors.ErrInternal{Message: err.Error()}
 	}
+
+	setKey := userTokensKey(value)
+	err = j.RedisClient.SAdd(ctx, setKey, key).Err()
+	if err != nil {
+		return &customerrors.ErrInternal{Message: err.Error()}
+	}
+	ttl, err := j.RedisClient.TTL(ctx, setKey).Result()
+	if err != nil {
+		return &customerrors.ErrInternal{Message: err.Error()}
+	}
+	if ttl < expiresIn {
+		err = j.RedisClient.Expire(ctx, setKey, expiresIn).Err()
+		if err != nil {
+			return &customerrors.ErrInternal{Message: err.Error()}
+		}
+	}
 	return nil
 }
+
+// Deletes every token of a user.
+// Returns the number of deleted tokens.
+// May return ErrInternal on failure.
+func (j *JWTRedisCache) DeleteUserTokens(userUUID string) (int64, error) {
+	ctx := context.TODO()
+	setKey := userTokensKey(userUUID)
+	tokens, err := j.RedisClient.SMembers(ctx, setKey).Result()
+	if err != nil {
+		return 0, &customerrors.ErrInternal{Message: err.Error()}
+	}
+
+	var num int64
+	if len(tokens) > 0 {
+		num, err = j.RedisClient.Del(ctx, tokens...).Result()
+		if err != nil {
+			return 0, &customerrors.ErrInternal{Message: err.Error()}
+		}
+	}
+	err = j.RedisClient.Del(ctx, setKey).Err()
+	if err != nil {
+		return 0, &customerrors.ErrInternal{Message: err.Error()}
+	}
+	return num, nil
+}
+
+// Returns the key of the set of a user's tokens.
+func userTokensKey(userUUID string) string {
+	return "user_tokens:" + userUUID
+}
@@ -14,11 +14,17 @@ type (
 		SqlDb        SqlDb
 		CqlDb        CqlDb
 		Cache        Cache
+		BlobStore    BlobStore
+		Websocket    Websocket
+		Pagination   Pagination
+		Ingestion    Ingestion
+		StreamUrl    StreamUrl
 		AccessToken  AccessToken
 		RefreshToken RefreshToken
 	}
 
 	App struct {
+		Environment      string
 		Port             int
 		RequestSizeLimit int
 	}
@@ -46,6 +52,39 @@ type (
 		RedisUrl string
 	}
 
+	BlobStore struct {
+		Type        string
+		LocalPath   string
+		S3Endpoint  string
+		S3Region    string
+		S3Bucket    string
+		S3AccessKey string
+		S3SecretKey string
+	}
+
+	Websocket struct {
+		PingPeriod time.Duration
+		PongWait   time.Duration
+		WriteWait  time.Duration
+	}
+
+	Pagination struct {
+		DefaultPageSize int
+		MaxPageSize     int
+	}
+
+	Ingestion struct {
+		Workers        int
+		QueueSize      int
+		Normalize      bool
+		TargetLoudness float64
+	}
+
+	StreamUrl struct {
+		Secret string
+		TTL    time.Duration
+	}
+
 	AccessToken struct {
 		PublicKeyPath  string
 		PublicKey      []byte
@@ -65,6 +104,10 @@ type (
 	}
 )
 
+// Environment of local setups, where settings
+// required in production may be left out.
+const EnvironmentDevelopment = "development"
+
 // Returns the config object with fields
 // parsed from the configuration file.
 func GetConfig() Config {
@@ -79,6 +122,7 @@ func GetConfig() Config {
 
 	cfg := Config{
 		App: App{
+			Environment:      viper.GetString("app.environment"),
 			Port:             viper.GetInt("app.server.port"),
 			RequestSizeLimit: viper.GetInt("app.server.request_size_limit"),
 		},
@@ -106,6 +150,39 @@ func GetConfig() Config {
 			RedisUrl: viper.GetString("cache.redis_url"),
 		},
 
+		BlobStore: BlobStore{
+			Type:        viper.GetString("blob_store.type"),
+			LocalPath:   viper.GetString("blob_store.local_path"),
+			S3Endpoint:  viper.GetString("blob_store.s3_endpoint"),
+			S3Region:    viper.GetString("blob_store.s3_region"),
+			S3Bucket:    viper.GetString("blob_store.s3_bucket"),
+			S3AccessKey: viper.GetString("blob_store.s3_access_key"),
+			S3SecretKey: viper.GetString("blob_store.s3_secret_key"),
+		},
+
+		Websocket: Websocket{
+			PingPeriod: viper.GetDuration("websocket.ping_period"),
+			PongWait:   viper.GetDuration("websocket.pong_wait"),
+			WriteWait:  viper.GetDuration("websocket.write_wait"),
+		},
+
+		Pagination: Pagination{
+			DefaultPageSize: viper.GetInt("pagination.default_page_size"),
+			MaxPageSize:     viper.GetInt("pagination.max_page_size"),
+		},
+
+		Ingestion: Ingestion{
+			Workers:        viper.GetInt("ingestion.workers"),
+			QueueSize:      viper.GetInt("ingestion.queue_size"),
+			Normalize:      viper.GetBool("ingestion.normalize"),
+			TargetLoudness: viper.GetFloat64("ingestion.target_loudness"),
+		},
+
+		StreamUrl: StreamUrl{
+			Secret: viper.GetString("stream_url.secret"),
+			TTL:    viper.GetDuration("stream_url.ttl"),
+		},
+
 		AccessToken: AccessToken{
 			PrivateKeyPath: viper.GetString("access_token.private_key_path"),
 			PublicKeyPath:  viper.GetString("access_token.public_key_path"),
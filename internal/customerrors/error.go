@@ -1,5 +1,25 @@
 package customerrors
 
+import (
+	"errors"
+	"fmt"
+)
+
+// Machine-readable error codes.
+const (
+	CodeInvalidInput = "invalid_input"
+	CodeUnauthorized = "unauthorized"
+	CodeForbidden    = "forbidden"
+	CodeNotFound     = "not_found"
+	CodeInternal     = "internal"
+)
+
+// A validation failure of a single input field.
+type FieldError struct {
+	Field  string `json:"field"`
+	Reason string `json:"reason"`
+}
+
 // Error meant to signal unauthorized behaviour.
 type ErrUnauthorized struct {
 	Message string
@@ -9,15 +29,38 @@ func (e *ErrUnauthorized) Error() string {
 	return e.Message
 }
 
+// Error meant to signal an authenticated user lacking permission.
+type ErrForbidden struct {
+	Message string
+}
+
+func (e *ErrForbidden) Error() string {
+	return e.Message
+}
+
 // Error meant to signal invalid input.
 type ErrInvalidInput struct {
 	Message string
+	// Fields failing validation, may be empty.
+	Details []FieldError
 }
 
 func (e *ErrInvalidInput) Error() string {
 	return e.Message
 }
 
+// Returns ErrInvalidInput for a single invalid field, reason may be empty.
+func InvalidField(field string, reason string) *ErrInvalidInput {
+	message := fmt.Sprintf("invalid %q", field)
+	if reason != "" {
+		message += " (" + reason + ")"
+	}
+	return &ErrInvalidInput{
+		Message: message,
+		Details: []FieldError{{Field: field, Reason: reason}},
+	}
+}
+
 // Error meant to signal missing content.
 type ErrNotFound struct {
 	Message string
@@ -35,3 +78,54 @@ type ErrInternal struct {
 func (e *ErrInternal) Error() string {
 	return e.Message
 }
+
+// Returns the code of an error, looking through wrapped errors.
+// Errors of other types are internal.
+func Code(err error) string {
+	var invalidInput *ErrInvalidInput
+	var unauthorized *ErrUnauthorized
+	var forbidden *ErrForbidden
+	var notFound *ErrNotFound
+
+	switch {
+	case errors.As(err, &invalidInput):
+		return CodeInvalidInput
+	case errors.As(err, &unauthorized):
+		return CodeUnauthorized
+	case errors.As(err, &forbidden):
+		return CodeForbidden
+	case errors.As(err, &notFound):
+		return CodeNotFound
+	default:
+		return CodeInternal
+	}
+}
+
+// Returns the invalid fields of an error, looking through wrapped errors.
+// Returns nil for errors without any.
+func Details(err error) []FieldError {
+	var invalidInput *ErrInvalidInput
+	if errors.As(err, &invalidInput) {
+		return invalidInput.Details
+	}
+	return nil
+}
+
+// Message shown to clients in place of internal errors,
+// which may expose queries or hosts.
+const InternalMessage = "internal server error"
+
+// Returns the message of an error safe to show to clients,
+// internal errors are replaced by InternalMessage.
+func PublicMessage(err error) string {
+	if Code(err) == CodeInternal {
+		return InternalMessage
+	}
+	return err.Error()
+}
+
+// Reports whether err or an error it wraps is ErrNotFound.
+func IsNotFound(err error) bool {
+	var notFound *ErrNotFound
+	return errors.As(err, &notFound)
+}
@@ -53,3 +53,63 @@ func NewCqlSongChunkRepository() serviceAbstractions.SongChunkRepository {
 	return &repositories.CqlSongChunkRepository{
 		DBProvider: infrastructureAbstractions.CqlDatabaseProviderInstance}
 }
+
+// Returns an sql read time repository.
+func NewSqlReadTimeRepository() serviceAbstractions.ReadTimeRepository {
+	return &repositories.SqlReadTimeRepository{
+		DBProvider: infrastructureAbstractions.SqlDatabaseProviderInstance}
+}
+
+// Returns an sql block repository.
+func NewSqlBlockRepository() serviceAbstractions.BlockRepository {
+	return &repositories.SqlBlockRepository{
+		DBProvider: infrastructureAbstractions.SqlDatabaseProviderInstance}
+}
+
+// Returns an sql chat mute repository.
+func NewSqlChatMuteRepository() serviceAbstractions.ChatMuteRepository {
+	return &repositories.SqlChatMuteRepository{
+		DBProvider: infrastructureAbstractions.SqlDatabaseProviderInstance}
+}
+
+// Returns an sql report repository.
+func NewSqlReportRepository() serviceAbstractions.ReportRepository {
+	return &repositories.SqlReportRepository{
+		DBProvider: infrastructureAbstractions.SqlDatabaseProviderInstance}
+}
+
+// Returns an sql audit log repository.
+func NewSqlAuditLogRepository() serviceAbstractions.AuditLogRepository {
+	return &repositories.SqlAuditLogRepository{
+		DBProvider: infrastructureAbstractions.SqlDatabaseProviderInstance}
+}
+
+// Returns an sql listen repository.
+func NewSqlListenRepository() serviceAbstractions.ListenRepository {
+	return &repositories.SqlListenRepository{
+		DBProvider: infrastructureAbstractions.SqlDatabaseProviderInstance}
+}
+
+// Returns an sql follow repository.
+func NewSqlFollowRepository() serviceAbstractions.FollowRepository {
+	return &repositories.SqlFollowRepository{
+		DBProvider: infrastructureAbstractions.SqlDatabaseProviderInstance}
+}
+
+// Returns an sql upload job repository.
+func NewSqlUploadJobRepository() serviceAbstractions.UploadJobRepository {
+	return &repositories.SqlUploadJobRepository{
+		DBProvider: infrastructureAbstractions.SqlDatabaseProviderInstance}
+}
+
+// Returns an sql album repository.
+func NewSqlAlbumRepository() serviceAbstractions.AlbumRepository {
+	return &repositories.SqlAlbumRepository{
+		DBProvider: infrastructureAbstractions.SqlDatabaseProviderInstance}
+}
+
+// Returns an sql account repository.
+func NewSqlAccountRepository() serviceAbstractions.AccountRepository {
+	return &repositories.SqlAccountRepository{
+		DBProvider: infrastructureAbstractions.SqlDatabaseProviderInstance}
+}
@@ -0,0 +1,146 @@
+package repositories
+
+import (
+	"database/sql"
+	"spotigram/internal/customerrors"
+	"spotigram/internal/infrastructure/abstractions"
+	"spotigram/internal/service/models"
+)
+
+type SqlAccountRepository struct {
+	DBProvider abstractions.SqlDatabaseProvider
+}
+
+// Queries selecting the ids of every kind of cleanup of a deleted account,
+// they run before the rows they read are deleted.
+var accountCleanupQueries = []struct {
+	kind  string
+	query string
+}{
+	{models.AccountCleanupChat, "SELECT chat_id FROM friendships WHERE user1_id = $1 OR user2_id = $1"},
+	{models.AccountCleanupPlaylist, "SELECT id FROM playlists WHERE user_id = $1"},
+	{models.AccountCleanupSong, "SELECT id FROM songs WHERE creator_id = $1"},
+	{models.AccountCleanupAlbum, "SELECT id FROM albums WHERE creator_id = $1"},
+	{models.AccountCleanupUser, "SELECT $1::uuid"},
+}
+
+// Statements deleting the rows of an account, in foreign key order.
+var accountDeleteStatements = []string{
+	`DELETE FROM read_times WHERE chat_id IN
+		(SELECT chat_id FROM friendships WHERE user1_id = $1 OR user2_id = $1)`,
+	`DELETE FROM chat_mutes WHERE chat_id IN
+		(SELECT chat_id FROM friendships WHERE user1_id = $1 OR user2_id = $1)`,
+	"DELETE FROM friendships WHERE user1_id = $1 OR user2_id = $1",
+	"DELETE FROM friend_requests WHERE sender_id = $1 OR recipient_id = $1",
+	"DELETE FROM blocks WHERE blocker_id = $1 OR blocked_id = $1",
+	"DELETE FROM playlists WHERE user_id = $1",
+	"DELETE FROM listens WHERE user_id = $1",
+	"DELETE FROM follows WHERE follower_id = $1 OR followee_id = $1",
+	"DELETE FROM upload_jobs WHERE user_id = $1",
+	"DELETE FROM reports WHERE reporter_id = $1",
+	"UPDATE reports SET resolved_by = NULL WHERE resolved_by = $1",
+	"DELETE FROM songs WHERE creator_id = $1",
+	"DELETE FROM albums WHERE creator_id = $1",
+	"UPDATE audit_log SET moderator_id = NULL WHERE moderator_id = $1",
+	"DELETE FROM users WHERE id = $1",
+}
+
+// Deletes a user with every row referring to them in one transaction,
+// recording the cleanups of their chats, playlists, songs, pictures
+// and sessions, which are kept outside of the database.
+// Returns the ids of the user's former friends.
+// UUID validation is not provided.
+// May return ErrInternal or ErrNotFound on failure.
+func (sar *SqlAccountRepository) DeleteAccount(userId string) ([]string, error) {
+	tx, err := sar.DBProvider.GetDb().Begin()
+	if err != nil {
+		return nil, &customerrors.ErrInternal{Message: err.Error()}
+	}
+	// No-op once committed
+	defer tx.Rollback()
+
+	var id string
+	err = tx.QueryRow("SELECT id FROM users WHERE id = $1 FOR UPDATE", userId).Scan(&id)
+	if err == sql.ErrNoRows {
+		return nil, &customerrors.ErrNotFound{Message: "user not found"}
+	} else if err != nil {
+		return nil, &customerrors.ErrInternal{Message: err.Error()}
+	}
+
+	friendIds := []string{}
+	rows, err := tx.Query(`
+		SELECT CASE WHEN user1_id = $1 THEN user2_id ELSE user1_id END
+		FROM friendships WHERE user1_id = $1 OR user2_id = $1`, userId)
+	if err != nil {
+		return nil, &customerrors.ErrInternal{Message: err.Error()}
+	}
+	for rows.Next() {
+		var friendId string
+		if err := rows.Scan(&friendId); err != nil {
+			rows.Close()
+			return nil, &customerrors.ErrInternal{Message: err.Error()}
+		}
+		friendIds = append(friendIds, friendId)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, &customerrors.ErrInternal{Message: err.Error()}
+	}
+
+	for _, c := range accountCleanupQueries {
+		_, err := tx.Exec(`INSERT INTO account_cleanups (kind, target_id)
+			SELECT $2::varchar, id FROM (`+c.query+`) AS cleanup (id)
+			ON CONFLICT DO NOTHING`, userId, c.kind)
+		if err != nil {
+			return nil, &customerrors.ErrInternal{Message: err.Error()}
+		}
+	}
+	for _, statement := range accountDeleteStatements {
+		if _, err := tx.Exec(statement, userId); err != nil {
+			return nil, &customerrors.ErrInternal{Message: err.Error()}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, &customerrors.ErrInternal{Message: err.Error()}
+	}
+	return friendIds, nil
+}
+
+// Returns at most limit cleanups left by deleted accounts.
+// May return ErrInternal on failure.
+func (sar *SqlAccountRepository) GetAccountCleanups(limit int) ([]models.AccountCleanup, error) {
+	rows, err := sar.DBProvider.GetDb().Query(
+		"SELECT kind, target_id FROM account_cleanups LIMIT $1", limit)
+	if err != nil {
+		return nil, &customerrors.ErrInternal{Message: err.Error()}
+	}
+	defer rows.Close()
+
+	cleanups := []models.AccountCleanup{}
+	for rows.Next() {
+		var c models.AccountCleanup
+		if err := rows.Scan(&c.Kind, &c.TargetId); err != nil {
+			return nil, &customerrors.ErrInternal{Message: err.Error()}
+		}
+		cleanups = append(cleanups, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, &customerrors.ErrInternal{Message: err.Error()}
+	}
+
+	return cleanups, nil
+}
+
+// Deletes a cleanup once done, missing cleanups are ignored.
+// May return ErrInternal on failure.
+func (sar *SqlAccountRepository) DeleteAccountCleanup(cleanup models.AccountCleanup) error {
+	_, err := sar.DBProvider.GetDb().Exec(
+		"DELETE FROM account_cleanups WHERE kind = $1 AND target_id = $2",
+		cleanup.Kind, cleanup.TargetId)
+	if err != nil {
+		return &customerrors.ErrInternal{Message: err.Error()}
+	}
+	return nil
+}
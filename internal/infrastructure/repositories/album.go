@@ -0,0 +1,238 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"spotigram/internal/customerrors"
+	"spotigram/internal/infrastructure/abstractions"
+	"spotigram/internal/service/models"
+)
+
+type SqlAlbumRepository struct {
+	DBProvider abstractions.SqlDatabaseProvider
+}
+
+// Columns read by scanAlbum, in order.
+const albumColumns = `id, creator_id, name, type,
+	to_char(release_date, 'YYYY-MM-DD'), created_at`
+
+// Scans a row of albumColumns into the album.
+func scanAlbum(row interface{ Scan(...any) error }, a *models.Album) error {
+	return row.Scan(&a.Id, &a.CreatorId, &a.Name, &a.Type,
+		&a.ReleaseDate, &a.CreatedAt)
+}
+
+// Adds an album to the repository.
+// May return ErrInternal or ErrInvalidInput on failure.
+func (sar *SqlAlbumRepository) AddAlbum(album models.Album) error {
+	db := sar.DBProvider.GetDb()
+
+	stmt, err := db.Prepare(`INSERT INTO albums
+		(id, creator_id, name, type, release_date, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)`)
+	if err != nil {
+		panic(fmt.Errorf("error preparing AddAlbum SQL statement: %v", err))
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(album.Id, album.CreatorId, album.Name, album.Type,
+		album.ReleaseDate, album.CreatedAt)
+	if err == sql.ErrConnDone {
+		return &customerrors.ErrInternal{Message: "connection is done"}
+	} else if err != nil {
+		return &customerrors.ErrInvalidInput{Message: err.Error()}
+	}
+
+	return nil
+}
+
+// Returns an album by its id.
+// UUID validation is not provided.
+// May return ErrInternal or ErrNotFound on failure.
+func (sar *SqlAlbumRepository) GetAlbum(albumId string) (*models.Album, error) {
+	album := models.Album{}
+	row := sar.DBProvider.GetDb().QueryRow(
+		"SELECT "+albumColumns+" FROM albums WHERE id = $1", albumId)
+	if err := scanAlbum(row, &album); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, &customerrors.ErrNotFound{Message: "album not found"}
+		} else {
+			return nil, &customerrors.ErrInternal{Message: err.Error()}
+		}
+	}
+	return &album, nil
+}
+
+// Returns a page of albums, newest releases first, starting after the cursor.
+// Empty filters are ignored.
+// May return ErrInternal, ErrInvalidInput or ErrNotFound on failure.
+func (sar *SqlAlbumRepository) GetAlbums(cursor string, limit int, nameFilter string, creatorIdFilter string, typeFilter string) (*models.Page[models.Album], error) {
+	var afterDate *string
+	var afterId *string
+	if err := decodeCursor(cursor, &afterDate, &afterId); err != nil {
+		return nil, err
+	}
+
+	var albums []models.Album
+	rows, err := sar.DBProvider.GetDb().Query(
+		`SELECT `+albumColumns+` FROM albums
+		WHERE name LIKE '%' || $1 || '%'
+		AND ($2::text = '' OR creator_id::text = $2)
+		AND ($3::text = '' OR type = $3)
+		AND ($4::date IS NULL OR (release_date, id) < ($4::date, $5::uuid))
+		ORDER BY release_date DESC, id DESC LIMIT $6`,
+		nameFilter, creatorIdFilter, typeFilter, afterDate, afterId, limit+1)
+	if err != nil {
+		return nil, &customerrors.ErrInternal{Message: err.Error()}
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		albums = append(albums, models.Album{})
+		if err := scanAlbum(rows, &albums[len(albums)-1]); err != nil {
+			return nil, &customerrors.ErrInternal{Message: err.Error()}
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, &customerrors.ErrInternal{Message: err.Error()}
+	}
+
+	if len(albums) == 0 {
+		return nil, &customerrors.ErrNotFound{Message: "albums not found"}
+	}
+
+	return newPage(albums, limit, func(a models.Album) []any {
+		return []any{a.ReleaseDate, a.Id}
+	}), nil
+}
+
+// Returns the visible songs of an album ordered by track number,
+// songs without a track number come last.
+// UUID validation is not provided.
+// May return ErrInternal on failure.
+func (sar *SqlAlbumRepository) GetAlbumTracks(albumId string) ([]models.Song, error) {
+	songs := []models.Song{}
+	rows, err := sar.DBProvider.GetDb().Query(`
+		SELECT `+songColumns+` FROM songs
+		WHERE album_id = $1 AND NOT hidden
+		ORDER BY track_number = 0, track_number, name`, albumId)
+	if err != nil {
+		return nil, &customerrors.ErrInternal{Message: err.Error()}
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		songs = append(songs, models.Song{})
+		if err := scanSong(rows, &songs[len(songs)-1]); err != nil {
+			return nil, &customerrors.ErrInternal{Message: err.Error()}
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, &customerrors.ErrInternal{Message: err.Error()}
+	}
+
+	return songs, nil
+}
+
+// Returns the cover of an album left in the table by versions
+// before the blob store, nil if it has none.
+// UUID validation is not provided.
+// May return ErrInternal or ErrNotFound on failure.
+func (sar *SqlAlbumRepository) GetStoredCover(albumId string) ([]byte, error) {
+	var cover []byte
+	err := sar.DBProvider.GetDb().QueryRow(
+		"SELECT cover FROM albums WHERE id = $1", albumId).Scan(&cover)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, &customerrors.ErrNotFound{Message: "album not found"}
+		} else {
+			return nil, &customerrors.ErrInternal{Message: err.Error()}
+		}
+	}
+	return cover, nil
+}
+
+// Returns the ids of at most limit albums with a cover left in the table,
+// ordered by id and starting after afterId.
+// May return ErrInternal on failure.
+func (sar *SqlAlbumRepository) GetAlbumIdsWithStoredCover(afterId string, limit int) ([]string, error) {
+	ids := []string{}
+	rows, err := sar.DBProvider.GetDb().Query(`
+		SELECT id FROM albums
+		WHERE cover IS NOT NULL AND ($1::text = '' OR id > $1::uuid)
+		ORDER BY id LIMIT $2`, afterId, limit)
+	if isUndefinedColumn(err) {
+		return ids, nil
+	} else if err != nil {
+		return nil, &customerrors.ErrInternal{Message: err.Error()}
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, &customerrors.ErrInternal{Message: err.Error()}
+		}
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, &customerrors.ErrInternal{Message: err.Error()}
+	}
+
+	return ids, nil
+}
+
+// Clears the cover of an album left in the table once it is in the blob store.
+// UUID validation is not provided.
+// May return ErrInternal or ErrNotFound on failure.
+func (sar *SqlAlbumRepository) ClearStoredCover(albumId string) error {
+	res, err := sar.DBProvider.GetDb().Exec(
+		"UPDATE albums SET cover = NULL WHERE id = $1", albumId)
+	if err != nil {
+		return &customerrors.ErrInternal{Message: err.Error()}
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return &customerrors.ErrInternal{Message: err.Error()}
+	} else if rowsAffected < 1 {
+		return &customerrors.ErrNotFound{Message: "album not found"}
+	}
+
+	return nil
+}
+
+// Drops the cover column left by versions before the blob store,
+// once every cover in it is in the blob store.
+// May return ErrInternal on failure.
+func (sar *SqlAlbumRepository) DropStoredBlobColumns() error {
+	_, err := sar.DBProvider.GetDb().Exec(
+		"ALTER TABLE albums DROP COLUMN IF EXISTS cover")
+	if err != nil {
+		return &customerrors.ErrInternal{Message: err.Error()}
+	}
+	return nil
+}
+
+// Deletes an album, its songs are kept without an album.
+// UUID validation is not provided.
+// May return ErrInternal or ErrNotFound on failure.
+func (sar *SqlAlbumRepository) DeleteAlbum(albumId string) error {
+	res, err := sar.DBProvider.GetDb().Exec(
+		"DELETE FROM albums WHERE id = $1", albumId)
+	if err != nil {
+		return &customerrors.ErrInternal{Message: err.Error()}
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return &customerrors.ErrInternal{Message: err.Error()}
+	} else if rowsAffected < 1 {
+		return &customerrors.ErrNotFound{Message: "album not found"}
+	}
+
+	return nil
+}
@@ -0,0 +1,83 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"spotigram/internal/customerrors"
+	"spotigram/internal/infrastructure/abstractions"
+	"spotigram/internal/service/models"
+	"time"
+)
+
+type SqlAuditLogRepository struct {
+	DBProvider abstractions.SqlDatabaseProvider
+}
+
+// Adds an entry to the audit log.
+// May return ErrInternal or ErrInvalidInput on failure.
+func (salr *SqlAuditLogRepository) AddEntry(e models.AuditLogEntry) error {
+	db := salr.DBProvider.GetDb()
+
+	stmt, err := db.Prepare(`
+		INSERT INTO audit_log (id, moderator_id, action, target_type, target_id, details, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`)
+	if err != nil {
+		panic(fmt.Errorf("error preparing AddEntry SQL statement: %v", err))
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(e.Id, e.ModeratorId, e.Action, e.TargetType,
+		e.TargetId, e.Details, e.CreatedAt)
+	if err == sql.ErrConnDone {
+		return &customerrors.ErrInternal{Message: "connection is done"}
+	} else if err != nil {
+		return &customerrors.ErrInvalidInput{Message: err.Error()}
+	}
+
+	return nil
+}
+
+// Returns a page of entries, newest first, starting after the cursor.
+// May return ErrInternal, ErrInvalidInput or ErrNotFound on failure.
+func (salr *SqlAuditLogRepository) GetEntries(cursor string, limit int) (*models.Page[models.AuditLogEntry], error) {
+	var afterTime *time.Time
+	var afterId *string
+	if err := decodeCursor(cursor, &afterTime, &afterId); err != nil {
+		return nil, err
+	}
+
+	var entries []models.AuditLogEntry
+	db := salr.DBProvider.GetDb()
+	rows, err := db.Query(`
+		SELECT id, moderator_id, action, target_type, target_id, details, created_at
+		FROM audit_log
+		WHERE $1::timestamp IS NULL OR (created_at, id) < ($1::timestamp, $2::uuid)
+		ORDER BY created_at DESC, id DESC LIMIT $3`, afterTime, afterId, limit+1)
+	if err != nil {
+		return nil, &customerrors.ErrInternal{Message: err.Error()}
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var moderatorId sql.NullString
+		entries = append(entries, models.AuditLogEntry{})
+		e := &entries[len(entries)-1]
+		if err := rows.Scan(&e.Id, &moderatorId, &e.Action, &e.TargetType,
+			&e.TargetId, &e.Details, &e.CreatedAt); err != nil {
+			return nil, &customerrors.ErrInternal{Message: err.Error()}
+		}
+		e.ModeratorId = moderatorId.String
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, &customerrors.ErrInternal{Message: err.Error()}
+	}
+
+	if len(entries) == 0 {
+		return nil, &customerrors.ErrNotFound{Message: "audit log entries not found"}
+	}
+
+	return newPage(entries, limit, func(e models.AuditLogEntry) []any {
+		return []any{e.CreatedAt, e.Id}
+	}), nil
+}
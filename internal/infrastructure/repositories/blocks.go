@@ -0,0 +1,113 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"spotigram/internal/customerrors"
+	"spotigram/internal/infrastructure/abstractions"
+	"spotigram/internal/service/models"
+)
+
+type SqlBlockRepository struct {
+	DBProvider abstractions.SqlDatabaseProvider
+}
+
+// Adds a block to the repository.
+// May return ErrInternal or ErrInvalidInput on failure.
+func (sbr *SqlBlockRepository) AddBlock(b models.Block) error {
+	db := sbr.DBProvider.GetDb()
+
+	stmt, err := db.Prepare("INSERT INTO blocks (blocker_id, blocked_id) VALUES ($1, $2)")
+	if err != nil {
+		panic(fmt.Errorf("error preparing AddBlock SQL statement: %v", err))
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(b.BlockerId, b.BlockedId)
+	if err == sql.ErrConnDone {
+		return &customerrors.ErrInternal{Message: "connection is done"}
+	} else if err != nil {
+		return &customerrors.ErrInvalidInput{Message: err.Error()}
+	}
+
+	return nil
+}
+
+// Deletes a block from the repository.
+// May return ErrInternal or ErrNotFound on failure.
+func (sbr *SqlBlockRepository) DeleteBlock(blocker, blocked string) error {
+	res, err := sbr.DBProvider.GetDb().Exec(
+		"DELETE FROM blocks WHERE blocker_id = $1 AND blocked_id = $2", blocker, blocked)
+	if err != nil {
+		return &customerrors.ErrInternal{Message: err.Error()}
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return &customerrors.ErrInternal{Message: err.Error()}
+	} else if rowsAffected < 1 {
+		return &customerrors.ErrNotFound{Message: "block not found"}
+	}
+
+	return nil
+}
+
+// Returns a page of the blocks made by a user, ordered by blocked id,
+// starting after the cursor.
+// May return ErrInternal, ErrInvalidInput or ErrNotFound on failure.
+func (sbr *SqlBlockRepository) GetBlocks(blocker string, cursor string, limit int) (*models.Page[models.Block], error) {
+	var afterId *string
+	if err := decodeCursor(cursor, &afterId); err != nil {
+		return nil, err
+	}
+
+	var blocks []models.Block
+	db := sbr.DBProvider.GetDb()
+	rows, err := db.Query(`
+		SELECT blocked_id FROM blocks
+		WHERE blocker_id = $1 AND ($2::uuid IS NULL OR blocked_id > $2::uuid)
+		ORDER BY blocked_id LIMIT $3`, blocker, afterId, limit+1)
+	if err != nil {
+		return nil, &customerrors.ErrInternal{Message: err.Error()}
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		blocks = append(blocks, models.Block{
+			BlockerId: blocker,
+		})
+		if err := rows.Scan(&blocks[len(blocks)-1].BlockedId); err != nil {
+			return nil, &customerrors.ErrInternal{Message: err.Error()}
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, &customerrors.ErrInternal{Message: err.Error()}
+	}
+
+	if len(blocks) == 0 {
+		return nil, &customerrors.ErrNotFound{Message: "blocks not found"}
+	}
+
+	return newPage(blocks, limit, func(b models.Block) []any {
+		return []any{b.BlockedId}
+	}), nil
+}
+
+// Checks whether either of the users blocked the other.
+// May return ErrInternal on failure.
+func (sbr *SqlBlockRepository) IsEitherBlocked(uuid1, uuid2 string) (bool, error) {
+	var result string
+	err := sbr.DBProvider.GetDb().QueryRow(
+		`SELECT blocker_id FROM blocks
+		WHERE (blocker_id = $1 AND blocked_id = $2) OR (blocker_id = $2 AND blocked_id = $1)
+		LIMIT 1`, uuid1, uuid2).Scan(&result)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		} else {
+			return false, &customerrors.ErrInternal{Message: err.Error()}
+		}
+	}
+	return true, nil
+}
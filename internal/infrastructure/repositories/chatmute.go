@@ -0,0 +1,83 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"spotigram/internal/customerrors"
+	"spotigram/internal/infrastructure/abstractions"
+)
+
+type SqlChatMuteRepository struct {
+	DBProvider abstractions.SqlDatabaseProvider
+}
+
+// Mutes a chat for a user.
+// May return ErrInternal or ErrInvalidInput on failure.
+func (r *SqlChatMuteRepository) AddMute(userId string, chatId string) error {
+	db := r.DBProvider.GetDb()
+
+	stmt, err := db.Prepare(`
+		INSERT INTO chat_mutes (user_id, chat_id)
+		VALUES ($1, $2)
+		ON CONFLICT DO NOTHING`)
+	if err != nil {
+		panic(fmt.Errorf("error preparing AddMute SQL statement: %v", err))
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(userId, chatId)
+	if err == sql.ErrConnDone {
+		return &customerrors.ErrInternal{Message: "connection is done"}
+	} else if err != nil {
+		return &customerrors.ErrInvalidInput{Message: err.Error()}
+	}
+
+	return nil
+}
+
+// Unmutes a chat for a user.
+// May return ErrInternal or ErrNotFound on failure.
+func (r *SqlChatMuteRepository) DeleteMute(userId string, chatId string) error {
+	res, err := r.DBProvider.GetDb().Exec(
+		"DELETE FROM chat_mutes WHERE user_id = $1 AND chat_id = $2", userId, chatId)
+	if err != nil {
+		return &customerrors.ErrInternal{Message: err.Error()}
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return &customerrors.ErrInternal{Message: err.Error()}
+	} else if rowsAffected < 1 {
+		return &customerrors.ErrNotFound{Message: "chat is not muted"}
+	}
+
+	return nil
+}
+
+// Checks whether the user muted the chat.
+// May return ErrInternal on failure.
+func (r *SqlChatMuteRepository) IsMuted(userId string, chatId string) (bool, error) {
+	var result string
+	err := r.DBProvider.GetDb().QueryRow(
+		"SELECT user_id FROM chat_mutes WHERE user_id = $1 AND chat_id = $2",
+		userId, chatId).Scan(&result)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		} else {
+			return false, &customerrors.ErrInternal{Message: err.Error()}
+		}
+	}
+	return true, nil
+}
+
+// Deletes every mute of a chat.
+// May return ErrInternal on failure.
+func (r *SqlChatMuteRepository) DeleteMutesByChatId(chatId string) error {
+	_, err := r.DBProvider.GetDb().Exec(
+		"DELETE FROM chat_mutes WHERE chat_id = $1", chatId)
+	if err != nil {
+		return &customerrors.ErrInternal{Message: err.Error()}
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"spotigram/internal/customerrors"
+	"spotigram/internal/infrastructure/abstractions"
+	"spotigram/internal/service/models"
+	"time"
+)
+
+type SqlFollowRepository struct {
+	DBProvider abstractions.SqlDatabaseProvider
+}
+
+// Adds a follow to the repository.
+// May return ErrInternal or ErrInvalidInput on failure.
+func (sfr *SqlFollowRepository) AddFollow(f models.Follow) error {
+	db := sfr.DBProvider.GetDb()
+
+	stmt, err := db.Prepare("INSERT INTO follows (follower_id, followee_id, created_at) VALUES ($1, $2, $3)")
+	if err != nil {
+		panic(fmt.Errorf("error preparing AddFollow SQL statement: %v", err))
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(f.FollowerId, f.FolloweeId, f.CreatedAt)
+	if err == sql.ErrConnDone {
+		return &customerrors.ErrInternal{Message: "connection is done"}
+	} else if err != nil {
+		return &customerrors.ErrInvalidInput{Message: err.Error()}
+	}
+
+	return nil
+}
+
+// Deletes a follow from the repository.
+// May return ErrInternal or ErrNotFound on failure.
+func (sfr *SqlFollowRepository) DeleteFollow(follower, followee string) error {
+	res, err := sfr.DBProvider.GetDb().Exec(
+		"DELETE FROM follows WHERE follower_id = $1 AND followee_id = $2", follower, followee)
+	if err != nil {
+		return &customerrors.ErrInternal{Message: err.Error()}
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return &customerrors.ErrInternal{Message: err.Error()}
+	} else if rowsAffected < 1 {
+		return &customerrors.ErrNotFound{Message: "follow not found"}
+	}
+
+	return nil
+}
+
+// Checks whether the follower follows the followee.
+// May return ErrInternal on failure.
+func (sfr *SqlFollowRepository) DoesFollowExist(follower, followee string) (bool, error) {
+	var result string
+	err := sfr.DBProvider.GetDb().QueryRow(
+		"SELECT follower_id FROM follows WHERE follower_id = $1 AND followee_id = $2",
+		follower, followee).Scan(&result)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		} else {
+			return false, &customerrors.ErrInternal{Message: err.Error()}
+		}
+	}
+	return true, nil
+}
+
+// Returns a page of the follows of a followee, newest first, starting after the cursor.
+// May return ErrInternal, ErrInvalidInput or ErrNotFound on failure.
+func (sfr *SqlFollowRepository) GetFollowers(followee string, cursor string, limit int) (*models.Page[models.Follow], error) {
+	return sfr.getFollows("followee_id", "follower_id", followee, cursor, limit)
+}
+
+// Returns a page of the follows of a follower, newest first, starting after the cursor.
+// May return ErrInternal, ErrInvalidInput or ErrNotFound on failure.
+func (sfr *SqlFollowRepository) GetFollowing(follower string, cursor string, limit int) (*models.Page[models.Follow], error) {
+	return sfr.getFollows("follower_id", "followee_id", follower, cursor, limit)
+}
+
+// Returns the number of followers and followed users of a user.
+// May return ErrInternal on failure.
+func (sfr *SqlFollowRepository) CountFollows(uuid string) (*models.FollowCounts, error) {
+	counts := models.FollowCounts{}
+	err := sfr.DBProvider.GetDb().QueryRow(`
+		SELECT
+		(SELECT COUNT(*) FROM follows WHERE followee_id = $1),
+		(SELECT COUNT(*) FROM follows WHERE follower_id = $1)`, uuid).Scan(
+		&counts.Followers, &counts.Following)
+	if err != nil {
+		return nil, &customerrors.ErrInternal{Message: err.Error()}
+	}
+	return &counts, nil
+}
+
+// column is either follower_id or followee_id, otherColumn the other one,
+// which breaks ties between follows made at the same time.
+func (sfr *SqlFollowRepository) getFollows(column string, otherColumn string, uuid string, cursor string, limit int) (*models.Page[models.Follow], error) {
+	var afterTime *time.Time
+	var afterId *string
+	if err := decodeCursor(cursor, &afterTime, &afterId); err != nil {
+		return nil, err
+	}
+
+	var follows []models.Follow
+	rows, err := sfr.DBProvider.GetDb().Query(`
+		SELECT follower_id, followee_id, created_at FROM follows
+		WHERE `+column+` = $1
+		AND ($2::timestamp IS NULL OR (created_at, `+otherColumn+`) < ($2::timestamp, $3::uuid))
+		ORDER BY created_at DESC, `+otherColumn+` DESC LIMIT $4`,
+		uuid, afterTime, afterId, limit+1)
+	if err != nil {
+		return nil, &customerrors.ErrInternal{Message: err.Error()}
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		follows = append(follows, models.Follow{})
+		f := &follows[len(follows)-1]
+		if err := rows.Scan(&f.FollowerId, &f.FolloweeId, &f.CreatedAt); err != nil {
+			return nil, &customerrors.ErrInternal{Message: err.Error()}
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, &customerrors.ErrInternal{Message: err.Error()}
+	}
+
+	if len(follows) == 0 {
+		return nil, &customerrors.ErrNotFound{Message: "follows not found"}
+	}
+
+	return newPage(follows, limit, func(f models.Follow) []any {
+		if otherColumn == "follower_id" {
+			return []any{f.CreatedAt, f.FollowerId}
+		}
+		return []any{f.CreatedAt, f.FolloweeId}
+	}), nil
+}
@@ -12,22 +12,24 @@ type SqlFriendRequestRepository struct {
 	DBProvider abstractions.SqlDatabaseProvider
 }
 
-// Returns every friend request sent by a user.
-// May return ErrInternal or ErrNotFound on failure.
-func (sfrr *SqlFriendRequestRepository) GetFriendRequestsSent(uuid1 string, offset int) ([]models.FriendRequest, error) {
-	if offset < 0 {
-		return nil, &customerrors.ErrInternal{Message: "invalid offset"}
+// Returns a page of the friend requests sent by a user,
+// ordered by recipient id, starting after the cursor.
+// May return ErrInternal, ErrInvalidInput or ErrNotFound on failure.
+func (sfrr *SqlFriendRequestRepository) GetFriendRequestsSent(uuid1 string, cursor string, limit int) (*models.Page[models.FriendRequest], error) {
+	var afterId *string
+	if err := decodeCursor(cursor, &afterId); err != nil {
+		return nil, err
 	}
+
 	var friendRequests []models.FriendRequest
-	db := sfrr.DBProvider.GetDb()
-	rows, err := db.Query("SELECT recipient_id, is_ignored FROM friend_requests WHERE sender_id = $1 LIMIT 100 OFFSET $2", uuid1, offset)
+	rows, err := sfrr.DBProvider.GetDb().Query(`
+		SELECT recipient_id, is_ignored FROM friend_requests
+		WHERE sender_id = $1 AND ($2::uuid IS NULL OR recipient_id > $2::uuid)
+		ORDER BY recipient_id LIMIT $3`, uuid1, afterId, limit+1)
 	if err != nil {
-		if err != sql.ErrNoRows {
-			return nil, &customerrors.ErrInternal{Message: err.Error()}
-		} else {
-			return nil, &customerrors.ErrNotFound{Message: "friend requests not found"}
-		}
+		return nil, &customerrors.ErrInternal{Message: err.Error()}
 	}
+	defer rows.Close()
 
 	for rows.Next() {
 		friendRequests = append(friendRequests, models.FriendRequest{
@@ -43,31 +45,33 @@ func (sfrr *SqlFriendRequestRepository) GetFriendRequestsSent(uuid1 string, offs
 		return nil, &customerrors.ErrInternal{Message: err.Error()}
 	}
 
-	rows.Close()
-
 	if len(friendRequests) == 0 {
 		return nil, &customerrors.ErrNotFound{Message: "friend requests not found"}
 	}
 
-	return friendRequests, nil
+	return newPage(friendRequests, limit, func(f models.FriendRequest) []any {
+		return []any{f.RecipientId}
+	}), nil
 }
 
-// Returns every friend request recieved by a user.
-// May return ErrInternal or ErrNotFound on failure.
-func (sfrr *SqlFriendRequestRepository) GetFriendRequestsReceived(uuid1 string, offset int) ([]models.FriendRequest, error) {
-	if offset < 0 {
-		return nil, &customerrors.ErrInternal{Message: "invalid offset"}
+// Returns a page of the friend requests received by a user,
+// ordered by sender id, starting after the cursor.
+// May return ErrInternal, ErrInvalidInput or ErrNotFound on failure.
+func (sfrr *SqlFriendRequestRepository) GetFriendRequestsReceived(uuid1 string, cursor string, limit int) (*models.Page[models.FriendRequest], error) {
+	var afterId *string
+	if err := decodeCursor(cursor, &afterId); err != nil {
+		return nil, err
 	}
+
 	var friendRequests []models.FriendRequest
-	db := sfrr.DBProvider.GetDb()
-	rows, err := db.Query("SELECT sender_id, is_ignored FROM friend_requests WHERE recipient_id = $1 LIMIT 100 OFFSET $2", uuid1, offset)
+	rows, err := sfrr.DBProvider.GetDb().Query(`
+		SELECT sender_id, is_ignored FROM friend_requests
+		WHERE recipient_id = $1 AND ($2::uuid IS NULL OR sender_id > $2::uuid)
+		ORDER BY sender_id LIMIT $3`, uuid1, afterId, limit+1)
 	if err != nil {
-		if err != sql.ErrNoRows {
-			return nil, &customerrors.ErrInternal{Message: err.Error()}
-		} else {
-			return nil, &customerrors.ErrNotFound{Message: "friends requests not found"}
-		}
+		return nil, &customerrors.ErrInternal{Message: err.Error()}
 	}
+	defer rows.Close()
 
 	for rows.Next() {
 		friendRequests = append(friendRequests, models.FriendRequest{
@@ -83,13 +87,13 @@ func (sfrr *SqlFriendRequestRepository) GetFriendRequestsReceived(uuid1 string,
 		return nil, &customerrors.ErrInternal{Message: err.Error()}
 	}
 
-	rows.Close()
-
 	if len(friendRequests) == 0 {
 		return nil, &customerrors.ErrNotFound{Message: "friend requests not found"}
 	}
 
-	return friendRequests, nil
+	return newPage(friendRequests, limit, func(f models.FriendRequest) []any {
+		return []any{f.SenderId}
+	}), nil
 }
 
 // Adds a friend request to the repository.
@@ -6,52 +6,34 @@ import (
 	"spotigram/internal/customerrors"
 	"spotigram/internal/infrastructure/abstractions"
 	"spotigram/internal/service/models"
+
+	"github.com/lib/pq"
 )
 
 type SqlFriendRepository struct {
 	DBProvider abstractions.SqlDatabaseProvider
 }
 
-// Returns every friend of a user by his id.
-// May return ErrInternal or ErrNotFound on failure.
-func (sdm *SqlFriendRepository) GetFriends(uuid1 string, offset int) ([]models.Friend, error) {
-	var friends []models.Friend
-
-	if offset < 0 {
-		return nil, &customerrors.ErrInternal{Message: "invalid offset"}
+// Returns a page of a user's friends ordered by friend id, starting after the cursor.
+// May return ErrInternal, ErrInvalidInput or ErrNotFound on failure.
+func (sdm *SqlFriendRepository) GetFriends(uuid1 string, cursor string, limit int) (*models.Page[models.Friend], error) {
+	var afterId *string
+	if err := decodeCursor(cursor, &afterId); err != nil {
+		return nil, err
 	}
 
-	db := sdm.DBProvider.GetDb()
-	rows, err := db.Query("SELECT user2_id, chat_id FROM friendships WHERE user1_id = $1 OFFSET $2 LIMIT 100", uuid1, offset)
+	var friends []models.Friend
+	rows, err := sdm.DBProvider.GetDb().Query(`
+		SELECT friend_id, chat_id FROM (
+			SELECT user2_id AS friend_id, chat_id FROM friendships WHERE user1_id = $1
+			UNION ALL
+			SELECT user1_id, chat_id FROM friendships WHERE user2_id = $1) f
+		WHERE $2::uuid IS NULL OR friend_id > $2::uuid
+		ORDER BY friend_id LIMIT $3`, uuid1, afterId, limit+1)
 	if err != nil {
-		if err != sql.ErrNoRows {
-			return nil, &customerrors.ErrInternal{Message: err.Error()}
-		} else {
-			return nil, &customerrors.ErrNotFound{Message: "friends not found"}
-		}
-	}
-
-	for rows.Next() {
-		friends = append(friends, models.Friend{
-			Id1: uuid1,
-		})
-		if err := rows.Scan(&friends[len(friends)-1].Id2, &friends[len(friends)-1].ChatId); err != nil {
-			return nil, &customerrors.ErrInternal{Message: err.Error()}
-		}
-	}
-
-	if err := rows.Err(); err != nil {
 		return nil, &customerrors.ErrInternal{Message: err.Error()}
 	}
-
-	rows.Close()
-
-	rows, err = db.Query("SELECT user1_id, chat_id FROM friendships WHERE user2_id = $1", uuid1)
-	if err != nil {
-		if err != sql.ErrNoRows {
-			return nil, &customerrors.ErrInternal{Message: err.Error()}
-		}
-	}
+	defer rows.Close()
 
 	for rows.Next() {
 		friends = append(friends, models.Friend{
@@ -66,13 +48,13 @@ func (sdm *SqlFriendRepository) GetFriends(uuid1 string, offset int) ([]models.F
 		return nil, &customerrors.ErrInternal{Message: err.Error()}
 	}
 
-	rows.Close()
-
 	if len(friends) == 0 {
 		return nil, &customerrors.ErrNotFound{Message: "friends not found"}
 	}
 
-	return friends, nil
+	return newPage(friends, limit, func(f models.Friend) []any {
+		return []any{f.Id2}
+	}), nil
 }
 
 // Returns a chat id of between friends.
@@ -188,3 +170,144 @@ func (sfm *SqlFriendRepository) DoesFriendExist(uuid1, uuid2 string) (bool, erro
 	}
 	return true, nil
 }
+
+// Returns the number of a user's friends.
+// UUID validation is not provided.
+// May return ErrInternal on failure.
+func (sfm *SqlFriendRepository) CountFriends(uuid string) (int, error) {
+	var count int
+	err := sfm.DBProvider.GetDb().QueryRow(
+		"SELECT COUNT(*) FROM friendships WHERE user1_id = $1 OR user2_id = $1",
+		uuid).Scan(&count)
+	if err != nil {
+		return 0, &customerrors.ErrInternal{Message: err.Error()}
+	}
+	return count, nil
+}
+
+// Checks whether two users have a friend in common.
+// UUID validation is not provided.
+// May return ErrInternal on failure.
+func (sfm *SqlFriendRepository) HasMutualFriend(uuid1, uuid2 string) (bool, error) {
+	var result string
+	err := sfm.DBProvider.GetDb().QueryRow(`
+		SELECT a.chat_id FROM friendships a JOIN friendships b
+		ON (CASE WHEN a.user1_id = $1 THEN a.user2_id ELSE a.user1_id END) =
+		(CASE WHEN b.user1_id = $2 THEN b.user2_id ELSE b.user1_id END)
+		WHERE (a.user1_id = $1 OR a.user2_id = $1)
+		AND (b.user1_id = $2 OR b.user2_id = $2)
+		LIMIT 1`, uuid1, uuid2).Scan(&result)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		} else {
+			return false, &customerrors.ErrInternal{Message: err.Error()}
+		}
+	}
+	return true, nil
+}
+
+// Returns a page of users the user is not related to, ranked by mutual
+// friends and by their listens of the user's listened songs or songIds,
+// starting after the cursor.
+// Shared songs are counted over at most 200 of the user's songs, the latest
+// listened ones first, and at most 1000 listeners of each song.
+// Friends, pending friend requests either way, blocks either way, suspended,
+// undiscoverable users, users accepting no friend requests and users accepting
+// them only from friends of friends without a mutual friend are left out.
+// A mutual friend weighs as much as 3 shared songs.
+// UUID validation is not provided.
+// May return ErrInternal, ErrInvalidInput or ErrNotFound on failure.
+func (sfm *SqlFriendRepository) GetFriendSuggestions(uuid string, songIds []string, cursor string, limit int) (*models.Page[models.FriendSuggestion], error) {
+	var afterScore, afterMutual *int
+	var afterId *string
+	if err := decodeCursor(cursor, &afterScore, &afterMutual, &afterId); err != nil {
+		return nil, err
+	}
+	if songIds == nil {
+		songIds = []string{}
+	}
+
+	var suggestions []models.FriendSuggestion
+	rows, err := sfm.DBProvider.GetDb().Query(`
+		WITH my_friends AS (
+			SELECT user2_id AS id FROM friendships WHERE user1_id = $1
+			UNION ALL
+			SELECT user1_id FROM friendships WHERE user2_id = $1
+		), mutual AS (
+			SELECT c.id, COUNT(*) AS n FROM my_friends mf
+			CROSS JOIN LATERAL (
+				SELECT user2_id AS id FROM friendships WHERE user1_id = mf.id
+				UNION ALL
+				SELECT user1_id FROM friendships WHERE user2_id = mf.id
+			) c
+			GROUP BY c.id
+		), my_songs AS (
+			SELECT song_id FROM (
+				SELECT song_id, MAX(listened_at) AS last FROM (
+					SELECT song_id, listened_at FROM listens
+					WHERE user_id = $1 ORDER BY listened_at DESC LIMIT 1000
+				) recent
+				GROUP BY song_id
+				UNION ALL
+				SELECT unnest($2::uuid[]), NULL
+			) s
+			GROUP BY song_id ORDER BY MAX(last) DESC NULLS LAST LIMIT 200
+		), shared AS (
+			SELECT l.user_id AS id, COUNT(*) AS n
+			FROM my_songs s CROSS JOIN LATERAL (
+				SELECT DISTINCT user_id FROM listens
+				WHERE song_id = s.song_id LIMIT 1000
+			) l
+			GROUP BY l.user_id
+		), scored AS (
+			SELECT COALESCE(m.id, s.id) AS id,
+			COALESCE(m.n, 0) AS mutual, COALESCE(s.n, 0) AS shared
+			FROM mutual m FULL OUTER JOIN shared s ON m.id = s.id
+		)
+		SELECT u.id, u.name, u.handle, sc.mutual, sc.shared
+		FROM scored sc JOIN users u ON u.id = sc.id
+		WHERE u.id <> $1 AND NOT u.suspended AND u.discoverable
+		AND u.friend_requests_from <> 'nobody'
+		AND (u.friend_requests_from <> 'friends-of-friends' OR sc.mutual > 0)
+		AND u.id NOT IN (SELECT id FROM my_friends)
+		AND NOT EXISTS (
+			SELECT 1 FROM friend_requests r
+			WHERE (r.sender_id = $1 AND r.recipient_id = u.id)
+			OR (r.sender_id = u.id AND r.recipient_id = $1))
+		AND NOT EXISTS (
+			SELECT 1 FROM blocks b
+			WHERE (b.blocker_id = $1 AND b.blocked_id = u.id)
+			OR (b.blocker_id = u.id AND b.blocked_id = $1))
+		AND ($3::bigint IS NULL OR (sc.mutual * 3 + sc.shared, sc.mutual, u.id) <
+			($3::bigint, $4::bigint, $5::uuid))
+		ORDER BY sc.mutual * 3 + sc.shared DESC, sc.mutual DESC, u.id DESC
+		LIMIT $6`, uuid, pq.Array(songIds), afterScore, afterMutual, afterId, limit+1)
+	if err != nil {
+		return nil, &customerrors.ErrInternal{Message: err.Error()}
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var handle sql.NullString
+		suggestions = append(suggestions, models.FriendSuggestion{})
+		s := &suggestions[len(suggestions)-1]
+		if err := rows.Scan(&s.Id, &s.Name, &handle,
+			&s.MutualFriends, &s.SharedSongs); err != nil {
+			return nil, &customerrors.ErrInternal{Message: err.Error()}
+		}
+		s.Handle = handle.String
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, &customerrors.ErrInternal{Message: err.Error()}
+	}
+
+	if len(suggestions) == 0 {
+		return nil, &customerrors.ErrNotFound{Message: "suggestions not found"}
+	}
+
+	return newPage(suggestions, limit, func(s models.FriendSuggestion) []any {
+		return []any{s.MutualFriends*3 + s.SharedSongs, s.MutualFriends, s.Id}
+	}), nil
+}
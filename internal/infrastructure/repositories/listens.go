@@ -0,0 +1,76 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"spotigram/internal/customerrors"
+	"spotigram/internal/infrastructure/abstractions"
+	"spotigram/internal/service/models"
+)
+
+type SqlListenRepository struct {
+	DBProvider abstractions.SqlDatabaseProvider
+}
+
+// Adds a listen to the repository.
+// May return ErrInternal or ErrInvalidInput on failure.
+func (slr *SqlListenRepository) AddListen(l models.LastStream) error {
+	db := slr.DBProvider.GetDb()
+
+	stmt, err := db.Prepare("INSERT INTO listens (user_id, song_id, listened_at) VALUES ($1, $2, $3)")
+	if err != nil {
+		panic(fmt.Errorf("error preparing AddListen SQL statement: %v", err))
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(l.UserId, l.SongId, l.Time)
+	if err == sql.ErrConnDone {
+		return &customerrors.ErrInternal{Message: "connection is done"}
+	} else if err != nil {
+		return &customerrors.ErrInvalidInput{Message: err.Error()}
+	}
+
+	return nil
+}
+
+// Returns a page of the listens of a user, newest first, starting after the cursor.
+// Listens are told apart by their time and song.
+// UUID validation is not provided.
+// May return ErrInternal, ErrInvalidInput or ErrNotFound on failure.
+func (slr *SqlListenRepository) GetListens(userId string, cursor string, limit int) (*models.Page[models.LastStream], error) {
+	var afterTime *int64
+	var afterId *string
+	if err := decodeCursor(cursor, &afterTime, &afterId); err != nil {
+		return nil, err
+	}
+
+	var listens []models.LastStream
+	rows, err := slr.DBProvider.GetDb().Query(`
+		SELECT song_id, listened_at FROM listens WHERE user_id = $1
+		AND ($2::bigint IS NULL OR (listened_at, song_id) < ($2::bigint, $3::uuid))
+		ORDER BY listened_at DESC, song_id DESC LIMIT $4`, userId, afterTime, afterId, limit+1)
+	if err != nil {
+		return nil, &customerrors.ErrInternal{Message: err.Error()}
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		listens = append(listens, models.LastStream{UserId: userId})
+		l := &listens[len(listens)-1]
+		if err := rows.Scan(&l.SongId, &l.Time); err != nil {
+			return nil, &customerrors.ErrInternal{Message: err.Error()}
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, &customerrors.ErrInternal{Message: err.Error()}
+	}
+
+	if len(listens) == 0 {
+		return nil, &customerrors.ErrNotFound{Message: "listens not found"}
+	}
+
+	return newPage(listens, limit, func(l models.LastStream) []any {
+		return []any{l.Time, l.SongId}
+	}), nil
+}
@@ -0,0 +1,32 @@
+package repositories
+
+import (
+	"spotigram/internal/customerrors"
+	"spotigram/internal/service/models"
+	"spotigram/internal/utility"
+)
+
+// Decodes a page cursor into the pointed keyset values,
+// an empty cursor leaves them untouched.
+// May return ErrInvalidInput on failure.
+func decodeCursor(cursor string, keys ...any) error {
+	if cursor == "" {
+		return nil
+	}
+	if !utility.DecodeCursor(cursor, keys...) {
+		return customerrors.InvalidField("cursor", "")
+	}
+	return nil
+}
+
+// Builds a page out of items fetched with a limit of limit+1,
+// the extra item only tells whether there is a next page.
+// keys returns the keyset values of the last item of the page.
+func newPage[T any](items []T, limit int, keys func(last T) []any) *models.Page[T] {
+	page := &models.Page[T]{Items: items}
+	if len(items) > limit {
+		page.Items = items[:limit]
+		page.NextCursor = utility.EncodeCursor(keys(page.Items[limit-1])...)
+	}
+	return page
+}
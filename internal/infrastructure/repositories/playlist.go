@@ -12,27 +12,26 @@ type SqlPlaylistRepository struct {
 	DBProvider abstractions.SqlDatabaseProvider
 }
 
-// Returns first 100 playlists with an offset.
+// Returns a page of a user's playlists ordered by id, starting after the cursor.
 // UUID validation is not provided.
-// May return ErrInternal or ErrNotFound on failure.
-func (spp *SqlPlaylistRepository) GetPlaylists(userId string, offset int, playlistNameFilter string) ([]models.Playlist, error) {
-	var playlists []models.Playlist
-
-	if offset < 0 {
-		return nil, &customerrors.ErrInternal{Message: "invalid offset"}
+// May return ErrInternal, ErrInvalidInput or ErrNotFound on failure.
+func (spp *SqlPlaylistRepository) GetPlaylists(userId string, cursor string, limit int, playlistNameFilter string) (*models.Page[models.Playlist], error) {
+	var afterId *string
+	if err := decodeCursor(cursor, &afterId); err != nil {
+		return nil, err
 	}
 
-	db := spp.DBProvider.GetDb()
-	rows, err := db.Query(
-		"SELECT id, name FROM playlists WHERE user_id = $1 AND name LIKE '%' || $3 || '%' OFFSET $2 LIMIT 100",
-		userId, offset, playlistNameFilter)
+	var playlists []models.Playlist
+	rows, err := spp.DBProvider.GetDb().Query(`
+		SELECT id, COALESCE(name, '') FROM playlists
+		WHERE user_id = $1 AND name LIKE '%' || $2 || '%'
+		AND ($3::uuid IS NULL OR id > $3::uuid)
+		ORDER BY id LIMIT $4`,
+		userId, playlistNameFilter, afterId, limit+1)
 	if err != nil {
-		if err != sql.ErrNoRows {
-			return nil, &customerrors.ErrInternal{Message: err.Error()}
-		} else {
-			return nil, &customerrors.ErrNotFound{Message: "playlists not found"}
-		}
+		return nil, &customerrors.ErrInternal{Message: err.Error()}
 	}
+	defer rows.Close()
 
 	for rows.Next() {
 		playlists = append(playlists, models.Playlist{})
@@ -47,13 +46,13 @@ func (spp *SqlPlaylistRepository) GetPlaylists(userId string, offset int, playli
 		return nil, &customerrors.ErrInternal{Message: err.Error()}
 	}
 
-	rows.Close()
-
 	if len(playlists) == 0 {
 		return nil, &customerrors.ErrNotFound{Message: "playlists not found"}
 	}
 
-	return playlists, nil
+	return newPage(playlists, limit, func(p models.Playlist) []any {
+		return []any{p.Id}
+	}), nil
 }
 
 // Returns the user id of playlist creator by its id.
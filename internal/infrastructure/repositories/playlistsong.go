@@ -53,22 +53,39 @@ func (cps *CqlPlaylistSongRepository) DeletePlaylistSong(playlistId, songId stri
 	return nil
 }
 
-// Deletes a song from the repository.
+// Deletes a song from every playlist.
+// song_id is not a partition key, so the playlists
+// containing the song are looked up first.
 // May return ErrInternal or ErrNotFound on failure.
 func (cps *CqlPlaylistSongRepository) DeleteSong(songId string) error {
 	session := cps.DBProvider.GetSession()
 
-	stmt :=
-		session.Query("DELETE FROM playlist_songs WHERE song_id = ?",
-			songId)
+	var playlistIds []string
+	iter := session.Query(
+		"SELECT playlist_id FROM playlist_songs WHERE song_id = ? ALLOW FILTERING",
+		songId).Iter()
 
-	if err := stmt.Exec(); err != nil {
-		if err == gocql.ErrNotFound {
-			return &customerrors.ErrNotFound{Message: err.Error()}
-		}
+	var playlist_id string
+	for iter.Scan(&playlist_id) {
+		playlistIds = append(playlistIds, playlist_id)
+	}
+	if err := iter.Close(); err != nil {
 		return &customerrors.ErrInternal{Message: err.Error()}
 	}
 
+	for _, playlistId := range playlistIds {
+		stmt := session.Query(
+			"DELETE FROM playlist_songs WHERE playlist_id = ? AND song_id = ?",
+			playlistId, songId)
+
+		if err := stmt.Exec(); err != nil {
+			if err == gocql.ErrNotFound {
+				return &customerrors.ErrNotFound{Message: err.Error()}
+			}
+			return &customerrors.ErrInternal{Message: err.Error()}
+		}
+	}
+
 	return nil
 }
 
@@ -0,0 +1,162 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"spotigram/internal/customerrors"
+	"spotigram/internal/infrastructure/abstractions"
+	"spotigram/internal/service/models"
+	"time"
+)
+
+type SqlReportRepository struct {
+	DBProvider abstractions.SqlDatabaseProvider
+}
+
+// Adds a report to the repository.
+// May return ErrInternal or ErrInvalidInput on failure.
+func (srr *SqlReportRepository) AddReport(r models.Report) error {
+	db := srr.DBProvider.GetDb()
+
+	stmt, err := db.Prepare(`
+		INSERT INTO reports (id, reporter_id, target_type, target_id, message_id, reason, status, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`)
+	if err != nil {
+		panic(fmt.Errorf("error preparing AddReport SQL statement: %v", err))
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(r.Id, r.ReporterId, r.TargetType, r.TargetId,
+		r.MessageId, r.Reason, r.Status, r.CreatedAt)
+	if err == sql.ErrConnDone {
+		return &customerrors.ErrInternal{Message: "connection is done"}
+	} else if err != nil {
+		return &customerrors.ErrInvalidInput{Message: err.Error()}
+	}
+
+	return nil
+}
+
+// Returns a report by its id.
+// UUID validation is not provided.
+// May return ErrInternal or ErrNotFound on failure.
+func (srr *SqlReportRepository) GetReport(reportId string) (*models.Report, error) {
+	report := models.Report{}
+	var resolvedBy sql.NullString
+	err := srr.DBProvider.GetDb().QueryRow(`
+		SELECT id, reporter_id, target_type, target_id, message_id, reason,
+		status, created_at, resolved_by, resolution
+		FROM reports WHERE id = $1`, reportId).Scan(
+		&report.Id, &report.ReporterId, &report.TargetType, &report.TargetId,
+		&report.MessageId, &report.Reason, &report.Status, &report.CreatedAt,
+		&resolvedBy, &report.Resolution)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, &customerrors.ErrNotFound{Message: "report not found"}
+		} else {
+			return nil, &customerrors.ErrInternal{Message: err.Error()}
+		}
+	}
+	report.ResolvedBy = resolvedBy.String
+
+	return &report, nil
+}
+
+// Returns a page of reports, oldest first, filtered by status unless empty,
+// starting after the cursor.
+// May return ErrInternal, ErrInvalidInput or ErrNotFound on failure.
+func (srr *SqlReportRepository) GetReports(status string, cursor string, limit int) (*models.Page[models.Report], error) {
+	var afterTime *time.Time
+	var afterId *string
+	if err := decodeCursor(cursor, &afterTime, &afterId); err != nil {
+		return nil, err
+	}
+
+	db := srr.DBProvider.GetDb()
+	rows, err := db.Query(`
+		SELECT id, reporter_id, target_type, target_id, message_id, reason,
+		status, created_at, resolved_by, resolution
+		FROM reports WHERE ($1::text = '' OR status = $1)
+		AND ($2::timestamp IS NULL OR (created_at, id) > ($2::timestamp, $3::uuid))
+		ORDER BY created_at, id LIMIT $4`, status, afterTime, afterId, limit+1)
+	if err != nil {
+		return nil, &customerrors.ErrInternal{Message: err.Error()}
+	}
+	defer rows.Close()
+
+	return scanReportPage(rows, limit)
+}
+
+// Returns a page of the reports made by a user, oldest first,
+// starting after the cursor.
+// UUID validation is not provided.
+// May return ErrInternal, ErrInvalidInput or ErrNotFound on failure.
+func (srr *SqlReportRepository) GetReportsByReporter(uuid string, cursor string, limit int) (*models.Page[models.Report], error) {
+	var afterTime *time.Time
+	var afterId *string
+	if err := decodeCursor(cursor, &afterTime, &afterId); err != nil {
+		return nil, err
+	}
+
+	db := srr.DBProvider.GetDb()
+	rows, err := db.Query(`
+		SELECT id, reporter_id, target_type, target_id, message_id, reason,
+		status, created_at, resolved_by, resolution
+		FROM reports WHERE reporter_id = $1
+		AND ($2::timestamp IS NULL OR (created_at, id) > ($2::timestamp, $3::uuid))
+		ORDER BY created_at, id LIMIT $4`, uuid, afterTime, afterId, limit+1)
+	if err != nil {
+		return nil, &customerrors.ErrInternal{Message: err.Error()}
+	}
+	defer rows.Close()
+
+	return scanReportPage(rows, limit)
+}
+
+// Scans reports fetched with a limit of limit+1 into a page.
+func scanReportPage(rows *sql.Rows, limit int) (*models.Page[models.Report], error) {
+	var reports []models.Report
+	for rows.Next() {
+		var resolvedBy sql.NullString
+		report := models.Report{}
+		if err := rows.Scan(
+			&report.Id, &report.ReporterId, &report.TargetType, &report.TargetId,
+			&report.MessageId, &report.Reason, &report.Status, &report.CreatedAt,
+			&resolvedBy, &report.Resolution); err != nil {
+			return nil, &customerrors.ErrInternal{Message: err.Error()}
+		}
+		report.ResolvedBy = resolvedBy.String
+		reports = append(reports, report)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, &customerrors.ErrInternal{Message: err.Error()}
+	}
+
+	if len(reports) == 0 {
+		return nil, &customerrors.ErrNotFound{Message: "reports not found"}
+	}
+
+	return newPage(reports, limit, func(r models.Report) []any {
+		return []any{r.CreatedAt, r.Id}
+	}), nil
+}
+
+// Sets the status, resolver and resolution of a report.
+// UUID validation is not provided.
+// May return ErrInternal or ErrNotFound on failure.
+func (srr *SqlReportRepository) ResolveReport(reportId string, moderatorId string, status string, resolution string) error {
+	res, err := srr.DBProvider.GetDb().Exec(
+		"UPDATE reports SET status = $1, resolved_by = $2, resolution = $3 WHERE id = $4",
+		status, moderatorId, resolution, reportId)
+	if err != nil {
+		return &customerrors.ErrInternal{Message: err.Error()}
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return &customerrors.ErrInternal{Message: err.Error()}
+	} else if rowsAffected < 1 {
+		return &customerrors.ErrNotFound{Message: "report not found"}
+	}
+	return nil
+}
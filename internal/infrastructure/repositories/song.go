@@ -6,46 +6,91 @@ import (
 	"spotigram/internal/customerrors"
 	"spotigram/internal/infrastructure/abstractions"
 	"spotigram/internal/service/models"
+	"time"
 )
 
 type SqlSongRepository struct {
 	DBProvider abstractions.SqlDatabaseProvider
 }
 
-func (ssr *SqlSongRepository) GetSongs(offset int, songNameFilter string, creatorIdFilter string) ([]models.Song, error) {
-	if offset < 0 {
-		return nil, &customerrors.ErrInternal{Message: "invalid offset"}
+// Columns read by scanSong, in order.
+const songColumns = `id, creator_id, name, length, artist, album,
+	track_number, year, genre, duration, COALESCE(album_id::text, ''),
+	loudness, true_peak, track_gain, normalized, streams, hidden, created_at`
+
+// Scans a row of songColumns into the song.
+func scanSong(row interface{ Scan(...any) error }, s *models.Song) error {
+	return row.Scan(&s.Id, &s.CreatorId, &s.Name, &s.Length,
+		&s.Artist, &s.Album, &s.TrackNumber, &s.Year, &s.Genre, &s.Duration,
+		&s.AlbumId, &s.Loudness, &s.TruePeak, &s.TrackGain, &s.Normalized, &s.Streams, &s.Hidden, &s.CreatedAt)
+}
+
+// Returns a page of the most streamed songs, starting after the cursor.
+// Hidden songs are left out.
+// May return ErrInternal, ErrInvalidInput or ErrNotFound on failure.
+func (ssr *SqlSongRepository) GetSongs(cursor string, limit int, songNameFilter string, creatorIdFilter string) (*models.Page[models.Song], error) {
+	var afterStreams *int
+	var afterId *string
+	if err := decodeCursor(cursor, &afterStreams, &afterId); err != nil {
+		return nil, err
 	}
 
 	var songs []models.Song
-	db := ssr.DBProvider.GetDb()
-	var rows *sql.Rows
-	var err error
-	if creatorIdFilter == "" {
-		rows, err = db.Query(
-			"SELECT id, creator_id, name, length, streams FROM songs WHERE name LIKE '%' || $2 || '%' ORDER BY streams DESC OFFSET $1 LIMIT 100",
-			offset, songNameFilter)
-	} else {
-		rows, err = db.Query(
-			"SELECT id, creator_id, name, length, streams FROM songs WHERE name LIKE '%' || $2 || '%' AND creator_id = $3 ORDER BY streams DESC OFFSET $1 LIMIT 100",
-			offset, songNameFilter, creatorIdFilter)
-	}
+	rows, err := ssr.DBProvider.GetDb().Query(
+		`SELECT `+songColumns+` FROM songs
+		WHERE name LIKE '%' || $1 || '%' AND NOT hidden
+		AND ($2::text = '' OR creator_id::text = $2)
+		AND ($3::int IS NULL OR (COALESCE(streams, 0), id) < ($3::int, $4::uuid))
+		ORDER BY COALESCE(streams, 0) DESC, id DESC LIMIT $5`,
+		songNameFilter, creatorIdFilter, afterStreams, afterId, limit+1)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, &customerrors.ErrNotFound{Message: "songs not found"}
-		} else {
+		return nil, &customerrors.ErrInternal{Message: err.Error()}
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		songs = append(songs, models.Song{})
+		if err := scanSong(rows, &songs[len(songs)-1]); err != nil {
 			return nil, &customerrors.ErrInternal{Message: err.Error()}
 		}
 	}
 
+	if err := rows.Err(); err != nil {
+		return nil, &customerrors.ErrInternal{Message: err.Error()}
+	}
+
+	if len(songs) == 0 {
+		return nil, &customerrors.ErrNotFound{Message: "songs not found"}
+	}
+
+	return newPage(songs, limit, func(s models.Song) []any {
+		return []any{s.Streams, s.Id}
+	}), nil
+}
+
+// Returns a page of the songs of a creator including hidden ones,
+// ordered by id, starting after the cursor.
+// May return ErrInternal, ErrInvalidInput or ErrNotFound on failure.
+func (ssr *SqlSongRepository) GetSongsByCreator(creatorId string, cursor string, limit int) (*models.Page[models.Song], error) {
+	var afterId *string
+	if err := decodeCursor(cursor, &afterId); err != nil {
+		return nil, err
+	}
+
+	var songs []models.Song
+	rows, err := ssr.DBProvider.GetDb().Query(
+		`SELECT `+songColumns+` FROM songs WHERE creator_id = $1
+		AND ($2::uuid IS NULL OR id > $2::uuid)
+		ORDER BY id LIMIT $3`,
+		creatorId, afterId, limit+1)
+	if err != nil {
+		return nil, &customerrors.ErrInternal{Message: err.Error()}
+	}
+	defer rows.Close()
+
 	for rows.Next() {
 		songs = append(songs, models.Song{})
-		if err := rows.Scan(
-			&songs[len(songs)-1].Id,
-			&songs[len(songs)-1].CreatorId,
-			&songs[len(songs)-1].Name,
-			&songs[len(songs)-1].Length,
-			&songs[len(songs)-1].Streams); err != nil {
+		if err := scanSong(rows, &songs[len(songs)-1]); err != nil {
 			return nil, &customerrors.ErrInternal{Message: err.Error()}
 		}
 	}
@@ -54,22 +99,20 @@ func (ssr *SqlSongRepository) GetSongs(offset int, songNameFilter string, creato
 		return nil, &customerrors.ErrInternal{Message: err.Error()}
 	}
 
-	rows.Close()
-
 	if len(songs) == 0 {
 		return nil, &customerrors.ErrNotFound{Message: "songs not found"}
 	}
 
-	return songs, nil
+	return newPage(songs, limit, func(s models.Song) []any {
+		return []any{s.Id}
+	}), nil
 }
 
 func (ssr *SqlSongRepository) GetSongInfo(songId string) (*models.Song, error) {
-	song := models.Song{
-		Id: songId,
-	}
+	song := models.Song{}
 	db := ssr.DBProvider.GetDb()
-	row := db.QueryRow("SELECT name, length, creator_id, streams FROM songs WHERE id = $1", songId)
-	if err := row.Scan(&song.Name, &song.Length, &song.CreatorId, &song.Streams); err != nil {
+	row := db.QueryRow("SELECT "+songColumns+" FROM songs WHERE id = $1", songId)
+	if err := scanSong(row, &song); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, &customerrors.ErrNotFound{Message: "no such song"}
 		} else {
@@ -80,19 +123,85 @@ func (ssr *SqlSongRepository) GetSongInfo(songId string) (*models.Song, error) {
 	return &song, nil
 }
 
-func (ssr *SqlSongRepository) GetSongFile(songId string) ([]byte, error) {
-	var file []byte
+// Returns the picture and the file of a song left in the table
+// by versions before the blob store, nil if it has none.
+// UUID validation is not provided.
+// May return ErrInternal or ErrNotFound on failure.
+func (ssr *SqlSongRepository) GetStoredBlobs(songId string) (picture []byte, file []byte, err error) {
 	db := ssr.DBProvider.GetDb()
-	row := db.QueryRow("SELECT file FROM songs WHERE id = $1", songId)
-	if err := row.Scan(&file); err != nil {
+	row := db.QueryRow("SELECT picture, file FROM songs WHERE id = $1", songId)
+	if err := row.Scan(&picture, &file); err != nil {
 		if err == sql.ErrNoRows {
-			return nil, &customerrors.ErrNotFound{Message: "no such song"}
+			return nil, nil, &customerrors.ErrNotFound{Message: "no such song"}
 		} else {
+			return nil, nil, &customerrors.ErrInternal{Message: err.Error()}
+		}
+	}
+
+	return picture, file, nil
+}
+
+// Returns the ids of at most limit songs with a picture or a file
+// left in the table, ordered by id and starting after afterId.
+// May return ErrInternal on failure.
+func (ssr *SqlSongRepository) GetSongIdsWithStoredBlobs(afterId string, limit int) ([]string, error) {
+	ids := []string{}
+	rows, err := ssr.DBProvider.GetDb().Query(`
+		SELECT id FROM songs
+		WHERE (picture IS NOT NULL OR file IS NOT NULL)
+		AND ($1::text = '' OR id > $1::uuid)
+		ORDER BY id LIMIT $2`, afterId, limit)
+	if isUndefinedColumn(err) {
+		return ids, nil
+	} else if err != nil {
+		return nil, &customerrors.ErrInternal{Message: err.Error()}
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
 			return nil, &customerrors.ErrInternal{Message: err.Error()}
 		}
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, &customerrors.ErrInternal{Message: err.Error()}
+	}
+
+	return ids, nil
+}
+
+// Clears the picture and the file of a song left in the table
+// once they are in the blob store.
+// UUID validation is not provided.
+// May return ErrInternal or ErrNotFound on failure.
+func (ssr *SqlSongRepository) ClearStoredBlobs(songId string) error {
+	res, err := ssr.DBProvider.GetDb().Exec(
+		"UPDATE songs SET picture = NULL, file = NULL WHERE id = $1", songId)
+	if err != nil {
+		return &customerrors.ErrInternal{Message: err.Error()}
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return &customerrors.ErrInternal{Message: err.Error()}
+	} else if rowsAffected < 1 {
+		return &customerrors.ErrNotFound{Message: "no such song"}
 	}
+	return nil
+}
 
-	return file, nil
+// Drops the picture and file columns left by versions before
+// the blob store, once everything in them is in the blob store.
+// May return ErrInternal on failure.
+func (ssr *SqlSongRepository) DropStoredBlobColumns() error {
+	_, err := ssr.DBProvider.GetDb().Exec(`ALTER TABLE songs
+		DROP COLUMN IF EXISTS picture, DROP COLUMN IF EXISTS file`)
+	if err != nil {
+		return &customerrors.ErrInternal{Message: err.Error()}
+	}
+	return nil
 }
 
 func (ssr *SqlSongRepository) DeleteSong(songId string) error {
@@ -121,16 +230,23 @@ func (ssr *SqlSongRepository) DeleteSong(songId string) error {
 	return nil
 }
 
-func (ssr *SqlSongRepository) AddSong(song models.Song, picture []byte, file []byte) error {
+func (ssr *SqlSongRepository) AddSong(song models.Song, waveform []byte) error {
 	db := ssr.DBProvider.GetDb()
 
-	stmt, err := db.Prepare("INSERT INTO songs (id, creator_id, name, length, picture, file, streams) VALUES ($1, $2, $3, $4, $5, $6, 0)")
+	stmt, err := db.Prepare(`INSERT INTO songs (id, creator_id, name, length,
+		artist, album, track_number, year, genre, duration, album_id,
+		loudness, true_peak, track_gain, normalized, waveform, streams, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NULLIF($11, '')::uuid,
+		$12, $13, $14, $15, $16, 0, $17)`)
 	if err != nil {
 		panic(fmt.Errorf("error preparing AddSong SQL statement: %v", err))
 	}
 	defer stmt.Close()
 
-	_, err = stmt.Exec(song.Id, song.CreatorId, song.Name, song.Length, picture, file)
+	_, err = stmt.Exec(song.Id, song.CreatorId, song.Name, song.Length,
+		song.Artist, song.Album, song.TrackNumber, song.Year, song.Genre, song.Duration,
+		song.AlbumId, song.Loudness, song.TruePeak, song.TrackGain, song.Normalized,
+		waveform, song.CreatedAt)
 	if err == sql.ErrConnDone {
 		return &customerrors.ErrInternal{Message: "connection is done"}
 	} else if err != nil {
@@ -161,6 +277,21 @@ func (spp *SqlSongRepository) UpdateSongName(songId string, name string) error {
 	return nil
 }
 
+func (ssr *SqlSongRepository) UpdateHidden(songId string, hidden bool) error {
+	res, err := ssr.DBProvider.GetDb().Exec(
+		"UPDATE songs SET hidden = $1 WHERE id = $2", hidden, songId)
+	if err != nil {
+		return &customerrors.ErrInternal{Message: err.Error()}
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return &customerrors.ErrInternal{Message: err.Error()}
+	} else if rowsAffected < 1 {
+		return &customerrors.ErrNotFound{Message: "no such song"}
+	}
+	return nil
+}
+
 func (ssr *SqlSongRepository) IncrementStreams(songId string) error {
 	db := ssr.DBProvider.GetDb()
 
@@ -180,14 +311,13 @@ func (ssr *SqlSongRepository) IncrementStreams(songId string) error {
 	return nil
 }
 
-// Returns a song's picture by its uuid.
+// Returns the waveform peaks of a song, nil if it has none.
 // UUID validation is not provided.
 // May return ErrInternal or ErrNotFound on failure.
-func (sdm *SqlSongRepository) GetPicture(songId string) ([]byte, error) {
-	var pic []byte
-	err := sdm.DBProvider.GetDb().QueryRow(
-		"SELECT picture FROM songs WHERE id = $1", songId).Scan(
-		&pic)
+func (ssr *SqlSongRepository) GetWaveform(songId string) ([]byte, error) {
+	var waveform []byte
+	err := ssr.DBProvider.GetDb().QueryRow(
+		"SELECT waveform FROM songs WHERE id = $1", songId).Scan(&waveform)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, &customerrors.ErrNotFound{Message: "song not found"}
@@ -195,5 +325,96 @@ func (sdm *SqlSongRepository) GetPicture(songId string) ([]byte, error) {
 			return nil, &customerrors.ErrInternal{Message: err.Error()}
 		}
 	}
-	return pic, nil
+	return waveform, nil
+}
+
+// Sets the waveform peaks of a song.
+// UUID validation is not provided.
+// May return ErrInternal or ErrNotFound on failure.
+func (ssr *SqlSongRepository) UpdateWaveform(songId string, waveform []byte) error {
+	res, err := ssr.DBProvider.GetDb().Exec(
+		"UPDATE songs SET waveform = $1 WHERE id = $2", waveform, songId)
+	if err != nil {
+		return &customerrors.ErrInternal{Message: err.Error()}
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return &customerrors.ErrInternal{Message: err.Error()}
+	} else if rowsAffected < 1 {
+		return &customerrors.ErrNotFound{Message: "no such song"}
+	}
+	return nil
+}
+
+// Returns the ids of at most limit songs without waveform peaks,
+// ordered by id and starting after afterId.
+// May return ErrInternal on failure.
+func (ssr *SqlSongRepository) GetSongIdsWithoutWaveform(afterId string, limit int) ([]string, error) {
+	ids := []string{}
+	rows, err := ssr.DBProvider.GetDb().Query(`
+		SELECT id FROM songs
+		WHERE waveform IS NULL AND ($1::text = '' OR id > $1::uuid)
+		ORDER BY id LIMIT $2`, afterId, limit)
+	if err != nil {
+		return nil, &customerrors.ErrInternal{Message: err.Error()}
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, &customerrors.ErrInternal{Message: err.Error()}
+		}
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, &customerrors.ErrInternal{Message: err.Error()}
+	}
+
+	return ids, nil
+}
+
+// Returns a page of the visible songs of the creators the user follows,
+// newest first, starting after the cursor. Songs uploaded before
+// upload times were recorded are left out.
+// UUID validation is not provided.
+// May return ErrInternal, ErrInvalidInput or ErrNotFound on failure.
+func (ssr *SqlSongRepository) GetFeed(userId string, cursor string, limit int) (*models.Page[models.Song], error) {
+	var afterTime *time.Time
+	var afterId *string
+	if err := decodeCursor(cursor, &afterTime, &afterId); err != nil {
+		return nil, err
+	}
+
+	var songs []models.Song
+	rows, err := ssr.DBProvider.GetDb().Query(`
+		SELECT `+songColumns+` FROM songs
+		WHERE NOT hidden AND created_at IS NOT NULL AND creator_id IN (
+			SELECT followee_id FROM follows WHERE follower_id = $1)
+		AND ($2::timestamp IS NULL OR (created_at, id) < ($2::timestamp, $3::uuid))
+		ORDER BY created_at DESC, id DESC LIMIT $4`, userId, afterTime, afterId, limit+1)
+	if err != nil {
+		return nil, &customerrors.ErrInternal{Message: err.Error()}
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		songs = append(songs, models.Song{})
+		if err := scanSong(rows, &songs[len(songs)-1]); err != nil {
+			return nil, &customerrors.ErrInternal{Message: err.Error()}
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, &customerrors.ErrInternal{Message: err.Error()}
+	}
+
+	if len(songs) == 0 {
+		return nil, &customerrors.ErrNotFound{Message: "songs not found"}
+	}
+
+	return newPage(songs, limit, func(s models.Song) []any {
+		return []any{*s.CreatedAt, s.Id}
+	}), nil
 }
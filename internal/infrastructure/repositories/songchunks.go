@@ -11,32 +11,43 @@ type CqlSongChunkRepository struct {
 	DBProvider abstractions.CqlDatabaseProvider
 }
 
-// Deletes song chunks from the repository.
+// Deletes song chunks of every rendition from the repository.
 // May return ErrInternal or ErrNotFound on failure.
 func (cps *CqlSongChunkRepository) DeleteSongChunks(songId string) error {
 	session := cps.DBProvider.GetSession()
 
-	stmt :=
-		session.Query("DELETE FROM song_chunks WHERE song_id = ?",
-			songId)
+	for _, table := range []string{"song_chunks", "song_rendition_chunks"} {
+		stmt :=
+			session.Query("DELETE FROM "+table+" WHERE song_id = ?",
+				songId)
 
-	if err := stmt.Exec(); err != nil {
-		if err == gocql.ErrNotFound {
-			return &customerrors.ErrNotFound{Message: err.Error()}
+		if err := stmt.Exec(); err != nil {
+			if err == gocql.ErrNotFound {
+				return &customerrors.ErrNotFound{Message: err.Error()}
+			}
+			return &customerrors.ErrInternal{Message: err.Error()}
 		}
-		return &customerrors.ErrInternal{Message: err.Error()}
 	}
 
 	return nil
 }
 
-// Deletes song chunks from the repository.
+// Returns a song chunk of a rendition, an empty rendition
+// is read from the single rendition table.
 // May return ErrInternal or ErrNotFound on failure.
-func (cps *CqlSongChunkRepository) GetSongChunk(songId string, index int) ([]byte, error) {
+func (cps *CqlSongChunkRepository) GetSongChunk(songId string, rendition string, index int) ([]byte, error) {
 	session := cps.DBProvider.GetSession()
 	var file []byte
-	err := session.Query("SELECT file FROM song_chunks WHERE song_id = ? AND ind = ?",
-		songId, index).Scan(&file)
+	var query *gocql.Query
+	if rendition == "" {
+		query = session.Query("SELECT file FROM song_chunks WHERE song_id = ? AND ind = ?",
+			songId, index)
+	} else {
+		query = session.Query(`SELECT file FROM song_rendition_chunks
+			WHERE song_id = ? AND rendition = ? AND ind = ?`,
+			songId, rendition, index)
+	}
+	err := query.Scan(&file)
 	if err != nil {
 		if err == gocql.ErrNotFound {
 			return nil, &customerrors.ErrNotFound{Message: err.Error()}
@@ -47,17 +58,17 @@ func (cps *CqlSongChunkRepository) GetSongChunk(songId string, index int) ([]byt
 	return file, nil
 }
 
-// Deletes a song from the repository.
-// May return ErrInternal or ErrNotFound on failure.
-func (cps *CqlSongChunkRepository) AddSongChunk(songId string, ind int, chunk []byte) error {
+// Adds a song chunk of a rendition to the repository.
+// May return ErrInternal on failure.
+func (cps *CqlSongChunkRepository) AddSongChunk(songId string, rendition string, ind int, chunk []byte) error {
 	session := cps.DBProvider.GetSession()
 
 	insertStmt := session.Query(`
-		INSERT INTO song_chunks (song_id, ind, file)
-		VALUES (?, ?, ?)
+		INSERT INTO song_rendition_chunks (song_id, rendition, ind, file)
+		VALUES (?, ?, ?, ?)
 	`)
 	err := insertStmt.Bind(
-		songId, ind, chunk,
+		songId, rendition, ind, chunk,
 	).Exec()
 
 	if err != nil {
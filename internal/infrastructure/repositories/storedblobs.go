@@ -0,0 +1,11 @@
+package repositories
+
+import "github.com/lib/pq"
+
+// Returns whether the error is postgres' undefined column, which the
+// queries of the blob columns left by versions before the blob store
+// return once those columns are dropped.
+func isUndefinedColumn(err error) bool {
+	pqErr, ok := err.(*pq.Error)
+	return ok && pqErr.Code == "42703"
+}
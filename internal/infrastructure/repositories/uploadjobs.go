@@ -0,0 +1,111 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"spotigram/internal/customerrors"
+	"spotigram/internal/infrastructure/abstractions"
+	"spotigram/internal/service/models"
+	"time"
+)
+
+type SqlUploadJobRepository struct {
+	DBProvider abstractions.SqlDatabaseProvider
+}
+
+// Adds an upload job to the repository.
+// May return ErrInternal or ErrInvalidInput on failure.
+func (sujr *SqlUploadJobRepository) AddJob(job models.UploadJob) error {
+	db := sujr.DBProvider.GetDb()
+
+	stmt, err := db.Prepare(`INSERT INTO upload_jobs
+		(id, user_id, song_id, song_name, status, error, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`)
+	if err != nil {
+		panic(fmt.Errorf("error preparing AddJob SQL statement: %v", err))
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(job.Id, job.UserId, job.SongId, job.SongName,
+		job.Status, job.Error, job.CreatedAt, job.UpdatedAt)
+	if err == sql.ErrConnDone {
+		return &customerrors.ErrInternal{Message: "connection is done"}
+	} else if err != nil {
+		return &customerrors.ErrInvalidInput{Message: err.Error()}
+	}
+
+	return nil
+}
+
+// Returns an upload job by its id.
+// UUID validation is not provided.
+// May return ErrInternal or ErrNotFound on failure.
+func (sujr *SqlUploadJobRepository) GetJob(id string) (*models.UploadJob, error) {
+	job := models.UploadJob{}
+	err := sujr.DBProvider.GetDb().QueryRow(`
+		SELECT id, user_id, song_id, song_name, status, error, created_at, updated_at
+		FROM upload_jobs WHERE id = $1`, id).Scan(
+		&job.Id, &job.UserId, &job.SongId, &job.SongName,
+		&job.Status, &job.Error, &job.CreatedAt, &job.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, &customerrors.ErrNotFound{Message: "upload job not found"}
+		} else {
+			return nil, &customerrors.ErrInternal{Message: err.Error()}
+		}
+	}
+	return &job, nil
+}
+
+// Sets the status of an upload job, errorMessage is empty unless it failed.
+// UUID validation is not provided.
+// May return ErrInternal or ErrNotFound on failure.
+func (sujr *SqlUploadJobRepository) UpdateJobStatus(id string, status string, errorMessage string) error {
+	res, err := sujr.DBProvider.GetDb().Exec(
+		"UPDATE upload_jobs SET status = $2, error = $3, updated_at = $4 WHERE id = $1",
+		id, status, errorMessage, time.Now().UTC())
+	if err != nil {
+		return &customerrors.ErrInternal{Message: err.Error()}
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return &customerrors.ErrInternal{Message: err.Error()}
+	} else if rowsAffected < 1 {
+		return &customerrors.ErrNotFound{Message: "upload job not found"}
+	}
+
+	return nil
+}
+
+// Marks every queued or processing job as failed.
+// Returns the failed jobs.
+// May return ErrInternal on failure.
+func (sujr *SqlUploadJobRepository) FailUnfinishedJobs(errorMessage string) ([]models.UploadJob, error) {
+	rows, err := sujr.DBProvider.GetDb().Query(`
+		UPDATE upload_jobs SET status = $1, error = $2, updated_at = $3
+		WHERE status IN ($4, $5)
+		RETURNING id, user_id, song_id, song_name, status, error, created_at, updated_at`,
+		models.UploadJobFailed, errorMessage, time.Now().UTC(),
+		models.UploadJobQueued, models.UploadJobProcessing)
+	if err != nil {
+		return nil, &customerrors.ErrInternal{Message: err.Error()}
+	}
+	defer rows.Close()
+
+	var jobs []models.UploadJob
+	for rows.Next() {
+		jobs = append(jobs, models.UploadJob{})
+		j := &jobs[len(jobs)-1]
+		if err := rows.Scan(&j.Id, &j.UserId, &j.SongId, &j.SongName,
+			&j.Status, &j.Error, &j.CreatedAt, &j.UpdatedAt); err != nil {
+			return nil, &customerrors.ErrInternal{Message: err.Error()}
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, &customerrors.ErrInternal{Message: err.Error()}
+	}
+
+	return jobs, nil
+}
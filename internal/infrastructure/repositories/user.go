@@ -6,24 +6,42 @@ import (
 	"spotigram/internal/customerrors"
 	"spotigram/internal/infrastructure/abstractions"
 	"spotigram/internal/service/models"
+	"time"
+
+	"github.com/lib/pq"
 )
 
 type SqlUserRepository struct {
 	DBProvider abstractions.SqlDatabaseProvider
 }
 
+// Columns read by scanUser, in order.
+const userColumns = "id, name, email, password, verified, role, suspended, handle, bio, location, links"
+
+// Scans a row of userColumns into the user.
+func scanUser(row interface{ Scan(...any) error }, u *models.User) error {
+	var handle sql.NullString
+	err := row.Scan(&u.Id, &u.Name, &u.Email, &u.Password, &u.Verified,
+		&u.Role, &u.Suspended, &handle, &u.Bio, &u.Location, pq.Array(&u.Links))
+	u.Handle = handle.String
+	if u.Links == nil {
+		u.Links = []string{}
+	}
+	return err
+}
+
 // Adds user to the repository.
 // May return ErrInternal or ErrInvalidInput on failure.
 func (sdm *SqlUserRepository) AddUser(sud models.User) error {
 	db := sdm.DBProvider.GetDb()
 
-	stmt, err := db.Prepare("INSERT INTO users (id, name, email, password, picture, verified, public_key) VALUES ($1, $2, $3, $4, $5, $6, $7)")
+	stmt, err := db.Prepare("INSERT INTO users (id, name, email, password, verified, public_key, role) VALUES ($1, $2, $3, $4, $5, $6, $7)")
 	if err != nil {
 		panic(fmt.Errorf("error preparing AddUser SQL statement: %v", err))
 	}
 	defer stmt.Close()
 
-	_, err = stmt.Exec(sud.Id, sud.Name, sud.Email, sud.Password, nil, sud.Verified, nil)
+	_, err = stmt.Exec(sud.Id, sud.Name, sud.Email, sud.Password, sud.Verified, nil, sud.Role)
 	if err == sql.ErrConnDone {
 		return &customerrors.ErrInternal{Message: "connection is done"}
 	} else if err != nil {
@@ -71,7 +89,7 @@ func (sdm *SqlUserRepository) GetPassword(uuid string) (string, error) {
 // UUID validation is not provided.
 // May return ErrInternal or ErrNotFound on failure.
 func (sdm *SqlUserRepository) GetPublicKey(uuid string) (string, error) {
-	var p string
+	var p sql.NullString
 	err := sdm.DBProvider.GetDb().QueryRow(
 		"SELECT public_key FROM users WHERE id = $1", uuid).Scan(&p)
 	if err != nil {
@@ -81,7 +99,7 @@ func (sdm *SqlUserRepository) GetPublicKey(uuid string) (string, error) {
 			return "", &customerrors.ErrInternal{Message: err.Error()}
 		}
 	}
-	return p, nil
+	return p.String, nil
 }
 
 // Returns a user by its uuid.
@@ -89,9 +107,8 @@ func (sdm *SqlUserRepository) GetPublicKey(uuid string) (string, error) {
 // May return ErrInternal or ErrNotFound on failure.
 func (sdm *SqlUserRepository) GetUser(uuid string) (*models.User, error) {
 	var user = models.User{}
-	err := sdm.DBProvider.GetDb().QueryRow(
-		"SELECT id, name, email, password, verified FROM users WHERE id = $1", uuid).Scan(
-		&user.Id, &user.Name, &user.Email, &user.Password, &user.Verified)
+	err := scanUser(sdm.DBProvider.GetDb().QueryRow(
+		"SELECT "+userColumns+" FROM users WHERE id = $1", uuid), &user)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, &customerrors.ErrNotFound{Message: "user not found"}
@@ -103,35 +120,40 @@ func (sdm *SqlUserRepository) GetUser(uuid string) (*models.User, error) {
 	return &user, nil
 }
 
-// Returns a users list, given offset and filter.
+// Returns a page of users ordered by id, given the filter, starting after the cursor.
+// Users blocked by or blocking the requester are left out if the requester is set,
+// users who are not discoverable are left out unless they are the requester's friends.
 // UUID validation is not provided.
-// May return ErrInternal or ErrNotFound on failure.
-func (sdm *SqlUserRepository) GetUsers(offset int, usernameFilter string) ([]models.User, error) {
-	if offset < 0 {
-		return nil, &customerrors.ErrInternal{Message: "invalid offset"}
+// May return ErrInternal, ErrInvalidInput or ErrNotFound on failure.
+func (sdm *SqlUserRepository) GetUsers(cursor string, limit int, usernameFilter string, requesterUUID string) (*models.Page[models.User], error) {
+	var afterId *string
+	if err := decodeCursor(cursor, &afterId); err != nil {
+		return nil, err
 	}
 
 	var users []models.User
-	db := sdm.DBProvider.GetDb()
-	rows, err := db.Query(
-		"SELECT id, name, email, password, verified FROM users WHERE name LIKE '%' || $2 || '%' OFFSET $1 LIMIT 100",
-		offset, usernameFilter)
+	rows, err := sdm.DBProvider.GetDb().Query(
+		`SELECT `+userColumns+` FROM users u
+		WHERE (name LIKE '%' || $1 || '%' OR handle LIKE '%' || lower($1) || '%')
+		AND (u.discoverable OR u.id::text = $2 OR EXISTS (
+			SELECT 1 FROM friendships f
+			WHERE (f.user1_id = u.id AND f.user2_id::text = $2)
+			OR (f.user2_id = u.id AND f.user1_id::text = $2)))
+		AND ($2::text = '' OR NOT EXISTS (
+			SELECT 1 FROM blocks b
+			WHERE (b.blocker_id::text = $2 AND b.blocked_id = u.id)
+			OR (b.blocked_id::text = $2 AND b.blocker_id = u.id)))
+		AND ($3::uuid IS NULL OR u.id > $3::uuid)
+		ORDER BY u.id LIMIT $4`,
+		usernameFilter, requesterUUID, afterId, limit+1)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, &customerrors.ErrNotFound{Message: "users not found"}
-		} else {
-			return nil, &customerrors.ErrInternal{Message: err.Error()}
-		}
+		return nil, &customerrors.ErrInternal{Message: err.Error()}
 	}
+	defer rows.Close()
 
 	for rows.Next() {
 		users = append(users, models.User{})
-		if err := rows.Scan(
-			&users[len(users)-1].Id,
-			&users[len(users)-1].Name,
-			&users[len(users)-1].Email,
-			&users[len(users)-1].Password,
-			&users[len(users)-1].Verified); err != nil {
+		if err := scanUser(rows, &users[len(users)-1]); err != nil {
 			return nil, &customerrors.ErrInternal{Message: err.Error()}
 		}
 	}
@@ -140,19 +162,20 @@ func (sdm *SqlUserRepository) GetUsers(offset int, usernameFilter string) ([]mod
 		return nil, &customerrors.ErrInternal{Message: err.Error()}
 	}
 
-	rows.Close()
-
 	if len(users) == 0 {
 		return nil, &customerrors.ErrNotFound{Message: "users not found"}
 	}
 
-	return users, nil
+	return newPage(users, limit, func(u models.User) []any {
+		return []any{u.Id}
+	}), nil
 }
 
-// Returns a user's picture by its uuid.
+// Returns a user's picture left in the table by versions
+// before the blob store, nil if it has none.
 // UUID validation is not provided.
 // May return ErrInternal or ErrNotFound on failure.
-func (sdm *SqlUserRepository) GetPicture(uuid string) ([]byte, error) {
+func (sdm *SqlUserRepository) GetStoredPicture(uuid string) ([]byte, error) {
 	var pic []byte
 	err := sdm.DBProvider.GetDb().QueryRow(
 		"SELECT picture FROM users WHERE id = $1", uuid).Scan(
@@ -167,6 +190,37 @@ func (sdm *SqlUserRepository) GetPicture(uuid string) ([]byte, error) {
 	return pic, nil
 }
 
+// Returns the ids of at most limit users with a picture left in the table,
+// ordered by id and starting after afterId.
+// May return ErrInternal on failure.
+func (sdm *SqlUserRepository) GetUserIdsWithStoredPicture(afterId string, limit int) ([]string, error) {
+	ids := []string{}
+	rows, err := sdm.DBProvider.GetDb().Query(`
+		SELECT id FROM users
+		WHERE picture IS NOT NULL AND ($1::text = '' OR id > $1::uuid)
+		ORDER BY id LIMIT $2`, afterId, limit)
+	if isUndefinedColumn(err) {
+		return ids, nil
+	} else if err != nil {
+		return nil, &customerrors.ErrInternal{Message: err.Error()}
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, &customerrors.ErrInternal{Message: err.Error()}
+		}
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, &customerrors.ErrInternal{Message: err.Error()}
+	}
+
+	return ids, nil
+}
+
 // Updates a user's name by its uuid.
 // UUID validation is not provided.
 // May return ErrInternal or ErrNotFound on failure.
@@ -221,12 +275,12 @@ func (sdm *SqlUserRepository) UpdatePublicKey(uuid string, public_key string) er
 	return nil
 }
 
-// Updates a user's picture by its uuid.
-// UUID validation is not provided, image validation is not provided.
+// Clears a user's picture left in the table once it is in the blob store.
+// UUID validation is not provided.
 // May return ErrInternal or ErrNotFound on failure.
-func (sdm *SqlUserRepository) UpdatePicture(uuid string, image []byte) error {
+func (sdm *SqlUserRepository) ClearStoredPicture(uuid string) error {
 	res, err := sdm.DBProvider.GetDb().Exec(
-		"UPDATE users SET picture = $1 WHERE id = $2", image, uuid)
+		"UPDATE users SET picture = NULL WHERE id = $1", uuid)
 	if err != nil {
 		return &customerrors.ErrInternal{Message: err.Error()}
 	}
@@ -239,6 +293,107 @@ func (sdm *SqlUserRepository) UpdatePicture(uuid string, image []byte) error {
 	return nil
 }
 
+// Updates a user's suspension status by its uuid.
+// UUID validation is not provided.
+// May return ErrInternal or ErrNotFound on failure.
+func (sdm *SqlUserRepository) UpdateSuspended(uuid string, suspended bool) error {
+	res, err := sdm.DBProvider.GetDb().Exec(
+		"UPDATE users SET suspended = $1 WHERE id = $2", suspended, uuid)
+	if err != nil {
+		return &customerrors.ErrInternal{Message: err.Error()}
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return &customerrors.ErrInternal{Message: err.Error()}
+	} else if rowsAffected < 1 {
+		return &customerrors.ErrNotFound{Message: "user not found"}
+	}
+	return nil
+}
+
+// Updates a user's role by its uuid.
+// UUID and role validation is not provided.
+// May return ErrInternal or ErrNotFound on failure.
+func (sdm *SqlUserRepository) UpdateRole(uuid string, role string) error {
+	res, err := sdm.DBProvider.GetDb().Exec(
+		"UPDATE users SET role = $1 WHERE id = $2", role, uuid)
+	if err != nil {
+		return &customerrors.ErrInternal{Message: err.Error()}
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return &customerrors.ErrInternal{Message: err.Error()}
+	} else if rowsAffected < 1 {
+		return &customerrors.ErrNotFound{Message: "user not found"}
+	}
+	return nil
+}
+
+// Updates a user's verification status by its uuid.
+// UUID validation is not provided.
+// May return ErrInternal or ErrNotFound on failure.
+func (sdm *SqlUserRepository) UpdateVerified(uuid string, verified bool) error {
+	res, err := sdm.DBProvider.GetDb().Exec(
+		"UPDATE users SET verified = $1 WHERE id = $2", verified, uuid)
+	if err != nil {
+		return &customerrors.ErrInternal{Message: err.Error()}
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return &customerrors.ErrInternal{Message: err.Error()}
+	} else if rowsAffected < 1 {
+		return &customerrors.ErrNotFound{Message: "user not found"}
+	}
+	return nil
+}
+
+// Returns a page of users matching the filter ordered by id,
+// starting after the cursor, the query matches names and emails.
+// May return ErrInternal, ErrInvalidInput or ErrNotFound on failure.
+func (sdm *SqlUserRepository) SearchUsers(filter models.UserFilter, cursor string, limit int) (*models.Page[models.User], error) {
+	var afterId *string
+	if err := decodeCursor(cursor, &afterId); err != nil {
+		return nil, err
+	}
+
+	var users []models.User
+	db := sdm.DBProvider.GetDb()
+	rows, err := db.Query(
+		`SELECT `+userColumns+` FROM users
+		WHERE ($1::text = '' OR name ILIKE '%' || $1 || '%'
+		OR email ILIKE '%' || $1 || '%' OR handle ILIKE '%' || $1 || '%')
+		AND ($2::text = '' OR role = $2)
+		AND ($3::boolean IS NULL OR suspended = $3)
+		AND ($4::boolean IS NULL OR verified = $4)
+		AND ($5::uuid IS NULL OR id > $5::uuid)
+		ORDER BY id LIMIT $6`,
+		filter.Query, filter.Role, filter.Suspended, filter.Verified,
+		afterId, limit+1)
+	if err != nil {
+		return nil, &customerrors.ErrInternal{Message: err.Error()}
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		users = append(users, models.User{})
+		if err := scanUser(rows, &users[len(users)-1]); err != nil {
+			return nil, &customerrors.ErrInternal{Message: err.Error()}
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, &customerrors.ErrInternal{Message: err.Error()}
+	}
+
+	if len(users) == 0 {
+		return nil, &customerrors.ErrNotFound{Message: "users not found"}
+	}
+
+	return newPage(users, limit, func(u models.User) []any {
+		return []any{u.Id}
+	}), nil
+}
+
 // Returns bool on whether the user uuid is present.
 // UUID validation is not provided.
 // May return ErrInternal on failure.
@@ -255,3 +410,202 @@ func (sdm *SqlUserRepository) DoesUserExist(uuid string) (bool, error) {
 	}
 	return true, nil
 }
+
+// Returns the uuid of a user by its handle.
+// Handle validation is not provided.
+// May return ErrInternal or ErrNotFound on failure.
+func (sdm *SqlUserRepository) GetUUIDByHandle(handle string) (string, error) {
+	var uuid string
+	err := sdm.DBProvider.GetDb().QueryRow(
+		"SELECT id FROM users WHERE handle = $1", handle).Scan(&uuid)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", &customerrors.ErrNotFound{Message: "user not found"}
+		} else {
+			return "", &customerrors.ErrInternal{Message: err.Error()}
+		}
+	}
+	return uuid, nil
+}
+
+// Returns when a user's handle was last changed,
+// zero time if it never was.
+// UUID validation is not provided.
+// May return ErrInternal or ErrNotFound on failure.
+func (sdm *SqlUserRepository) GetHandleChangedAt(uuid string) (time.Time, error) {
+	var changedAt sql.NullTime
+	err := sdm.DBProvider.GetDb().QueryRow(
+		"SELECT handle_changed_at FROM users WHERE id = $1", uuid).Scan(&changedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return time.Time{}, &customerrors.ErrNotFound{Message: "user not found"}
+		} else {
+			return time.Time{}, &customerrors.ErrInternal{Message: err.Error()}
+		}
+	}
+	return changedAt.Time, nil
+}
+
+// Updates a user's handle and its change time by its uuid.
+// UUID validation is not provided.
+// May return ErrInternal, ErrInvalidInput or ErrNotFound on failure.
+func (sdm *SqlUserRepository) UpdateHandle(uuid string, handle string, changedAt time.Time) error {
+	res, err := sdm.DBProvider.GetDb().Exec(
+		"UPDATE users SET handle = $1, handle_changed_at = $2 WHERE id = $3",
+		handle, changedAt, uuid)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return &customerrors.ErrInvalidInput{Message: "handle is taken"}
+		}
+		return &customerrors.ErrInternal{Message: err.Error()}
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return &customerrors.ErrInternal{Message: err.Error()}
+	} else if rowsAffected < 1 {
+		return &customerrors.ErrNotFound{Message: "user not found"}
+	}
+	return nil
+}
+
+// Updates a user's bio, location and links by its uuid.
+// UUID validation is not provided.
+// May return ErrInternal or ErrNotFound on failure.
+func (sdm *SqlUserRepository) UpdateProfile(uuid string, bio string, location string, links []string) error {
+	if links == nil {
+		links = []string{}
+	}
+	res, err := sdm.DBProvider.GetDb().Exec(
+		"UPDATE users SET bio = $1, location = $2, links = $3 WHERE id = $4",
+		bio, location, pq.Array(links), uuid)
+	if err != nil {
+		return &customerrors.ErrInternal{Message: err.Error()}
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return &customerrors.ErrInternal{Message: err.Error()}
+	} else if rowsAffected < 1 {
+		return &customerrors.ErrNotFound{Message: "user not found"}
+	}
+	return nil
+}
+
+// Returns a user's banner left in the table by versions
+// before the blob store, nil if it has none.
+// UUID validation is not provided.
+// May return ErrInternal or ErrNotFound on failure.
+func (sdm *SqlUserRepository) GetStoredBanner(uuid string) ([]byte, error) {
+	var banner []byte
+	err := sdm.DBProvider.GetDb().QueryRow(
+		"SELECT banner FROM users WHERE id = $1", uuid).Scan(&banner)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, &customerrors.ErrNotFound{Message: "user not found"}
+		} else {
+			return nil, &customerrors.ErrInternal{Message: err.Error()}
+		}
+	}
+	return banner, nil
+}
+
+// Returns the ids of at most limit users with a banner left in the table,
+// ordered by id and starting after afterId.
+// May return ErrInternal on failure.
+func (sdm *SqlUserRepository) GetUserIdsWithStoredBanner(afterId string, limit int) ([]string, error) {
+	ids := []string{}
+	rows, err := sdm.DBProvider.GetDb().Query(`
+		SELECT id FROM users
+		WHERE banner IS NOT NULL AND ($1::text = '' OR id > $1::uuid)
+		ORDER BY id LIMIT $2`, afterId, limit)
+	if isUndefinedColumn(err) {
+		return ids, nil
+	} else if err != nil {
+		return nil, &customerrors.ErrInternal{Message: err.Error()}
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, &customerrors.ErrInternal{Message: err.Error()}
+		}
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, &customerrors.ErrInternal{Message: err.Error()}
+	}
+
+	return ids, nil
+}
+
+// Clears a user's banner left in the table once it is in the blob store.
+// UUID validation is not provided.
+// May return ErrInternal or ErrNotFound on failure.
+func (sdm *SqlUserRepository) ClearStoredBanner(uuid string) error {
+	res, err := sdm.DBProvider.GetDb().Exec(
+		"UPDATE users SET banner = NULL WHERE id = $1", uuid)
+	if err != nil {
+		return &customerrors.ErrInternal{Message: err.Error()}
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return &customerrors.ErrInternal{Message: err.Error()}
+	} else if rowsAffected < 1 {
+		return &customerrors.ErrNotFound{Message: "user not found"}
+	}
+	return nil
+}
+
+// Drops the picture and banner columns left by versions before
+// the blob store, once everything in them is in the blob store.
+// May return ErrInternal on failure.
+func (sdm *SqlUserRepository) DropStoredBlobColumns() error {
+	_, err := sdm.DBProvider.GetDb().Exec(`ALTER TABLE users
+		DROP COLUMN IF EXISTS picture, DROP COLUMN IF EXISTS banner`)
+	if err != nil {
+		return &customerrors.ErrInternal{Message: err.Error()}
+	}
+	return nil
+}
+
+// Returns a user's privacy settings by its uuid.
+// UUID validation is not provided.
+// May return ErrInternal or ErrNotFound on failure.
+func (sdm *SqlUserRepository) GetPrivacy(uuid string) (*models.PrivacySettings, error) {
+	privacy := models.PrivacySettings{}
+	err := sdm.DBProvider.GetDb().QueryRow(`
+		SELECT discoverable, friend_requests_from, picture_visibility, activity_visibility
+		FROM users WHERE id = $1`, uuid).Scan(
+		&privacy.Discoverable, &privacy.FriendRequests,
+		&privacy.PictureVisibility, &privacy.ActivityVisibility)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, &customerrors.ErrNotFound{Message: "user not found"}
+		} else {
+			return nil, &customerrors.ErrInternal{Message: err.Error()}
+		}
+	}
+	return &privacy, nil
+}
+
+// Updates a user's privacy settings by its uuid.
+// UUID validation is not provided.
+// May return ErrInternal or ErrNotFound on failure.
+func (sdm *SqlUserRepository) UpdatePrivacy(uuid string, privacy models.PrivacySettings) error {
+	res, err := sdm.DBProvider.GetDb().Exec(`
+		UPDATE users SET discoverable = $1, friend_requests_from = $2,
+		picture_visibility = $3, activity_visibility = $4 WHERE id = $5`,
+		privacy.Discoverable, privacy.FriendRequests,
+		privacy.PictureVisibility, privacy.ActivityVisibility, uuid)
+	if err != nil {
+		return &customerrors.ErrInternal{Message: err.Error()}
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return &customerrors.ErrInternal{Message: err.Error()}
+	} else if rowsAffected < 1 {
+		return &customerrors.ErrNotFound{Message: "user not found"}
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+package actions
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"spotigram/internal/customerrors"
+	"spotigram/internal/utility"
+)
+
+// A message pushed to a user as a side effect of an action.
+type Notification struct {
+	Status     string
+	ReceiverId string
+	Content    any
+}
+
+// The authenticated user running an action.
+type Caller struct {
+	Id   string
+	Role string
+	// Id of the websocket request running the action, empty over http.
+	RequestId string
+}
+
+// Outcome of a successfully run action.
+type Result struct {
+	// Response body, nil when the action returns nothing.
+	Data any
+	// Notifications to push to the affected users.
+	Notifications []Notification
+	// Users whose open connections are closed once the notifications are sent.
+	Disconnect []string
+}
+
+// Registration of a use case as an action.
+// T is the input model of the use case, R is its result.
+type Spec[T any, R any] struct {
+	// Unique name of the action, used as the websocket "action".
+	Name string
+
+	// Sets the authenticated user on the decoded input.
+	// May be nil if the input does not carry the user.
+	Bind func(input *T, caller Caller)
+
+	// Runs the use case.
+	Handle func(input T) (R, error)
+
+	// Converts the use case result to the response body.
+	// The result itself is used if nil.
+	Respond func(input T, result R) any
+
+	// Builds the notifications caused by the action.
+	// May be nil.
+	Notify func(userId string, input T, result R) []Notification
+
+	// Returns the users whose sessions the action ended,
+	// their open connections are closed. May be nil.
+	Disconnect func(userId string, input T, result R) []string
+}
+
+type action struct {
+	run func(caller Caller, decode func(input any) error) (*Result, error)
+}
+
+var registry = make(map[string]*action)
+
+// Registers a use case as an action.
+// Panics if the name is already taken.
+func Register[T any, R any](spec Spec[T, R]) {
+	if _, ok := registry[spec.Name]; ok {
+		panic(fmt.Errorf("action %q is already registered", spec.Name))
+	}
+
+	registry[spec.Name] = &action{
+		run: func(caller Caller, decode func(input any) error) (*Result, error) {
+			var input T
+			if err := decode(&input); err != nil {
+				return nil, err
+			}
+			if spec.Bind != nil {
+				spec.Bind(&input, caller)
+			}
+
+			output, err := spec.Handle(input)
+			if err != nil {
+				return nil, err
+			}
+
+			result := &Result{Data: output}
+			if spec.Respond != nil {
+				result.Data = spec.Respond(input, output)
+			}
+			if spec.Notify != nil {
+				result.Notifications = spec.Notify(caller.Id, input, output)
+			}
+			if spec.Disconnect != nil {
+				result.Disconnect = spec.Disconnect(caller.Id, input, output)
+			}
+			return result, nil
+		},
+	}
+}
+
+// Returns bool on whether the action is registered.
+func Exists(name string) bool {
+	_, ok := registry[name]
+	return ok
+}
+
+// Runs a registered action on behalf of the caller.
+// Content is the JSON encoded input, may be empty.
+// Returns the errors of the use case, or ErrInvalidInput for
+// an unknown action or undecodable content.
+func Run(name string, caller Caller, content []byte) (*Result, error) {
+	a, ok := registry[name]
+	if !ok {
+		return nil, customerrors.InvalidField("action", "")
+	}
+	return a.run(caller, func(input any) error {
+		if len(content) == 0 {
+			return nil
+		}
+		if err := json.Unmarshal(content, input); err != nil {
+			return customerrors.InvalidField("content", "")
+		}
+		return nil
+	})
+}
+
+// Runs a registered action on behalf of the caller,
+// with the input decoded from url query arguments.
+// Returns the errors of the use case, or ErrInvalidInput for
+// an unknown action or undecodable arguments.
+func RunQuery(name string, caller Caller, args map[string]string) (*Result, error) {
+	a, ok := registry[name]
+	if !ok {
+		return nil, customerrors.InvalidField("action", "")
+	}
+	return a.run(caller, func(input any) error {
+		if err := utility.DecodeQuery(args, input); err != nil {
+			return &customerrors.ErrInvalidInput{Message: err.Error()}
+		}
+		return nil
+	})
+}
+
+// Adapts a use case without a result to a Spec handler.
+func Void[T any](f func(input T) error) func(input T) (any, error) {
+	return func(input T) (any, error) {
+		return nil, f(input)
+	}
+}
+
+var notifier = func(Notification) {}
+
+// Sets the function used to push notifications to users.
+func SetNotifier(f func(Notification)) {
+	notifier = f
+}
+
+// Pushes the notifications through the notifier.
+func Dispatch(notifications []Notification) {
+	for _, n := range notifications {
+		notifier(n)
+	}
+}
+
+var disconnector = func(string) {}
+
+// Sets the function used to close the open connections of a user.
+func SetDisconnector(f func(userId string)) {
+	disconnector = f
+}
+
+// Closes the open connections of the users through the disconnector.
+func Disconnect(userIds []string) {
+	for _, id := range userIds {
+		disconnector(id)
+	}
+}
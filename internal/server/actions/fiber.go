@@ -0,0 +1,42 @@
+package actions
+
+import (
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Returns a fiber handler running the action on behalf of the user set by
+// the token deserialization. GET requests with a query string take their
+// input from it, other requests from the JSON body.
+// Panics if the action is not registered.
+func Handler(name string) fiber.Handler {
+	if !Exists(name) {
+		panic(fmt.Errorf("action %q is not registered", name))
+	}
+
+	return func(ctx *fiber.Ctx) error {
+		caller := Caller{}
+		caller.Id, _ = ctx.Locals("user_uuid").(string)
+		caller.Role, _ = ctx.Locals("user_role").(string)
+
+		var result *Result
+		var err error
+		if query := ctx.Queries(); ctx.Method() == fiber.MethodGet && len(query) > 0 {
+			result, err = RunQuery(name, caller, query)
+		} else {
+			result, err = Run(name, caller, ctx.Body())
+		}
+		if err != nil {
+			return err
+		}
+
+		Dispatch(result.Notifications)
+		Disconnect(result.Disconnect)
+
+		if result.Data == nil {
+			return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"status": "ok"})
+		}
+		return ctx.Status(fiber.StatusOK).JSON(result.Data)
+	}
+}
@@ -0,0 +1,701 @@
+package actions
+
+import (
+	"spotigram/internal/service/models"
+	"spotigram/internal/service/usecases"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Registers every JSON based use case as an action.
+func RegisterUsecases() {
+	registerMe()
+	registerUser()
+	registerFriends()
+	registerFollows()
+	registerBlocks()
+	registerChat()
+	registerSong()
+	registerPlaylist()
+	registerModeration()
+	registerAdmin()
+}
+
+func registerMe() {
+	Register(Spec[models.GetUserInfoInput, *models.User]{
+		Name:   "get-my-info",
+		Bind:   func(in *models.GetUserInfoInput, c Caller) { in.UserUUID = c.Id },
+		Handle: usecases.GetUserInfo,
+	})
+
+	Register(Spec[models.GetPublicKeyInput, string]{
+		Name:   "get-my-public-key",
+		Bind:   func(in *models.GetPublicKeyInput, c Caller) { in.UserUUID = c.Id },
+		Handle: usecases.GetPublicKey,
+		Respond: func(_ models.GetPublicKeyInput, key string) any {
+			return fiber.Map{"public_key": key}
+		},
+	})
+
+	Register(Spec[models.ChangeNameInput, any]{
+		Name:   "change-name",
+		Bind:   func(in *models.ChangeNameInput, c Caller) { in.UserUUID = c.Id },
+		Handle: Void(usecases.ChangeName),
+	})
+
+	Register(Spec[models.ChangePasswordInput, any]{
+		Name:   "change-password",
+		Bind:   func(in *models.ChangePasswordInput, c Caller) { in.UserUUID = c.Id },
+		Handle: Void(usecases.ChangePassword),
+	})
+
+	Register(Spec[models.ChangePublicKeyInput, any]{
+		Name:   "change-public-key",
+		Bind:   func(in *models.ChangePublicKeyInput, c Caller) { in.UserUUID = c.Id },
+		Handle: Void(usecases.ChangePublicKey),
+	})
+
+	Register(Spec[models.ChangeHandleInput, any]{
+		Name:   "change-handle",
+		Bind:   func(in *models.ChangeHandleInput, c Caller) { in.UserUUID = c.Id },
+		Handle: Void(usecases.ChangeHandle),
+	})
+
+	Register(Spec[models.UpdateProfileInput, any]{
+		Name:   "update-profile",
+		Bind:   func(in *models.UpdateProfileInput, c Caller) { in.UserUUID = c.Id },
+		Handle: Void(usecases.UpdateProfile),
+	})
+
+	Register(Spec[models.GetPrivacyInput, *models.PrivacySettings]{
+		Name:   "get-privacy",
+		Bind:   func(in *models.GetPrivacyInput, c Caller) { in.UserUUID = c.Id },
+		Handle: usecases.GetPrivacy,
+	})
+
+	Register(Spec[models.UpdatePrivacyInput, any]{
+		Name:   "update-privacy",
+		Bind:   func(in *models.UpdatePrivacyInput, c Caller) { in.UserUUID = c.Id },
+		Handle: Void(usecases.UpdatePrivacy),
+	})
+
+	Register(Spec[models.GetActivityInput, *models.Page[models.LastStream]]{
+		Name:   "get-my-activity",
+		Bind:   func(in *models.GetActivityInput, c Caller) { in.UserUUID = c.Id },
+		Handle: usecases.GetActivity,
+	})
+
+	Register(Spec[models.DeleteAccountInput, []string]{
+		Name:   "delete-account",
+		Bind:   func(in *models.DeleteAccountInput, c Caller) { in.UserUUID = c.Id },
+		Handle: usecases.DeleteAccount,
+		Respond: func(models.DeleteAccountInput, []string) any {
+			return nil
+		},
+		Notify: func(userId string, _ models.DeleteAccountInput, friendIds []string) []Notification {
+			return friendsDeleted(userId, friendIds)
+		},
+		Disconnect: func(userId string, _ models.DeleteAccountInput, _ []string) []string {
+			return []string{userId}
+		},
+	})
+}
+
+func registerUser() {
+	Register(Spec[models.GetUsersInput, *models.Page[models.User]]{
+		Name:   "get-users",
+		Bind:   func(in *models.GetUsersInput, c Caller) { in.RequesterUUID = c.Id },
+		Handle: usecases.GetUsers,
+	})
+
+	Register(Spec[models.GetUserInfoInput, *models.User]{
+		Name:   "get-user-info",
+		Bind:   func(in *models.GetUserInfoInput, c Caller) { in.RequesterUUID = c.Id },
+		Handle: usecases.GetUserInfo,
+	})
+
+	Register(Spec[models.GetPublicKeyInput, string]{
+		Name:   "get-user-public-key",
+		Bind:   func(in *models.GetPublicKeyInput, c Caller) { in.RequesterUUID = c.Id },
+		Handle: usecases.GetPublicKey,
+		Respond: func(_ models.GetPublicKeyInput, key string) any {
+			return fiber.Map{"public_key": key}
+		},
+	})
+
+	Register(Spec[models.GetActivityInput, *models.Page[models.LastStream]]{
+		Name:   "get-user-activity",
+		Bind:   func(in *models.GetActivityInput, c Caller) { in.RequesterUUID = c.Id },
+		Handle: usecases.GetActivity,
+	})
+}
+
+func registerFollows() {
+	Register(Spec[models.FollowInput, any]{
+		Name:   "follow",
+		Bind:   func(in *models.FollowInput, c Caller) { in.UserUUID = c.Id },
+		Handle: Void(usecases.FollowUser),
+	})
+
+	Register(Spec[models.UnfollowInput, any]{
+		Name:   "unfollow",
+		Bind:   func(in *models.UnfollowInput, c Caller) { in.UserUUID = c.Id },
+		Handle: Void(usecases.UnfollowUser),
+	})
+
+	Register(Spec[models.GetFollowsInput, *models.Page[models.Follow]]{
+		Name:   "get-my-followers",
+		Bind:   func(in *models.GetFollowsInput, c Caller) { in.UserUUID = c.Id },
+		Handle: usecases.GetFollowers,
+	})
+
+	Register(Spec[models.GetFollowsInput, *models.Page[models.Follow]]{
+		Name:   "get-my-following",
+		Bind:   func(in *models.GetFollowsInput, c Caller) { in.UserUUID = c.Id },
+		Handle: usecases.GetFollowing,
+	})
+
+	Register(Spec[models.GetFollowCountsInput, *models.FollowCounts]{
+		Name:   "get-my-follow-counts",
+		Bind:   func(in *models.GetFollowCountsInput, c Caller) { in.UserUUID = c.Id },
+		Handle: usecases.GetFollowCounts,
+	})
+
+	Register(Spec[models.GetFollowsInput, *models.Page[models.Follow]]{
+		Name:   "get-user-followers",
+		Bind:   func(in *models.GetFollowsInput, c Caller) { in.RequesterUUID = c.Id },
+		Handle: usecases.GetFollowers,
+	})
+
+	Register(Spec[models.GetFollowsInput, *models.Page[models.Follow]]{
+		Name:   "get-user-following",
+		Bind:   func(in *models.GetFollowsInput, c Caller) { in.RequesterUUID = c.Id },
+		Handle: usecases.GetFollowing,
+	})
+
+	Register(Spec[models.GetFollowCountsInput, *models.FollowCounts]{
+		Name:   "get-user-follow-counts",
+		Bind:   func(in *models.GetFollowCountsInput, c Caller) { in.RequesterUUID = c.Id },
+		Handle: usecases.GetFollowCounts,
+	})
+
+	Register(Spec[models.GetFeedInput, *models.Page[models.Song]]{
+		Name:   "get-feed",
+		Bind:   func(in *models.GetFeedInput, c Caller) { in.UserUUID = c.Id },
+		Handle: usecases.GetFeed,
+	})
+}
+
+func registerFriends() {
+	Register(Spec[models.GetFriendsInput, *models.Page[models.Friend]]{
+		Name:   "get-friends",
+		Bind:   func(in *models.GetFriendsInput, c Caller) { in.UserUUID = c.Id },
+		Handle: usecases.GetFriends,
+	})
+
+	Register(Spec[models.DeleteFriendInput, any]{
+		Name:   "delete-friend",
+		Bind:   func(in *models.DeleteFriendInput, c Caller) { in.User1UUID = c.Id },
+		Handle: Void(usecases.DeleteFriend),
+		Notify: func(userId string, in models.DeleteFriendInput, _ any) []Notification {
+			return []Notification{{
+				Status:     "friend-deleted",
+				ReceiverId: in.User2UUID,
+				Content: models.Friend{
+					Id1: in.User2UUID,
+					Id2: userId,
+				},
+			}}
+		},
+	})
+
+	Register(Spec[models.GetFriendSuggestionsInput, *models.Page[models.FriendSuggestion]]{
+		Name:   "get-friend-suggestions",
+		Bind:   func(in *models.GetFriendSuggestionsInput, c Caller) { in.UserUUID = c.Id },
+		Handle: usecases.GetFriendSuggestions,
+	})
+
+	Register(Spec[models.GetFriendRequestsSentInput, *models.Page[models.FriendRequest]]{
+		Name:   "get-friend-requests-sent",
+		Bind:   func(in *models.GetFriendRequestsSentInput, c Caller) { in.UserUUID = c.Id },
+		Handle: usecases.GetFriendRequestsSent,
+	})
+
+	Register(Spec[models.GetFriendRequestsReceivedInput, *models.Page[models.FriendRequest]]{
+		Name:   "get-friend-requests-received",
+		Bind:   func(in *models.GetFriendRequestsReceivedInput, c Caller) { in.UserUUID = c.Id },
+		Handle: usecases.GetFriendRequestsReceived,
+	})
+
+	Register(Spec[models.AddFriendRequestInput, any]{
+		Name:   "send-friend-request",
+		Bind:   func(in *models.AddFriendRequestInput, c Caller) { in.SenderUUID = c.Id },
+		Handle: Void(usecases.AddFriendRequest),
+		Notify: func(userId string, in models.AddFriendRequestInput, _ any) []Notification {
+			return []Notification{{
+				Status:     "friend-request-received",
+				ReceiverId: in.RecipientUUID,
+				Content: models.FriendRequest{
+					SenderId:    userId,
+					RecipientId: in.RecipientUUID,
+				},
+			}}
+		},
+	})
+
+	Register(Spec[models.DeleteFriendRequestInput, any]{
+		Name:   "delete-friend-request",
+		Bind:   func(in *models.DeleteFriendRequestInput, c Caller) { in.SenderUUID = c.Id },
+		Handle: Void(usecases.DeleteFriendRequest),
+		Notify: func(userId string, in models.DeleteFriendRequestInput, _ any) []Notification {
+			return []Notification{{
+				Status:     "friend-request-deleted",
+				ReceiverId: in.RecipientUUID,
+				Content: models.FriendRequest{
+					SenderId:    userId,
+					RecipientId: in.RecipientUUID,
+				},
+			}}
+		},
+	})
+
+	Register(Spec[models.UpdateFriendRequestInput, any]{
+		Name:   "update-friend-request",
+		Bind:   func(in *models.UpdateFriendRequestInput, c Caller) { in.RecipientUUID = c.Id },
+		Handle: Void(usecases.UpdateFriendRequest),
+		Notify: func(userId string, in models.UpdateFriendRequestInput, _ any) []Notification {
+			return []Notification{{
+				Status:     "friend-request-updated",
+				ReceiverId: in.SenderUUID,
+				Content: models.FriendRequest{
+					SenderId:    in.SenderUUID,
+					RecipientId: userId,
+					IsIgnored:   in.IsIgnored,
+				},
+			}}
+		},
+	})
+
+	Register(Spec[models.AcceptFriendRequestInput, *models.Friend]{
+		Name:   "accept-friend-request",
+		Bind:   func(in *models.AcceptFriendRequestInput, c Caller) { in.RecipientUUID = c.Id },
+		Handle: usecases.AcceptFriendRequest,
+		Notify: func(userId string, in models.AcceptFriendRequestInput, friend *models.Friend) []Notification {
+			return []Notification{
+				{Status: "friend-request-accepted", ReceiverId: in.SenderUUID, Content: friend},
+				{Status: "friend-added", ReceiverId: userId, Content: friend},
+			}
+		},
+	})
+}
+
+// Result of the use cases notifying another chat member.
+type chatResult[T any] struct {
+	RecipientId string
+	Content     T
+}
+
+func registerBlocks() {
+	Register(Spec[models.GetBlockedUsersInput, *models.Page[models.Block]]{
+		Name:   "get-blocked-users",
+		Bind:   func(in *models.GetBlockedUsersInput, c Caller) { in.UserUUID = c.Id },
+		Handle: usecases.GetBlockedUsers,
+	})
+
+	Register(Spec[models.BlockUserInput, any]{
+		Name:   "block-user",
+		Bind:   func(in *models.BlockUserInput, c Caller) { in.UserUUID = c.Id },
+		Handle: Void(usecases.BlockUser),
+	})
+
+	Register(Spec[models.UnblockUserInput, any]{
+		Name:   "unblock-user",
+		Bind:   func(in *models.UnblockUserInput, c Caller) { in.UserUUID = c.Id },
+		Handle: Void(usecases.UnblockUser),
+	})
+}
+
+func registerChat() {
+	Register(Spec[models.GetMessagesInput, []models.Message]{
+		Name:   "get-messages",
+		Bind:   func(in *models.GetMessagesInput, c Caller) { in.UserId = c.Id },
+		Handle: usecases.GetMessages,
+	})
+
+	Register(Spec[models.GetUnreadMessagesInput, []models.Message]{
+		Name:   "get-unread-messages",
+		Bind:   func(in *models.GetUnreadMessagesInput, c Caller) { in.UserId = c.Id },
+		Handle: usecases.GetUnreadMessages,
+	})
+
+	Register(Spec[models.SendMessageInput, chatResult[*models.Message]]{
+		Name: "send-message",
+		Bind: func(in *models.SendMessageInput, c Caller) { in.UserId = c.Id },
+		Handle: func(in models.SendMessageInput) (chatResult[*models.Message], error) {
+			recipientId, message, err := usecases.SendMessage(models.Message{
+				UserId:      in.UserId,
+				ChatId:      in.ChatId,
+				Content:     in.Content,
+				IsEncrypted: in.IsEncrypted,
+				TimeId:      in.TimeId,
+			})
+			return chatResult[*models.Message]{recipientId, message}, err
+		},
+		Respond: func(_ models.SendMessageInput, r chatResult[*models.Message]) any {
+			return r.Content
+		},
+		Notify: func(userId string, in models.SendMessageInput, r chatResult[*models.Message]) []Notification {
+			notifications := []Notification{
+				{Status: "message-received", ReceiverId: userId, Content: r.Content},
+			}
+			if !usecases.IsChatMuted(r.RecipientId, in.ChatId) {
+				notifications = append([]Notification{
+					{Status: "message-received", ReceiverId: r.RecipientId, Content: r.Content},
+				}, notifications...)
+			}
+			return notifications
+		},
+	})
+
+	Register(Spec[models.DeleteMessageInput, string]{
+		Name:   "delete-message",
+		Bind:   func(in *models.DeleteMessageInput, c Caller) { in.UserId = c.Id },
+		Handle: usecases.DeleteMessage,
+		Respond: func(models.DeleteMessageInput, string) any {
+			return nil
+		},
+		Notify: func(userId string, in models.DeleteMessageInput, recipientId string) []Notification {
+			return []Notification{
+				{Status: "message-deleted", ReceiverId: recipientId, Content: in},
+				{Status: "message-deleted", ReceiverId: userId, Content: in},
+			}
+		},
+	})
+
+	Register(Spec[models.GetReadTimeInput, *models.ReadTime]{
+		Name:   "get-read-time",
+		Bind:   func(in *models.GetReadTimeInput, c Caller) { in.UserId = c.Id },
+		Handle: usecases.GetReadTime,
+		Notify: func(userId string, _ models.GetReadTimeInput, readTime *models.ReadTime) []Notification {
+			return []Notification{
+				{Status: "read-time-received", ReceiverId: userId, Content: readTime},
+			}
+		},
+	})
+
+	Register(Spec[models.UpdateReadTimeInput, chatResult[*models.ReadTime]]{
+		Name: "update-read-time",
+		Bind: func(in *models.UpdateReadTimeInput, c Caller) { in.UserId = c.Id },
+		Handle: func(in models.UpdateReadTimeInput) (chatResult[*models.ReadTime], error) {
+			readTime, recipientId, err := usecases.UpdateReadTime(in)
+			return chatResult[*models.ReadTime]{recipientId, readTime}, err
+		},
+		Respond: func(_ models.UpdateReadTimeInput, r chatResult[*models.ReadTime]) any {
+			return r.Content
+		},
+		Notify: func(_ string, _ models.UpdateReadTimeInput, r chatResult[*models.ReadTime]) []Notification {
+			return []Notification{
+				{Status: "read-time-received", ReceiverId: r.RecipientId, Content: r.Content},
+			}
+		},
+	})
+
+	Register(Spec[models.MuteChatInput, any]{
+		Name:   "mute-chat",
+		Bind:   func(in *models.MuteChatInput, c Caller) { in.UserId = c.Id },
+		Handle: Void(usecases.MuteChat),
+	})
+
+	Register(Spec[models.UnmuteChatInput, any]{
+		Name:   "unmute-chat",
+		Bind:   func(in *models.UnmuteChatInput, c Caller) { in.UserId = c.Id },
+		Handle: Void(usecases.UnmuteChat),
+	})
+}
+
+func registerSong() {
+	Register(Spec[models.GetSongsInput, *models.Page[models.Song]]{
+		Name:   "get-songs",
+		Handle: usecases.GetSongs,
+	})
+
+	Register(Spec[models.GetSongInfoInput, *models.Song]{
+		Name:   "get-song-info",
+		Bind:   func(in *models.GetSongInfoInput, c Caller) { in.UserId, in.UserRole = c.Id, c.Role },
+		Handle: usecases.GetSongInfo,
+	})
+
+	Register(Spec[models.GetUploadStatusInput, *models.UploadJob]{
+		Name:   "get-upload-status",
+		Bind:   func(in *models.GetUploadStatusInput, c Caller) { in.UserId, in.UserRole = c.Id, c.Role },
+		Handle: usecases.GetUploadStatus,
+	})
+
+	Register(Spec[models.UpdateSongNameInput, any]{
+		Name:   "rename-song",
+		Bind:   func(in *models.UpdateSongNameInput, c Caller) { in.UserId, in.UserRole = c.Id, c.Role },
+		Handle: Void(usecases.UpdateSongName),
+	})
+
+	Register(Spec[models.DeleteSongInput, any]{
+		Name:   "delete-song",
+		Bind:   func(in *models.DeleteSongInput, c Caller) { in.UserId, in.UserRole = c.Id, c.Role },
+		Handle: Void(usecases.DeleteSong),
+	})
+}
+
+func registerPlaylist() {
+	Register(Spec[models.GetPlaylistsInput, *models.Page[models.Playlist]]{
+		Name:   "get-playlists",
+		Bind:   func(in *models.GetPlaylistsInput, c Caller) { in.UserId = c.Id },
+		Handle: usecases.GetPlaylists,
+	})
+
+	Register(Spec[models.GetPlaylistSongsInput, []models.PlaylistSong]{
+		Name:   "get-playlist-songs",
+		Bind:   func(in *models.GetPlaylistSongsInput, c Caller) { in.UserId, in.UserRole = c.Id, c.Role },
+		Handle: usecases.GetPlaylistSongs,
+	})
+
+	Register(Spec[models.GetAlbumsInput, *models.Page[models.Album]]{
+		Name:   "get-albums",
+		Handle: usecases.GetAlbums,
+	})
+
+	Register(Spec[models.GetAlbumInfoInput, *models.AlbumInfo]{
+		Name:   "get-album-info",
+		Handle: usecases.GetAlbumInfo,
+	})
+
+	Register(Spec[models.AddAlbumInput, *models.Album]{
+		Name:   "create-album",
+		Bind:   func(in *models.AddAlbumInput, c Caller) { in.UserId, in.UserRole = c.Id, c.Role },
+		Handle: usecases.AddAlbum,
+	})
+
+	Register(Spec[models.DeleteAlbumInput, any]{
+		Name:   "delete-album",
+		Bind:   func(in *models.DeleteAlbumInput, c Caller) { in.UserId, in.UserRole = c.Id, c.Role },
+		Handle: Void(usecases.DeleteAlbum),
+	})
+
+	Register(Spec[models.AddPlaylistInput, string]{
+		Name:   "create-playlist",
+		Bind:   func(in *models.AddPlaylistInput, c Caller) { in.UserId = c.Id },
+		Handle: usecases.AddPlaylist,
+		Respond: func(_ models.AddPlaylistInput, id string) any {
+			return fiber.Map{"id": id}
+		},
+	})
+
+	Register(Spec[models.UpdatePlaylistNameInput, any]{
+		Name:   "rename-playlist",
+		Bind:   func(in *models.UpdatePlaylistNameInput, c Caller) { in.UserId, in.UserRole = c.Id, c.Role },
+		Handle: Void(usecases.UpdatePlaylistName),
+	})
+
+	Register(Spec[models.DeletePlaylistInput, any]{
+		Name:   "delete-playlist",
+		Bind:   func(in *models.DeletePlaylistInput, c Caller) { in.UserId, in.UserRole = c.Id, c.Role },
+		Handle: Void(usecases.DeletePlaylist),
+	})
+
+	Register(Spec[models.AddPlaylistSongInput, any]{
+		Name:   "add-playlist-song",
+		Bind:   func(in *models.AddPlaylistSongInput, c Caller) { in.UserId, in.UserRole = c.Id, c.Role },
+		Handle: Void(usecases.AddPlaylistSong),
+	})
+
+	Register(Spec[models.DeletePlaylistSongInput, any]{
+		Name:   "delete-playlist-song",
+		Bind:   func(in *models.DeletePlaylistSongInput, c Caller) { in.UserId, in.UserRole = c.Id, c.Role },
+		Handle: Void(usecases.DeletePlaylistSong),
+	})
+}
+
+func registerModeration() {
+	Register(Spec[models.ReportInput, string]{
+		Name:   "report",
+		Bind:   func(in *models.ReportInput, c Caller) { in.UserId = c.Id },
+		Handle: usecases.ReportContent,
+		Respond: func(_ models.ReportInput, id string) any {
+			return fiber.Map{"id": id}
+		},
+	})
+
+	Register(Spec[models.GetReportsInput, *models.Page[models.Report]]{
+		Name:   "get-reports",
+		Bind:   func(in *models.GetReportsInput, c Caller) { in.UserId, in.UserRole = c.Id, c.Role },
+		Handle: usecases.GetReports,
+	})
+
+	Register(Spec[models.ResolveReportInput, any]{
+		Name:   "resolve-report",
+		Bind:   func(in *models.ResolveReportInput, c Caller) { in.UserId, in.UserRole = c.Id, c.Role },
+		Handle: Void(usecases.ResolveReport),
+	})
+
+	Register(Spec[models.ModerationInput, any]{
+		Name:   "hide-song",
+		Bind:   func(in *models.ModerationInput, c Caller) { in.UserId, in.UserRole = c.Id, c.Role },
+		Handle: Void(usecases.HideSong),
+	})
+
+	Register(Spec[models.ModerationInput, any]{
+		Name:   "unhide-song",
+		Bind:   func(in *models.ModerationInput, c Caller) { in.UserId, in.UserRole = c.Id, c.Role },
+		Handle: Void(usecases.UnhideSong),
+	})
+
+	Register(Spec[models.ModerationInput, any]{
+		Name:   "suspend-user",
+		Bind:   func(in *models.ModerationInput, c Caller) { in.UserId, in.UserRole = c.Id, c.Role },
+		Handle: Void(usecases.SuspendUser),
+		Notify: func(_ string, in models.ModerationInput, _ any) []Notification {
+			return []Notification{{
+				Status:     "account-suspended",
+				ReceiverId: in.TargetId,
+				Content:    fiber.Map{"reason": in.Reason},
+			}}
+		},
+		Disconnect: func(_ string, in models.ModerationInput, _ any) []string {
+			return []string{in.TargetId}
+		},
+	})
+
+	Register(Spec[models.ModerationInput, any]{
+		Name:   "unsuspend-user",
+		Bind:   func(in *models.ModerationInput, c Caller) { in.UserId, in.UserRole = c.Id, c.Role },
+		Handle: Void(usecases.UnsuspendUser),
+	})
+
+	Register(Spec[models.GetAuditLogInput, *models.Page[models.AuditLogEntry]]{
+		Name:   "get-audit-log",
+		Bind:   func(in *models.GetAuditLogInput, c Caller) { in.UserId, in.UserRole = c.Id, c.Role },
+		Handle: usecases.GetAuditLog,
+	})
+}
+
+func registerAdmin() {
+	Register(Spec[models.SetRoleInput, any]{
+		Name:   "set-role",
+		Bind:   func(in *models.SetRoleInput, c Caller) { in.UserId, in.UserRole = c.Id, c.Role },
+		Handle: Void(usecases.SetRole),
+		Notify: func(_ string, in models.SetRoleInput, _ any) []Notification {
+			return []Notification{{
+				Status:     "role-changed",
+				ReceiverId: in.TargetId,
+				Content:    fiber.Map{"role": in.Role},
+			}}
+		},
+		Disconnect: func(_ string, in models.SetRoleInput, _ any) []string {
+			return []string{in.TargetId}
+		},
+	})
+
+	Register(Spec[models.AdminGetUsersInput, *models.Page[models.User]]{
+		Name:   "admin-get-users",
+		Bind:   func(in *models.AdminGetUsersInput, c Caller) { in.UserRole = c.Role },
+		Handle: usecases.AdminGetUsers,
+	})
+
+	Register(Spec[models.ModerationInput, *models.UserOverview]{
+		Name:   "admin-get-user",
+		Bind:   func(in *models.ModerationInput, c Caller) { in.UserId, in.UserRole = c.Id, c.Role },
+		Handle: usecases.AdminGetUserOverview,
+	})
+
+	Register(Spec[models.ModerationInput, any]{
+		Name:   "admin-verify-user",
+		Bind:   func(in *models.ModerationInput, c Caller) { in.UserId, in.UserRole = c.Id, c.Role },
+		Handle: Void(usecases.AdminVerifyUser),
+	})
+
+	Register(Spec[models.ModerationInput, string]{
+		Name:   "admin-reset-password",
+		Bind:   func(in *models.ModerationInput, c Caller) { in.UserId, in.UserRole = c.Id, c.Role },
+		Handle: usecases.AdminResetPassword,
+		Respond: func(_ models.ModerationInput, password string) any {
+			return fiber.Map{"password": password}
+		},
+		Disconnect: func(_ string, in models.ModerationInput, _ string) []string {
+			return []string{in.TargetId}
+		},
+	})
+
+	Register(Spec[models.ModerationInput, int64]{
+		Name:   "admin-revoke-sessions",
+		Bind:   func(in *models.ModerationInput, c Caller) { in.UserId, in.UserRole = c.Id, c.Role },
+		Handle: usecases.AdminRevokeSessions,
+		Respond: func(_ models.ModerationInput, revoked int64) any {
+			return fiber.Map{"revoked": revoked}
+		},
+		Disconnect: func(_ string, in models.ModerationInput, _ int64) []string {
+			return []string{in.TargetId}
+		},
+	})
+
+	Register(Spec[models.ModerationInput, []string]{
+		Name:   "admin-delete-user",
+		Bind:   func(in *models.ModerationInput, c Caller) { in.UserId, in.UserRole = c.Id, c.Role },
+		Handle: usecases.AdminDeleteUser,
+		Respond: func(models.ModerationInput, []string) any {
+			return nil
+		},
+		Notify: func(_ string, in models.ModerationInput, friendIds []string) []Notification {
+			return friendsDeleted(in.TargetId, friendIds)
+		},
+		Disconnect: func(_ string, in models.ModerationInput, _ []string) []string {
+			return []string{in.TargetId}
+		},
+	})
+}
+
+// Notifies the former friends of a deleted account.
+func friendsDeleted(userId string, friendIds []string) []Notification {
+	notifications := []Notification{}
+	for _, id := range friendIds {
+		notifications = append(notifications, Notification{
+			Status:     "friend-deleted",
+			ReceiverId: id,
+			Content: models.Friend{
+				Id1: id,
+				Id2: userId,
+			},
+		})
+	}
+	return notifications
+}
+
+// Notifies the uploader about the new status of an upload job,
+// followers are notified as well once the song is released.
+func UploadStatus(job models.UploadJob, song *models.Song) []Notification {
+	notifications := []Notification{{
+		Status:     "upload-status",
+		ReceiverId: job.UserId,
+		Content:    job,
+	}}
+	if song != nil {
+		notifications = append(notifications, NewRelease(*song)...)
+	}
+	return notifications
+}
+
+// Notifies the followers of a song creator about the new song,
+// failing to list the followers only skips the notifications.
+func NewRelease(song models.Song) []Notification {
+	followerIds, err := usecases.GetFollowerIds(song.CreatorId)
+	if err != nil {
+		return nil
+	}
+
+	notifications := []Notification{}
+	for _, id := range followerIds {
+		notifications = append(notifications, Notification{
+			Status:     "new-release",
+			ReceiverId: id,
+			Content:    song,
+		})
+	}
+	return notifications
+}
@@ -0,0 +1,83 @@
+package controllers
+
+import (
+	"io"
+	"spotigram/internal/customerrors"
+	"spotigram/internal/service/models"
+	"spotigram/internal/service/usecases"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// A handler to send the cover of an album.
+func AlbumCoverHandler(ctx *fiber.Ctx) error {
+	input := models.GetAlbumCoverInput{}
+	err := parseInput(ctx, &input)
+	if err != nil {
+		return &customerrors.ErrInvalidInput{Message: err.Error()}
+	}
+
+	cover, err := usecases.GetAlbumCover(input)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).Send(cover)
+}
+
+// A handler to change the cover of an album,
+// the album id comes in the query string.
+func ChangeAlbumCoverHandler(ctx *fiber.Ctx) error {
+	input := models.ChangeAlbumCoverInput{
+		UserId:  ctx.Locals("user_uuid").(string),
+		AlbumId: ctx.Query("id"),
+		Image:   ctx.Body(),
+	}
+	input.UserRole, _ = ctx.Locals("user_role").(string)
+
+	err := usecases.ChangeAlbumCover(input)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"status": "ok"})
+}
+
+// A handler to upload the songs of an album at once,
+// the songs come as the "files" of a multipart form.
+func UploadAlbumSongsHandler(ctx *fiber.Ctx) error {
+	input := models.AddAlbumSongsInput{
+		UserId:  ctx.Locals("user_uuid").(string),
+		AlbumId: ctx.Query("id"),
+	}
+	input.UserRole, _ = ctx.Locals("user_role").(string)
+
+	form, err := ctx.MultipartForm()
+	if err != nil {
+		return &customerrors.ErrInvalidInput{Message: err.Error()}
+	}
+	for _, header := range form.File["files"] {
+		f, err := header.Open()
+		if err != nil {
+			return &customerrors.ErrInvalidInput{Message: err.Error()}
+		}
+		file, err := io.ReadAll(f)
+		f.Close()
+		if err != nil {
+			return &customerrors.ErrInvalidInput{Message: err.Error()}
+		}
+		input.Files = append(input.Files, file)
+	}
+
+	jobs, err := usecases.AddAlbumSongs(input)
+	if err != nil {
+		return err
+	}
+
+	ids := []string{}
+	for _, job := range jobs {
+		ids = append(ids, job.Id)
+	}
+	return ctx.Status(fiber.StatusAccepted).JSON(fiber.Map{
+		"status": "ok", "job_ids": ids})
+}
@@ -18,19 +18,12 @@ func SignUpHandler(ctx *fiber.Ctx) error {
 	sui := models.SignUpInput{}
 	err := json.Unmarshal((ctx.Body()), &sui)
 	if err != nil {
-		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"status": "fail", "message": err.Error()})
+		return &customerrors.ErrInvalidInput{Message: err.Error()}
 	}
 
 	err = usecases.SignUpUser(sui)
 	if err != nil {
-		if errInternal, ok := err.(*customerrors.ErrInternal); ok {
-			return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"status": "fail", "message": errInternal.Error()})
-		} else if errInvalidInput, ok := err.(*customerrors.ErrInvalidInput); ok {
-			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"status": "fail", "message": errInvalidInput.Error()})
-		}
+		return err
 	}
 
 	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"status": "ok"})
@@ -41,23 +34,13 @@ func SignInHandler(ctx *fiber.Ctx) error {
 	sii := models.SignInInput{}
 	err := json.Unmarshal(ctx.Body(), &sii)
 	if err != nil {
-		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"status": "fail", "message": err.Error()})
+		return &customerrors.ErrInvalidInput{Message: err.Error()}
 	}
 
 	userUuid, accessTokenDetails, refreshTokenDetails, err :=
 		usecases.SignInUser(sii, config.Cfg)
 	if err != nil {
-		if errInternal, ok := err.(*customerrors.ErrInternal); ok {
-			return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"status": "fail", "message": errInternal.Error()})
-		} else if errInvalidInput, ok := err.(*customerrors.ErrInvalidInput); ok {
-			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"status": "fail", "message": errInvalidInput.Error()})
-		} else if errNotFound, ok := err.(*customerrors.ErrNotFound); ok {
-			return ctx.Status(fiber.StatusNoContent).JSON(fiber.Map{
-				"status": "fail", "message": errNotFound.Error()})
-		}
+		return err
 	}
 
 	ctx.Cookie(&fiber.Cookie{
@@ -84,13 +67,7 @@ func LogoutHandler(ctx *fiber.Ctx) error {
 	}
 	err := usecases.Logout(input, config.Cfg)
 	if err != nil {
-		if errInternal, ok := err.(*customerrors.ErrInternal); ok {
-			return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"status": "fail", "message": errInternal.Error()})
-		} else if errUnauthorized, ok := err.(*customerrors.ErrUnauthorized); ok {
-			return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"status": "fail", "message": errUnauthorized.Error()})
-		}
+		return err
 	}
 
 	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"status": "ok"})
@@ -104,16 +81,7 @@ func RefreshAccessTokenHandler(ctx *fiber.Ctx) error {
 	accessTokenDetails, err :=
 		usecases.RefreshAccessToken(input, config.Cfg)
 	if err != nil {
-		if errInternal, ok := err.(*customerrors.ErrInternal); ok {
-			return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"status": "fail", "message": errInternal.Error()})
-		} else if errUnauthorized, ok := err.(*customerrors.ErrUnauthorized); ok {
-			return ctx.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"status": "fail", "message": errUnauthorized.Error()})
-		} else if errNotFound, ok := err.(*customerrors.ErrNotFound); ok {
-			return ctx.Status(fiber.StatusNoContent).JSON(fiber.Map{
-				"status": "fail", "message": errNotFound.Error()})
-		}
+		return err
 	}
 
 	ctx.Cookie(&fiber.Cookie{
@@ -0,0 +1,71 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"spotigram/internal/customerrors"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Body of every failed response.
+type errorResponse struct {
+	Status  string                    `json:"status"`
+	Code    string                    `json:"code"`
+	Message string                    `json:"message"`
+	Details []customerrors.FieldError `json:"details"`
+}
+
+// Maps an error code to its http status.
+var codeStatuses = map[string]int{
+	customerrors.CodeInvalidInput: fiber.StatusBadRequest,
+	customerrors.CodeUnauthorized: fiber.StatusUnauthorized,
+	customerrors.CodeForbidden:    fiber.StatusForbidden,
+	customerrors.CodeNotFound:     fiber.StatusNotFound,
+	customerrors.CodeInternal:     fiber.StatusInternalServerError,
+}
+
+// A fiber error handler answering every error returned by
+// the handlers with a JSON envelope and the matching status.
+// Fiber's own errors keep their status. Internal errors are
+// logged and answered with a generic message.
+func ErrorHandler(ctx *fiber.Ctx, err error) error {
+	response := errorResponse{
+		Status:  "fail",
+		Code:    customerrors.Code(err),
+		Message: err.Error(),
+		Details: customerrors.Details(err),
+	}
+	status := codeStatuses[response.Code]
+
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		status = fiberErr.Code
+		response.Code = fiberErrorCode(fiberErr.Code)
+	}
+	if response.Code == customerrors.CodeInternal {
+		log.Printf("%s %s: %v", ctx.Method(), ctx.Path(), err)
+		response.Message = customerrors.InternalMessage
+	}
+	if response.Details == nil {
+		response.Details = []customerrors.FieldError{}
+	}
+
+	return ctx.Status(status).JSON(response)
+}
+
+// Maps the status of a fiber error to an error code.
+func fiberErrorCode(status int) string {
+	switch {
+	case status == fiber.StatusNotFound:
+		return customerrors.CodeNotFound
+	case status == fiber.StatusUnauthorized:
+		return customerrors.CodeUnauthorized
+	case status == fiber.StatusForbidden:
+		return customerrors.CodeForbidden
+	case status >= fiber.StatusInternalServerError:
+		return customerrors.CodeInternal
+	default:
+		return customerrors.CodeInvalidInput
+	}
+}
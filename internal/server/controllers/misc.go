@@ -1,7 +1,10 @@
 package controllers
 
 import (
+	"encoding/json"
 	"fmt"
+	"spotigram/internal/customerrors"
+	"spotigram/internal/utility"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -14,12 +17,19 @@ func AboutHandler(ctx *fiber.Ctx) error {
 
 // A handler to send not found message.
 func NotFoundHandler(ctx *fiber.Ctx) error {
-	ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{
-		"status": "fail",
-		"message": fmt.Sprintf(
+	return &customerrors.ErrNotFound{
+		Message: fmt.Sprintf(
 			"path \"%v\" with method \"%v\" does not exist on this server",
 			ctx.Path(),
 			ctx.Method()),
-	})
-	return nil
+	}
+}
+
+// Decodes the input of a request into v, from the query string
+// of GET requests that have one and from the JSON body otherwise.
+func parseInput(ctx *fiber.Ctx, v any) error {
+	if query := ctx.Queries(); ctx.Method() == fiber.MethodGet && len(query) > 0 {
+		return utility.DecodeQuery(query, v)
+	}
+	return json.Unmarshal(ctx.Body(), v)
 }
@@ -1,210 +1,189 @@
 package controllers
 
 import (
-	"encoding/json"
+	"fmt"
 	"spotigram/internal/customerrors"
 	"spotigram/internal/service/models"
 	"spotigram/internal/service/usecases"
+	"spotigram/internal/utility"
+	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 )
 
-// A handler to send current user info.
-func SongsHandler(ctx *fiber.Ctx) error {
-	input := models.GetSongsInput{}
-	err := json.Unmarshal((ctx.Body()), &input)
+func SongPictureHandler(ctx *fiber.Ctx) error {
+	input := models.GetSongPictureInput{}
+	err := parseInput(ctx, &input)
 	if err != nil {
-		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"status": "fail", "message": err.Error()})
+		return &customerrors.ErrInvalidInput{Message: err.Error()}
 	}
-	songs, err := usecases.GetSongs(input)
+	input.UserId, _ = ctx.Locals("user_uuid").(string)
+	input.UserRole, _ = ctx.Locals("user_role").(string)
+
+	pic, err := usecases.GetSongPicture(input)
 	if err != nil {
-		if errInternal, ok := err.(*customerrors.ErrInternal); ok {
-			return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"status": "fail", "message": errInternal.Error()})
-		} else if newErr, ok := err.(*customerrors.ErrNotFound); ok {
-			return ctx.Status(fiber.StatusNoContent).JSON(fiber.Map{
-				"status": "fail", "message": newErr.Error()})
-		} else if newErr, ok := err.(*customerrors.ErrInvalidInput); ok {
-			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"status": "fail", "message": newErr.Error()})
-		}
-	}
-
-	return ctx.Status(fiber.StatusOK).JSON(songs)
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).Send(pic)
 }
 
-func SongInfoHandler(ctx *fiber.Ctx) error {
-	input := models.GetSongInfoInput{}
-	err := json.Unmarshal(ctx.Body(), &input)
+func UploadSongHandler(ctx *fiber.Ctx) error {
+	input := models.AddSongInput{
+		UserId: ctx.Locals("user_uuid").(string),
+		// The body is spooled into the blob store before the handler
+		// returns, fiber reuses it afterwards
+		File: ctx.Body(),
+	}
+	// Metadata overrides come in the query string
+	if err := utility.DecodeQuery(ctx.Queries(), &input); err != nil {
+		return &customerrors.ErrInvalidInput{Message: err.Error()}
+	}
+	if name := ctx.Params("songname"); name != "" {
+		input.Name = name
+	}
+	input.UserRole, _ = ctx.Locals("user_role").(string)
+	job, err := usecases.AddSong(input)
 	if err != nil {
-		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"status": "fail", "message": err.Error()})
+		return err
 	}
 
-	song, err := usecases.GetSongInfo(input)
-	if err != nil {
-		if errInternal, ok := err.(*customerrors.ErrInternal); ok {
-			return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"status": "fail", "message": errInternal.Error()})
-		} else if newErr, ok := err.(*customerrors.ErrNotFound); ok {
-			return ctx.Status(fiber.StatusNoContent).JSON(fiber.Map{
-				"status": "fail", "message": newErr.Error()})
-		} else if newErr, ok := err.(*customerrors.ErrInvalidInput); ok {
-			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"status": "fail", "message": newErr.Error()})
-		}
-	}
-
-	return ctx.Status(fiber.StatusOK).JSON(song)
+	return ctx.Status(fiber.StatusAccepted).JSON(fiber.Map{
+		"status": "ok", "job_id": job.Id})
 }
 
-func SongPictureHandler(ctx *fiber.Ctx) error {
-	input := models.GetSongPictureInput{}
-	err := json.Unmarshal((ctx.Body()), &input)
+// A handler to send the waveform peaks of a song, raw signed bytes
+// by default or min/max pairs with the json format.
+func SongWaveformHandler(ctx *fiber.Ctx) error {
+	input := models.GetSongWaveformInput{}
+	err := parseInput(ctx, &input)
 	if err != nil {
-		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"status": "fail", "message": err.Error()})
+		return &customerrors.ErrInvalidInput{Message: err.Error()}
 	}
+	input.UserId, _ = ctx.Locals("user_uuid").(string)
+	input.UserRole, _ = ctx.Locals("user_role").(string)
 
-	pic, err := usecases.GetSongPicture(input)
+	waveform, err := usecases.GetSongWaveform(input)
 	if err != nil {
-		if errInternal, ok := err.(*customerrors.ErrInternal); ok {
-			return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"status": "fail", "message": errInternal.Error()})
-		} else if _, ok := err.(*customerrors.ErrNotFound); ok {
-			return ctx.Status(fiber.StatusNoContent).JSON(fiber.Map{
-				"status": "fail", "message": "song not found"})
-		} else if _, ok := err.(*customerrors.ErrInvalidInput); ok {
-			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"status": "fail", "message": "invalid uuid"})
-		}
+		return err
 	}
 
-	return ctx.Status(fiber.StatusOK).Send(pic)
+	if input.Format == "json" {
+		return ctx.Status(fiber.StatusOK).JSON(
+			models.Waveform{Peaks: utility.WaveformPairs(waveform)})
+	}
+	ctx.Set(fiber.HeaderContentType, fiber.MIMEOctetStream)
+	return ctx.Status(fiber.StatusOK).Send(waveform)
 }
 
-func RenameSongHandler(ctx *fiber.Ctx) error {
-	input := models.UpdateSongNameInput{
-		UserId: ctx.Locals("user_uuid").(string),
+// A handler to stream the original file of a song,
+// supports single byte ranges and conditional requests by entity tag.
+func DownloadSongHandler(ctx *fiber.Ctx) error {
+	input := models.GetSongFileInput{}
+	err := parseInput(ctx, &input)
+	if err != nil {
+		return &customerrors.ErrInvalidInput{Message: err.Error()}
 	}
-	err := json.Unmarshal((ctx.Body()), &input)
+	input.UserId, _ = ctx.Locals("user_uuid").(string)
+	input.UserRole, _ = ctx.Locals("user_role").(string)
+	info, err := usecases.GetSongFileInfo(input)
 	if err != nil {
-		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"status": "fail", "message": err.Error()})
+		return err
 	}
 
-	err = usecases.UpdateSongName(input)
-	if err != nil {
-		if errInternal, ok := err.(*customerrors.ErrInternal); ok {
-			return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"status": "fail", "message": errInternal.Error()})
-		} else if _, ok := err.(*customerrors.ErrNotFound); ok {
-			return ctx.Status(fiber.StatusNoContent).JSON(fiber.Map{
-				"status": "fail", "message": "song not found"})
-		} else if _, ok := err.(*customerrors.ErrInvalidInput); ok {
-			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"status": "fail", "message": "invalid uuid"})
-		}
-	}
-
-	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"status": "ok"})
-}
+	ctx.Set(fiber.HeaderAcceptRanges, "bytes")
+	ctx.Set(fiber.HeaderETag, info.ETag)
+	if match := ctx.Get(fiber.HeaderIfNoneMatch); match != "" &&
+		utility.MatchETag(match, info.ETag) {
+		return ctx.SendStatus(fiber.StatusNotModified)
+	}
 
-func UploadSongHandler(ctx *fiber.Ctx) error {
-	input := models.AddSongInput{
-		UserId: ctx.Locals("user_uuid").(string),
-		Name:   ctx.Params("songname"),
-		File:   ctx.Body(),
+	first, last, partial, err := utility.ParseByteRange(ctx.Get(fiber.HeaderRange), info.Size)
+	if ifRange := ctx.Get(fiber.HeaderIfRange); ifRange != "" && ifRange != info.ETag {
+		// The client holds another version, it gets the whole file
+		first, last, partial, err = 0, 0, false, nil
 	}
-	err := usecases.AddSong(input)
+	if err == utility.ErrRangeNotSatisfiable {
+		ctx.Set(fiber.HeaderContentRange, fmt.Sprintf("bytes */%d", info.Size))
+		return ctx.SendStatus(fiber.StatusRequestedRangeNotSatisfiable)
+	}
+
+	status := fiber.StatusOK
+	if partial {
+		status = fiber.StatusPartialContent
+		ctx.Set(fiber.HeaderContentRange,
+			fmt.Sprintf("bytes %d-%d/%d", first, last, info.Size))
+	} else {
+		first, last = 0, info.Size-1
+	}
+
+	file, err := usecases.GetSongFile(input, first, last-first+1)
 	if err != nil {
-		if errInternal, ok := err.(*customerrors.ErrInternal); ok {
-			return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"status": "fail", "message": errInternal.Error()})
-		} else if newErr, ok := err.(*customerrors.ErrNotFound); ok {
-			return ctx.Status(fiber.StatusNoContent).JSON(fiber.Map{
-				"status": "fail", "message": newErr.Error()})
-		} else if newErr, ok := err.(*customerrors.ErrInvalidInput); ok {
-			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"status": "fail", "message": newErr.Error()})
-		}
-	}
-
-	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"status": "ok"})
+		return err
+	}
+
+	ctx.Set(fiber.HeaderContentType, info.ContentType)
+	ctx.Set(fiber.HeaderContentDisposition, utility.ContentDisposition(info.Name))
+	return ctx.Status(status).SendStream(file, int(last-first+1))
 }
 
-func DeleteSongHandler(ctx *fiber.Ctx) error {
-	input := models.DeleteSongInput{
-		UserId: ctx.Locals("user_uuid").(string),
+func GetSongChunk(ctx *fiber.Ctx) error {
+	input := models.GetSongChunkInput{
+		FileName: ctx.Params("filename"),
 	}
-	err := json.Unmarshal((ctx.Body()), &input)
+	input.UserId, _ = ctx.Locals("user_uuid").(string)
+	input.UserRole, _ = ctx.Locals("user_role").(string)
+
+	file, err := usecases.GetSongChunk(input)
 	if err != nil {
-		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"status": "fail", "message": err.Error()})
+		return err
 	}
-	err = usecases.DeleteSong(input)
-	if err != nil {
-		if errInternal, ok := err.(*customerrors.ErrInternal); ok {
-			return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"status": "fail", "message": errInternal.Error()})
-		} else if newErr, ok := err.(*customerrors.ErrNotFound); ok {
-			return ctx.Status(fiber.StatusNoContent).JSON(fiber.Map{
-				"status": "fail", "message": newErr.Error()})
-		} else if newErr, ok := err.(*customerrors.ErrInvalidInput); ok {
-			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"status": "fail", "message": newErr.Error()})
-		} else if newErr, ok := err.(*customerrors.ErrUnauthorized); ok {
-			return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"status": "fail", "message": newErr.Error()})
-		}
-	}
-
-	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"status": "ok"})
+
+	return ctx.Status(fiber.StatusOK).Send(file)
 }
 
-func DownloadSongHandler(ctx *fiber.Ctx) error {
-	input := models.GetSongFileInput{}
-	err := json.Unmarshal((ctx.Body()), &input)
+// A handler to send the playlist of a song with signed urls,
+// for players which cannot send the access token cookie.
+func SongStreamUrlHandler(ctx *fiber.Ctx) error {
+	input := models.GetSongStreamUrlInput{}
+	err := parseInput(ctx, &input)
 	if err != nil {
-		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"status": "fail", "message": err.Error()})
+		return &customerrors.ErrInvalidInput{Message: err.Error()}
 	}
-	file, err := usecases.GetSongFile(input)
+	input.UserId = ctx.Locals("user_uuid").(string)
+	input.UserRole, _ = ctx.Locals("user_role").(string)
+
+	playlist, err := usecases.GetSongStreamUrl(input)
 	if err != nil {
-		if errInternal, ok := err.(*customerrors.ErrInternal); ok {
-			return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"status": "fail", "message": errInternal.Error()})
-		} else if newErr, ok := err.(*customerrors.ErrNotFound); ok {
-			return ctx.Status(fiber.StatusNoContent).JSON(fiber.Map{
-				"status": "fail", "message": newErr.Error()})
-		} else if newErr, ok := err.(*customerrors.ErrInvalidInput); ok {
-			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"status": "fail", "message": newErr.Error()})
-		}
+		return err
 	}
 
-	return ctx.Status(fiber.StatusOK).Send(file)
+	ctx.Set(fiber.HeaderContentType, "application/vnd.apple.mpegurl")
+	ctx.Set(fiber.HeaderCacheControl, "private, no-store")
+	return ctx.Status(fiber.StatusOK).Send(playlist)
 }
 
-func GetSongChunk(ctx *fiber.Ctx) error {
-	input := models.GetSongChunkInput{
-		FileName: ctx.Params("filename"),
+// A handler to send a playlist or a chunk of a song by a signed url,
+// responses may only be cached by the player until the url expires.
+func SignedSongChunkHandler(ctx *fiber.Ctx) error {
+	input := models.GetSignedSongChunkInput{}
+	if err := utility.DecodeQuery(ctx.Queries(), &input); err != nil {
+		return &customerrors.ErrInvalidInput{Message: err.Error()}
 	}
+	input.FileName = ctx.Params("filename")
 
-	file, err := usecases.GetSongChunk(input)
+	file, err := usecases.GetSignedSongChunk(input)
 	if err != nil {
-		if errInternal, ok := err.(*customerrors.ErrInternal); ok {
-			return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"status": "fail", "message": errInternal.Error()})
-		} else if newErr, ok := err.(*customerrors.ErrNotFound); ok {
-			return ctx.Status(fiber.StatusNoContent).JSON(fiber.Map{
-				"status": "fail", "message": newErr.Error()})
-		} else if newErr, ok := err.(*customerrors.ErrInvalidInput); ok {
-			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"status": "fail", "message": newErr.Error()})
-		}
+		return err
 	}
 
+	if strings.HasSuffix(input.FileName, ".m3u8") {
+		ctx.Set(fiber.HeaderContentType, "application/vnd.apple.mpegurl")
+	} else {
+		ctx.Set(fiber.HeaderContentType, "video/mp2t")
+	}
+	maxAge := input.Expires - time.Now().Unix()
+	ctx.Set(fiber.HeaderCacheControl, fmt.Sprintf("private, max-age=%d", maxAge))
 	return ctx.Status(fiber.StatusOK).Send(file)
 }
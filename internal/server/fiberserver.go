@@ -4,10 +4,13 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"spotigram/internal/server/actions"
 	"spotigram/internal/server/config"
 	"spotigram/internal/server/controllers"
 	"spotigram/internal/server/middleware"
 	ws "spotigram/internal/server/websocket"
+	"spotigram/internal/service/models"
+	"spotigram/internal/service/usecases"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/logger"
@@ -34,6 +37,15 @@ func (s *FiberServer) Start() {
 		Format: "[${ip}]:${port} ${status} - ${method} ${path}\n",
 	}))
 
+	// Use cases shared by the REST routes and the websocket protocol
+	actions.RegisterUsecases()
+	ws.RegisterActions()
+	actions.SetNotifier(ws.SendNotification)
+	actions.SetDisconnector(ws.DisconnectUser)
+	usecases.SetUploadJobListener(func(job models.UploadJob, song *models.Song) {
+		actions.Dispatch(actions.UploadStatus(job, song))
+	})
+
 	s.app.Get("/about", controllers.AboutHandler)
 
 	auth := s.app.Group("/auth")
@@ -43,46 +55,113 @@ func (s *FiberServer) Start() {
 	auth.Post("/login", controllers.SignInHandler)
 
 	me := s.app.Group("/me")
-	me.Get("/friends", middleware.DeserializeTokenHandler, controllers.FriendsHandler)
-	me.Get("/friend-requests-sent", middleware.DeserializeTokenHandler, controllers.FriendRequestSentHandler)
-	me.Get("/friend-requests-received", middleware.DeserializeTokenHandler, controllers.FriendRequestReceivedHandler)
-	me.Get("/info", middleware.DeserializeTokenHandler, controllers.MyInfoHandler)
-	me.Get("/public-key", middleware.DeserializeTokenHandler, controllers.MyPublicKeyHandler)
+	me.Get("/friends", middleware.DeserializeTokenHandler, actions.Handler("get-friends"))
+	me.Get("/friend-suggestions", middleware.DeserializeTokenHandler, actions.Handler("get-friend-suggestions"))
+	me.Get("/friend-requests-sent", middleware.DeserializeTokenHandler, actions.Handler("get-friend-requests-sent"))
+	me.Get("/friend-requests-received", middleware.DeserializeTokenHandler, actions.Handler("get-friend-requests-received"))
+	me.Get("/info", middleware.DeserializeTokenHandler, actions.Handler("get-my-info"))
+	me.Get("/public-key", middleware.DeserializeTokenHandler, actions.Handler("get-my-public-key"))
 	me.Get("/picture", middleware.DeserializeTokenHandler, controllers.MyPictureHandler)
-	me.Post("/change-name", middleware.DeserializeTokenHandler, controllers.ChangeNameHandler)
-	me.Post("/change-password", middleware.DeserializeTokenHandler, controllers.ChangePasswordHandler)
-	me.Post("/change-public-key", middleware.DeserializeTokenHandler, controllers.ChangePublicKeyHandler)
+	me.Post("/change-name", middleware.DeserializeTokenHandler, actions.Handler("change-name"))
+	me.Post("/change-password", middleware.DeserializeTokenHandler, actions.Handler("change-password"))
+	me.Post("/change-public-key", middleware.DeserializeTokenHandler, actions.Handler("change-public-key"))
 	me.Post("/change-picture", middleware.DeserializeTokenHandler, controllers.ChangePictureHandler)
+	me.Post("/change-handle", middleware.DeserializeTokenHandler, actions.Handler("change-handle"))
+	me.Post("/update-profile", middleware.DeserializeTokenHandler, actions.Handler("update-profile"))
+	me.Get("/banner", middleware.DeserializeTokenHandler, controllers.MyBannerHandler)
+	me.Post("/change-banner", middleware.DeserializeTokenHandler, controllers.ChangeBannerHandler)
+	me.Get("/blocked", middleware.DeserializeTokenHandler, actions.Handler("get-blocked-users"))
+	me.Post("/block", middleware.DeserializeTokenHandler, actions.Handler("block-user"))
+	me.Post("/unblock", middleware.DeserializeTokenHandler, actions.Handler("unblock-user"))
+	me.Get("/privacy", middleware.DeserializeTokenHandler, actions.Handler("get-privacy"))
+	me.Post("/update-privacy", middleware.DeserializeTokenHandler, actions.Handler("update-privacy"))
+	me.Get("/activity", middleware.DeserializeTokenHandler, actions.Handler("get-my-activity"))
+	me.Get("/followers", middleware.DeserializeTokenHandler, actions.Handler("get-my-followers"))
+	me.Get("/following", middleware.DeserializeTokenHandler, actions.Handler("get-my-following"))
+	me.Get("/follow-counts", middleware.DeserializeTokenHandler, actions.Handler("get-my-follow-counts"))
+	me.Post("/follow", middleware.DeserializeTokenHandler, actions.Handler("follow"))
+	me.Post("/unfollow", middleware.DeserializeTokenHandler, actions.Handler("unfollow"))
+	me.Get("/feed", middleware.DeserializeTokenHandler, actions.Handler("get-feed"))
+	me.Delete("/delete-account", middleware.DeserializeTokenHandler, actions.Handler("delete-account"))
+	me.Get("/export", middleware.DeserializeTokenHandler, controllers.ExportAccountHandler)
 
 	user := s.app.Group("/user")
-	user.Get("/all", middleware.DeserializeTokenHandler, controllers.UsersHandler)
-	user.Get("/info", middleware.DeserializeTokenHandler, controllers.UserInfoHandler)
-	user.Get("/public-key", middleware.DeserializeTokenHandler, controllers.UserPublicKeyHandler)
+	user.Get("/all", middleware.DeserializeTokenHandler, actions.Handler("get-users"))
+	user.Get("/info", middleware.DeserializeTokenHandler, actions.Handler("get-user-info"))
+	user.Get("/public-key", middleware.DeserializeTokenHandler, actions.Handler("get-user-public-key"))
 	user.Get("/picture", middleware.DeserializeTokenHandler, controllers.UserPictureHandler)
+	user.Get("/banner", middleware.DeserializeTokenHandler, controllers.UserBannerHandler)
+	user.Get("/activity", middleware.DeserializeTokenHandler, actions.Handler("get-user-activity"))
+	user.Get("/followers", middleware.DeserializeTokenHandler, actions.Handler("get-user-followers"))
+	user.Get("/following", middleware.DeserializeTokenHandler, actions.Handler("get-user-following"))
+	user.Get("/follow-counts", middleware.DeserializeTokenHandler, actions.Handler("get-user-follow-counts"))
 
 	playlist := s.app.Group("/playlist")
-	playlist.Get("/all", middleware.DeserializeTokenHandler, controllers.PlaylistsHandler)
-	playlist.Get("/songs", middleware.DeserializeTokenHandler, controllers.PlaylistSongsHandler)
-	playlist.Post("/create", middleware.DeserializeTokenHandler, controllers.AddPlaylistHandler)
-	playlist.Post("/rename", middleware.DeserializeTokenHandler, controllers.RenamePlaylistHandler)
-	playlist.Delete("/delete", middleware.DeserializeTokenHandler, controllers.DeletePlaylistHandler)
-	playlist.Post("/add-song", middleware.DeserializeTokenHandler, controllers.AddPlaylistSongHandler)
-	playlist.Delete("/delete-song", middleware.DeserializeTokenHandler, controllers.DeletePlaylistSongHandler)
+	playlist.Get("/all", middleware.DeserializeTokenHandler, actions.Handler("get-playlists"))
+	playlist.Get("/songs", middleware.DeserializeTokenHandler, actions.Handler("get-playlist-songs"))
+	playlist.Post("/create", middleware.DeserializeTokenHandler, actions.Handler("create-playlist"))
+	playlist.Post("/rename", middleware.DeserializeTokenHandler, actions.Handler("rename-playlist"))
+	playlist.Delete("/delete", middleware.DeserializeTokenHandler, actions.Handler("delete-playlist"))
+	playlist.Post("/add-song", middleware.DeserializeTokenHandler, actions.Handler("add-playlist-song"))
+	playlist.Delete("/delete-song", middleware.DeserializeTokenHandler, actions.Handler("delete-playlist-song"))
 
 	song := s.app.Group("/song")
-	song.Get("/all", middleware.DeserializeTokenHandler, controllers.SongsHandler)
-	song.Get("/info", middleware.DeserializeTokenHandler, controllers.SongInfoHandler)
-	song.Post("/rename", middleware.DeserializeTokenHandler, controllers.RenameSongHandler)
+	song.Get("/all", middleware.DeserializeTokenHandler, actions.Handler("get-songs"))
+	song.Get("/info", middleware.DeserializeTokenHandler, actions.Handler("get-song-info"))
+	song.Get("/upload-status", middleware.DeserializeTokenHandler, actions.Handler("get-upload-status"))
+	song.Post("/rename", middleware.DeserializeTokenHandler, actions.Handler("rename-song"))
 	song.Get("/picture", middleware.DeserializeTokenHandler, controllers.SongPictureHandler)
+	song.Get("/waveform", middleware.DeserializeTokenHandler, controllers.SongWaveformHandler)
 	song.Get("/download", middleware.DeserializeTokenHandler, controllers.DownloadSongHandler)
-	song.Delete("/delete", middleware.DeserializeTokenHandler, controllers.DeleteSongHandler)
+	song.Delete("/delete", middleware.DeserializeTokenHandler, actions.Handler("delete-song"))
 	song.Get("/stream/:filename", middleware.DeserializeTokenHandler, controllers.GetSongChunk)
-	song.Post("/upload/:songname", middleware.DeserializeTokenHandler, controllers.UploadSongHandler)
+	song.Get("/stream-url", middleware.DeserializeTokenHandler, controllers.SongStreamUrlHandler)
+	song.Get("/signed/:filename", controllers.SignedSongChunkHandler)
+	song.Post("/upload/:songname", middleware.DeserializeTokenHandler,
+		middleware.RequireRoles(models.RoleArtist, models.RoleAdmin), controllers.UploadSongHandler)
+	song.Post("/upload", middleware.DeserializeTokenHandler,
+		middleware.RequireRoles(models.RoleArtist, models.RoleAdmin), controllers.UploadSongHandler)
+
+	album := s.app.Group("/album")
+	album.Get("/all", middleware.DeserializeTokenHandler, actions.Handler("get-albums"))
+	album.Get("/info", middleware.DeserializeTokenHandler, actions.Handler("get-album-info"))
+	album.Get("/cover", middleware.DeserializeTokenHandler, controllers.AlbumCoverHandler)
+	album.Post("/create", middleware.DeserializeTokenHandler, actions.Handler("create-album"))
+	album.Post("/change-cover", middleware.DeserializeTokenHandler, controllers.ChangeAlbumCoverHandler)
+	album.Post("/upload", middleware.DeserializeTokenHandler,
+		middleware.RequireRoles(models.RoleArtist, models.RoleAdmin), controllers.UploadAlbumSongsHandler)
+	album.Delete("/delete", middleware.DeserializeTokenHandler, actions.Handler("delete-album"))
 
 	chat := s.app.Group("/chat")
-	chat.Get("/messages", middleware.DeserializeTokenHandler, controllers.ChatMessagesHandler)
-	chat.Get("/unread-messages", middleware.DeserializeTokenHandler, controllers.ChatUnreadMessagesHandler)
+	chat.Get("/messages", middleware.DeserializeTokenHandler, actions.Handler("get-messages"))
+	chat.Get("/unread-messages", middleware.DeserializeTokenHandler, actions.Handler("get-unread-messages"))
+	chat.Post("/mute", middleware.DeserializeTokenHandler, actions.Handler("mute-chat"))
+	chat.Post("/unmute", middleware.DeserializeTokenHandler, actions.Handler("unmute-chat"))
+
+	moderatorOnly := middleware.RequireRoles(models.RoleModerator, models.RoleAdmin)
+	moderation := s.app.Group("/moderation")
+	moderation.Post("/report", middleware.DeserializeTokenHandler, actions.Handler("report"))
+	moderation.Get("/reports", middleware.DeserializeTokenHandler, moderatorOnly, actions.Handler("get-reports"))
+	moderation.Post("/resolve", middleware.DeserializeTokenHandler, moderatorOnly, actions.Handler("resolve-report"))
+	moderation.Post("/hide-song", middleware.DeserializeTokenHandler, moderatorOnly, actions.Handler("hide-song"))
+	moderation.Post("/unhide-song", middleware.DeserializeTokenHandler, moderatorOnly, actions.Handler("unhide-song"))
+	moderation.Post("/suspend-user", middleware.DeserializeTokenHandler, moderatorOnly, actions.Handler("suspend-user"))
+	moderation.Post("/unsuspend-user", middleware.DeserializeTokenHandler, moderatorOnly, actions.Handler("unsuspend-user"))
+	moderation.Get("/audit-log", middleware.DeserializeTokenHandler, moderatorOnly, actions.Handler("get-audit-log"))
+
+	adminOnly := middleware.RequireRoles(models.RoleAdmin)
+	admin := s.app.Group("/admin")
+	admin.Post("/set-role", middleware.DeserializeTokenHandler, adminOnly, actions.Handler("set-role"))
+	admin.Get("/users", middleware.DeserializeTokenHandler, adminOnly, actions.Handler("admin-get-users"))
+	admin.Get("/user", middleware.DeserializeTokenHandler, adminOnly, actions.Handler("admin-get-user"))
+	admin.Post("/verify", middleware.DeserializeTokenHandler, adminOnly, actions.Handler("admin-verify-user"))
+	admin.Post("/suspend", middleware.DeserializeTokenHandler, adminOnly, actions.Handler("suspend-user"))
+	admin.Post("/unsuspend", middleware.DeserializeTokenHandler, adminOnly, actions.Handler("unsuspend-user"))
+	admin.Post("/reset-password", middleware.DeserializeTokenHandler, adminOnly, actions.Handler("admin-reset-password"))
+	admin.Post("/revoke-sessions", middleware.DeserializeTokenHandler, adminOnly, actions.Handler("admin-revoke-sessions"))
+	admin.Delete("/delete", middleware.DeserializeTokenHandler, adminOnly, actions.Handler("admin-delete-user"))
 
+	ws.SetupTimings()
 	go ws.RunChatHub()
 	s.app.Get("/connect", middleware.DeserializeTokenHandler, ws.WebsocketChatUpgradeHandler,
 		websocket.New(ws.WebsocketChatLoop))
@@ -14,23 +14,17 @@ func DeserializeTokenHandler(ctx *fiber.Ctx) error {
 	input := models.DeserializeTokenInput{
 		AccessToken: ctx.Cookies("access_token"),
 	}
-	userUuid, accessTokenUuid, err :=
+	userUuid, accessTokenUuid, role, err :=
 		usecases.DeserializeToken(input, config.Cfg)
-	if err != nil {
-		if errInternal, ok := err.(*customerrors.ErrInternal); ok {
-			return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"status": "fail", "message": errInternal.Error()})
-		} else if errUnauthorized, ok := err.(*customerrors.ErrUnauthorized); ok {
-			return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"status": "fail", "message": errUnauthorized.Error()})
-		} else if errNotFound, ok := err.(*customerrors.ErrNotFound); ok {
-			return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"status": "fail", "message": errNotFound.Error()})
-		}
+	if customerrors.IsNotFound(err) {
+		return &customerrors.ErrUnauthorized{Message: err.Error()}
+	} else if err != nil {
+		return err
 	}
 
 	ctx.Locals("user_uuid", userUuid)
 	ctx.Locals("access_token_uuid", accessTokenUuid)
+	ctx.Locals("user_role", role)
 
 	return ctx.Next()
 }
@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"spotigram/internal/customerrors"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Returns a handler letting through only users with one of the roles.
+// Expects DeserializeTokenHandler beforehand.
+func RequireRoles(roles ...string) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		role, _ := ctx.Locals("user_role").(string)
+		for _, r := range roles {
+			if role == r {
+				return ctx.Next()
+			}
+		}
+
+		return &customerrors.ErrForbidden{Message: "insufficient role"}
+	}
+}
@@ -1,6 +1,7 @@
 package server
 
 import (
+	"spotigram/internal/server/controllers"
 	serviceAbstractions "spotigram/internal/service/abstractions"
 
 	"github.com/gofiber/fiber/v2"
@@ -11,7 +12,8 @@ import (
 func NewFiberServer(requestBodyLimit int) serviceAbstractions.Server {
 	return &FiberServer{
 		app: fiber.New(fiber.Config{
-			BodyLimit: requestBodyLimit,
+			BodyLimit:    requestBodyLimit,
+			ErrorHandler: controllers.ErrorHandler,
 		}),
 	}
 }
@@ -2,11 +2,14 @@ package websocket
 
 import (
 	"encoding/json"
+	"log"
+	"strconv"
 	"time"
 
+	"spotigram/internal/customerrors"
+	"spotigram/internal/server/actions"
 	"spotigram/internal/service/models"
 	"spotigram/internal/service/usecases"
-	"spotigram/internal/utility"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/websocket/v2"
@@ -19,18 +22,24 @@ type responceContext struct {
 }
 
 type echoResponce struct {
-	Status  string `json:"status"`
-	Content string `json:"content"`
+	Status    string                    `json:"status"`
+	RequestId string                    `json:"request_id,omitempty"`
+	Code      string                    `json:"code,omitempty"`
+	Content   string                    `json:"content"`
+	Details   []customerrors.FieldError `json:"details,omitempty"`
+	Result    json.RawMessage           `json:"result,omitempty"`
 }
 
 type notificationResponce struct {
-	Status  string          `json:"status"`
-	Content json.RawMessage `json:"content"`
+	Status    string          `json:"status"`
+	RequestId string          `json:"request_id,omitempty"`
+	Content   json.RawMessage `json:"content"`
 }
 
 type statusCheckContext struct {
-	SenderId string
-	UserId   string
+	SenderId  string
+	RequestId string
+	UserId    string
 }
 
 type statusUpdateContext struct {
@@ -55,629 +64,261 @@ type chatStatus struct {
 	Status int
 }
 
-var (
-	connections  = make(map[string]*websocket.Conn)
-	chatStatuses = make(map[string]*chatStatus)
-	register     = make(chan *websocket.Conn)
-	broadcast    = make(chan responceContext)
-	unregister   = make(chan *websocket.Conn)
-	statusCheck  = make(chan statusCheckContext)
-	statusUpdate = make(chan statusUpdateContext)
-)
-
-func RunChatHub() {
-	ticker := time.NewTicker(3 * time.Minute)
-	for {
-		select {
-		// Connect
-		case connection := <-register:
-			if _, ok := connections[connection.Locals("user_uuid").(string)]; ok {
-				connections[connection.Locals("user_uuid").(string)].
-					WriteMessage(websocket.CloseMessage, []byte{})
-			}
-			connections[connection.Locals("user_uuid").(string)] = connection
-
-		// Send message
-		case ctx := <-broadcast:
-			var connection *websocket.Conn
-			var ok bool
-			if connection, ok = connections[ctx.ReceiverUUID]; !ok {
-				continue
-			}
-			if err := connection.WriteMessage(ctx.MessageType, []byte(ctx.Message)); err != nil {
-
-				unregister <- connection
-				connection.WriteMessage(websocket.CloseMessage, []byte{})
-				connection.Close()
-			}
-
-		// Update status
-		case ctx := <-statusUpdate:
-			var ok bool
-			_, ok = connections[ctx.SenderId]
-			if !ok {
-				continue
-			}
-			prevChatStatus, ok := chatStatuses[ctx.SenderId]
-			if !ok {
-				prevChatStatus = nil
-			}
-			conU, ok := connections[ctx.UserId]
-			if !ok {
-				conU = nil
-			}
-
-			if ctx.Status != -1 {
-				chatStatuses[ctx.SenderId] = &chatStatus{
-					UserId: ctx.UserId,
-					Status: ctx.Status,
-				}
-			} else {
-				delete(chatStatuses, ctx.SenderId)
-			}
-
-			if conU != nil {
-				var content []byte
-				if ctx.Status == -1 {
-					content, _ = json.Marshal(statusCheckResponse{
-						UserId: ctx.SenderId,
-						Status: "offline",
-					})
-				} else {
-					content, _ = json.Marshal(statusCheckResponse{
-						UserId: ctx.SenderId,
-						Status: STATUSES[ctx.Status],
-					})
-				}
-
-				message, _ := json.Marshal(notificationResponce{
-					Status:  "status-update",
-					Content: content,
-				})
-
-				ctx2 := responceContext{
-					MessageType:  websocket.TextMessage,
-					Message:      string(message),
-					ReceiverUUID: ctx.UserId,
-				}
-
-				if err := conU.WriteMessage(ctx2.MessageType, []byte(ctx2.Message)); err != nil {
-					//bruh
-				}
-			}
-
-			if prevChatStatus != nil && prevChatStatus.UserId != ctx.UserId {
-				conP, ok := connections[prevChatStatus.UserId]
-				if !ok {
-					continue
-				}
-
-				var content []byte
-				content, _ = json.Marshal(statusCheckResponse{
-					UserId: ctx.SenderId,
-					Status: "offline",
-				})
-
-				message, _ := json.Marshal(notificationResponce{
-					Status:  "status-update",
-					Content: content,
-				})
-
-				ctx2 := responceContext{
-					MessageType:  websocket.TextMessage,
-					Message:      string(message),
-					ReceiverUUID: ctx.UserId,
-				}
-
-				if err := conP.WriteMessage(ctx2.MessageType, []byte(ctx2.Message)); err != nil {
-					//bruh
-				}
-			}
-
-		// Check status
-		case ctx := <-statusCheck:
-			var ok bool
-			con, ok := connections[ctx.SenderId]
-			if !ok {
-				continue
-			}
-			_, ok = connections[ctx.UserId]
-
-			var content []byte
-			if ok {
-				chatStatus, ok := chatStatuses[ctx.UserId]
-				if !ok || chatStatus.UserId != ctx.SenderId {
-					content, _ = json.Marshal(statusCheckResponse{
-						UserId: ctx.UserId,
-						Status: "offline",
-					})
-				} else {
-					content, _ = json.Marshal(statusCheckResponse{
-						UserId: ctx.UserId,
-						Status: STATUSES[chatStatus.Status],
-					})
-				}
-			} else {
-				content, _ = json.Marshal(statusCheckResponse{
-					UserId: ctx.UserId,
-					Status: "offline",
-				})
-			}
+// Checks the upgrade request and negotiates the protocol version,
+// passed as the "version" query parameter.
+func WebsocketChatUpgradeHandler(c *fiber.Ctx) error {
+	if !websocket.IsWebSocketUpgrade(c) {
+		return fiber.ErrUpgradeRequired
+	}
 
-			message, _ := json.Marshal(notificationResponce{
-				Status:  "status-update",
-				Content: content,
-			})
+	version := legacyProtocolVersion
+	if query := c.Query("version"); query != "" {
+		requested, err := strconv.Atoi(query)
+		if err != nil || requested < legacyProtocolVersion {
+			return &customerrors.ErrInvalidInput{
+				Message: "unsupported protocol \"version\""}
+		}
+		version = requested
+		if version > latestProtocolVersion {
+			version = latestProtocolVersion
+		}
+	}
 
-			ctx2 := responceContext{
-				MessageType:  websocket.TextMessage,
-				Message:      string(message),
-				ReceiverUUID: ctx.SenderId,
-			}
+	c.Locals("allowed", true)
+	c.Locals("protocol_version", version)
+	return c.Next()
+}
 
-			if err := con.WriteMessage(ctx2.MessageType, []byte(ctx2.Message)); err != nil {
-				unregister <- con
-				con.WriteMessage(websocket.CloseMessage, []byte{})
-				con.Close()
-			}
+// Context of a single client request, used to reply to it.
+type requestContext struct {
+	UserId    string
+	Role      string
+	RequestId string
+	Version   int
+}
 
-		// Disconnect
-		case connection := <-unregister:
-			delete(connections, connection.Locals("user_uuid").(string))
-
-			chatStatus, ok := chatStatuses[connection.Locals("user_uuid").(string)]
-			if ok {
-				delete(chatStatuses, connection.Locals("user_uuid").(string))
-				content, _ := json.Marshal(statusCheckResponse{
-					UserId: connection.Locals("user_uuid").(string),
-					Status: "offline",
-				})
-				message, _ := json.Marshal(notificationResponce{
-					Status:  "status-update",
-					Content: content,
-				})
-				ctx2 := responceContext{
-					MessageType:  websocket.TextMessage,
-					Message:      string(message),
-					ReceiverUUID: chatStatus.UserId,
-				}
-				if err := connection.WriteMessage(ctx2.MessageType, []byte(ctx2.Message)); err != nil {
-					continue
-				}
-			}
+// Sends a fail echo with an error code to the requester.
+// Legacy clients only receive the message and the request id.
+func (r requestContext) sendError(code string, message string) {
+	r.sendErrorDetails(code, message, nil)
+}
 
-		// Send pings
-		case <-ticker.C:
-			for _, c := range connections {
-				c.WriteMessage(websocket.TextMessage, []byte("ping"))
-			}
-		}
+// Sends a fail echo with an error code and the invalid fields to the requester.
+// Legacy clients only receive the message and the request id.
+func (r requestContext) sendErrorDetails(code string, message string, details []customerrors.FieldError) {
+	response := echoResponce{
+		Status:    "fail",
+		RequestId: r.RequestId,
+		Content:   message,
+	}
+	if r.Version >= latestProtocolVersion {
+		response.Code = code
+		response.Details = details
+	}
+	errorMessage, _ := json.Marshal(response)
+	hub.broadcast <- responceContext{
+		MessageType:  websocket.TextMessage,
+		Message:      string(errorMessage),
+		ReceiverUUID: r.UserId,
 	}
 }
 
-func WebsocketChatUpgradeHandler(c *fiber.Ctx) error {
-	if websocket.IsWebSocketUpgrade(c) {
-		c.Locals("allowed", true)
-		return c.Next()
+// Sends a fail echo for an error returned by a use case,
+// internal errors are logged and hidden from the client.
+func (r requestContext) sendUsecaseError(err error) {
+	code := customerrors.Code(err)
+	if code == customerrors.CodeInternal {
+		log.Printf("websocket request %s of user %s: %v", r.RequestId, r.UserId, err)
 	}
-	return fiber.ErrUpgradeRequired
+	r.sendErrorDetails(code, customerrors.PublicMessage(err), customerrors.Details(err))
 }
 
-func sendError(message string, uuid string) {
-	errorMessage, _ := json.Marshal(echoResponce{
-		Status:  "fail",
-		Content: message,
-	})
-	broadcast <- responceContext{
+// Sends an ok echo to the requester, carrying the action result if any.
+func (r requestContext) sendOk(result json.RawMessage) {
+	response := echoResponce{
+		Status:    "ok",
+		RequestId: r.RequestId,
+		Result:    result,
+	}
+	okMessage, _ := json.Marshal(response)
+	hub.broadcast <- responceContext{
 		MessageType:  websocket.TextMessage,
-		Message:      string(errorMessage),
-		ReceiverUUID: uuid,
+		Message:      string(okMessage),
+		ReceiverUUID: r.UserId,
 	}
 }
 
-func sendOk(uuid string) {
-	errorMessage, _ := json.Marshal(echoResponce{
-		Status: "ok",
-	})
-	broadcast <- responceContext{
+// Sends a notification caused by the request to the requester.
+func (r requestContext) sendNotification(status string, message json.RawMessage) {
+	response := notificationResponce{
+		Status:    status,
+		RequestId: r.RequestId,
+		Content:   message,
+	}
+	notification, _ := json.Marshal(response)
+	hub.broadcast <- responceContext{
 		MessageType:  websocket.TextMessage,
-		Message:      string(errorMessage),
-		ReceiverUUID: uuid,
+		Message:      string(notification),
+		ReceiverUUID: r.UserId,
 	}
 }
 
+// Sends a notification to a user.
 func sendNotification(status string, message json.RawMessage, uuid string) {
-	errorMessage, _ := json.Marshal(notificationResponce{
+	notification, _ := json.Marshal(notificationResponce{
 		Status:  status,
 		Content: message,
 	})
-	broadcast <- responceContext{
+	hub.broadcast <- responceContext{
 		MessageType:  websocket.TextMessage,
-		Message:      string(errorMessage),
+		Message:      string(notification),
 		ReceiverUUID: uuid,
 	}
 }
 
+// Pushes an action notification to its receiver.
+// Meant to be set as the notifier of the actions package.
+func SendNotification(n actions.Notification) {
+	content, _ := json.Marshal(n.Content)
+	sendNotification(n.Status, content, n.ReceiverId)
+}
+
+// Runs a client request through the action router,
+// notifying the affected users and echoing the result.
+func handleRequest(req requestContext, payload models.WebsocketPayload) {
+	if !actions.Exists(payload.Action) {
+		req.sendError(codeInvalidAction, "invalid \"action\"")
+		return
+	}
+
+	result, err := actions.Run(payload.Action,
+		actions.Caller{Id: req.UserId, Role: req.Role, RequestId: req.RequestId}, payload.Content)
+	if err != nil {
+		req.sendUsecaseError(err)
+		return
+	}
+
+	// Notifications for the requester follow the echo.
+	var own []actions.Notification
+	for _, n := range result.Notifications {
+		if n.ReceiverId == req.UserId {
+			own = append(own, n)
+			continue
+		}
+		SendNotification(n)
+	}
+
+	var data json.RawMessage
+	if result.Data != nil {
+		data, _ = json.Marshal(result.Data)
+	}
+	req.sendOk(data)
+
+	for _, n := range own {
+		content, _ := json.Marshal(n.Content)
+		req.sendNotification(n.Status, content)
+	}
+
+	actions.Disconnect(result.Disconnect)
+}
+
 func WebsocketChatLoop(c *websocket.Conn) {
-	register <- c
 	userUUID := c.Locals("user_uuid").(string)
+	role, _ := c.Locals("user_role").(string)
+	version := c.Locals("protocol_version").(int)
+	cl := newClient(userUUID, c, writeWait, pingPeriod)
+	go cl.writePump()
+	hub.register <- cl
+
+	if version >= latestProtocolVersion {
+		content, _ := json.Marshal(fiber.Map{"protocol_version": version})
+		sendNotification("connected", content, userUUID)
+	}
 
 	c.SetReadLimit(256 * 1024)
 
-	var (
-		mt  int
-		msg []byte
-		err error
-	)
+	// A peer that stops answering pings is considered gone,
+	// the read fails and the connection is unregistered.
+	c.SetReadDeadline(time.Now().Add(pongWait))
+	c.SetPongHandler(func(string) error {
+		return c.SetReadDeadline(time.Now().Add(pongWait))
+	})
 
 	for {
-		c.SetReadDeadline(time.Now().Add(5 * time.Minute))
-		mt, msg, err = c.ReadMessage()
+		mt, msg, err := c.ReadMessage()
 		if err != nil {
 			break
 		}
+		c.SetReadDeadline(time.Now().Add(pongWait))
 
-		if mt == websocket.TextMessage && len(msg) < 10 {
-			if string(msg) == "pong" {
-				continue
-			}
+		if mt != websocket.TextMessage {
+			requestContext{UserId: userUUID, Version: version}.
+				sendError(codeInvalidMessageType, "invalid websocket message type")
+			continue
+		}
+
+		// Unmarshal payload
+		var payload models.WebsocketPayload
+		err = json.Unmarshal(msg, &payload)
+		if err != nil {
+			requestContext{UserId: userUUID, Version: version}.
+				sendError(codeInvalidJson, "invalid json")
+			continue
+		}
+		req := requestContext{
+			UserId:    userUUID,
+			Role:      role,
+			RequestId: payload.RequestId,
+			Version:   version,
+		}
+		if payload.Action == "" || payload.Content == nil {
+			req.sendError(codeInvalidPayload, "invalid \"action\" or \"content\" field")
+			continue
 		}
 
-		if mt == websocket.TextMessage {
-			// Unmarshal payload
-			var payload models.WebsocketPayload
-			err := json.Unmarshal(msg, &payload)
-			if err != nil {
-				sendError("invalid json", userUUID)
-				continue
+		handleRequest(req, payload)
+	}
+
+	// The connection must outlive the write pump,
+	// it is released as soon as this handler returns.
+	hub.unregister <- cl
+	<-cl.done
+}
+
+// Registers the actions handled by the chat hub.
+func RegisterActions() {
+	actions.Register(actions.Spec[models.CheckStatusInput, any]{
+		Name: "check-status",
+		Bind: func(in *models.CheckStatusInput, c actions.Caller) {
+			in.SenderId = c.Id
+			in.RequestId = c.RequestId
+		},
+		Handle: func(in models.CheckStatusInput) (any, error) {
+			if err := usecases.CheckStatus(in); err != nil {
+				return nil, err
 			}
-			if payload.Action == "" || payload.Content == nil {
-				sendError("invalid \"action\" or \"content\" field", userUUID)
-				continue
+			hub.statusCheck <- statusCheckContext{
+				SenderId:  in.SenderId,
+				RequestId: in.RequestId,
+				UserId:    in.UserId,
 			}
+			return nil, nil
+		},
+	})
 
-			// Action cases
-			switch payload.Action {
-			case "send-friend-request":
-				var input = models.AddFriendRequestInput{}
-				err := json.Unmarshal(payload.Content, &input)
-				if err != nil {
-					sendError("invalid \"content\"", userUUID)
-					continue
-				}
-				if input.RecipientUUID == "" {
-					sendError("invalid \"id\"", userUUID)
-					continue
-				}
-
-				input.SenderUUID = c.Locals("user_uuid").(string)
-
-				err = usecases.AddFriendRequest(input)
-				if err != nil {
-					sendError(err.Error(), userUUID)
-					continue
-				}
-
-				// Notify user
-				notificationMessage, _ := json.Marshal(models.FriendRequest{
-					SenderId:    userUUID,
-					RecipientId: input.RecipientUUID,
-					IsIgnored:   false,
-				})
-				sendNotification(
-					"friend-request-received",
-					notificationMessage,
-					input.RecipientUUID)
-
-				sendOk(userUUID)
-
-			case "delete-friend-request":
-				var input = models.DeleteFriendRequestInput{}
-				err := json.Unmarshal(payload.Content, &input)
-				if err != nil {
-					sendError("invalid \"const\"", userUUID)
-					continue
-				}
-				if input.RecipientUUID == "" {
-					sendError("invalid \"id\"", userUUID)
-					continue
-				}
-
-				input.SenderUUID = c.Locals("user_uuid").(string)
-
-				err = usecases.DeleteFriendRequest(input)
-				if err != nil {
-					sendError(err.Error(), userUUID)
-					continue
-				}
-
-				// Notify user
-				notificationMessage, _ := json.Marshal(models.FriendRequest{
-					SenderId:    userUUID,
-					RecipientId: input.RecipientUUID,
-					IsIgnored:   false,
-				})
-				sendNotification(
-					"friend-request-deleted",
-					notificationMessage,
-					input.RecipientUUID)
-
-				sendOk(userUUID)
-
-			case "update-friend-request":
-				var input = models.UpdateFriendRequestInput{}
-				err := json.Unmarshal(payload.Content, &input)
-				if err != nil {
-					sendError("invalid \"content\"", userUUID)
-					continue
-				}
-				if input.SenderUUID == "" {
-					sendError("invalid \"id\"", userUUID)
-					continue
-				}
-
-				input.RecipientUUID = c.Locals("user_uuid").(string)
-
-				err = usecases.UpdateFriendRequest(input)
-				if err != nil {
-					sendError(err.Error(), userUUID)
-					continue
-				}
-
-				// Notify user
-				notificationMessage, _ := json.Marshal(models.FriendRequest{
-					SenderId:    input.SenderUUID,
-					RecipientId: userUUID,
-					IsIgnored:   input.IsIgnored,
-				})
-				sendNotification(
-					"friend-request-updated",
-					notificationMessage,
-					input.SenderUUID)
-
-				sendOk(userUUID)
-
-			case "accept-friend-request":
-				var input = models.AcceptFriendRequestInput{}
-				err := json.Unmarshal(payload.Content, &input)
-				if err != nil {
-					sendError("invalid \"content\"", userUUID)
-					continue
-				}
-				if input.SenderUUID == "" {
-					sendError("invalid \"id\"", userUUID)
-					continue
-				}
-				input.RecipientUUID = c.Locals("user_uuid").(string)
-
-				newFriend, err := usecases.AcceptFriendRequest(input)
-				if err != nil {
-					sendError(err.Error(), userUUID)
-					continue
-				}
-
-				// Notify user
-				notificationMessage, _ := json.Marshal(newFriend)
-				sendNotification(
-					"friend-request-accepted",
-					notificationMessage,
-					input.SenderUUID)
-
-				sendOk(userUUID)
-
-				sendNotification(
-					"friend-added",
-					notificationMessage,
-					userUUID)
-
-			case "delete-friend":
-				var input = models.DeleteFriendInput{}
-				err := json.Unmarshal(payload.Content, &input)
-				if err != nil {
-					sendError("invalid \"content\"", userUUID)
-					continue
-				}
-				if input.User2UUID == "" {
-					sendError("invalid \"id\"", userUUID)
-					continue
-				}
-
-				input.User1UUID = c.Locals("user_uuid").(string)
-
-				err = usecases.DeleteFriend(input)
-				if err != nil {
-					sendError(err.Error(), userUUID)
-					continue
-				}
-
-				// Notify user
-				notificationMessage, _ := json.Marshal(models.Friend{
-					Id1:    input.User2UUID,
-					Id2:    userUUID,
-					ChatId: "",
-				})
-				sendNotification(
-					"friend-deleted",
-					notificationMessage,
-					input.User2UUID)
-
-				sendOk(userUUID)
-
-			case "send-message":
-				var inputBase = models.SendMessageInput{}
-				err := json.Unmarshal(payload.Content, &inputBase)
-				if err != nil {
-					sendError("invalid \"content\"", userUUID)
-					continue
-				}
-
-				if inputBase.ChatId == "" {
-					sendError("invalid chat \"id\"", userUUID)
-					continue
-				}
-				input := models.Message{
-					UserId:      c.Locals("user_uuid").(string),
-					ChatId:      inputBase.ChatId,
-					Content:     inputBase.Content,
-					IsEncrypted: inputBase.IsEncrypted,
-					TimeId:      inputBase.TimeId,
-				}
-				recipientUUID, message, err := usecases.SendMessage(input)
-				if err != nil {
-					sendError(err.Error(), userUUID)
-					continue
-				}
-
-				// Notify user
-				notificationMessage, _ := json.Marshal(message)
-				sendNotification(
-					"message-received",
-					notificationMessage,
-					recipientUUID)
-
-				sendOk(userUUID)
-
-				sendNotification(
-					"message-received",
-					notificationMessage,
-					userUUID)
-
-			case "delete-message":
-				var input = models.DeleteMessageInput{}
-				err := json.Unmarshal(payload.Content, &input)
-				if err != nil {
-					sendError("invalid \"content\"", userUUID)
-					continue
-				}
-				if input.ChatId == "" {
-					sendError("invalid chat \"id\"", userUUID)
-					continue
-				}
-				input.UserId = c.Locals("user_uuid").(string)
-				recipientUUID, err := usecases.DeleteMessage(input)
-				if err != nil {
-					sendError(err.Error(), userUUID)
-					continue
-				}
-
-				// Notify user
-				notificationMessage, _ := json.Marshal(input)
-				sendNotification(
-					"message-deleted",
-					notificationMessage,
-					recipientUUID)
-
-				sendOk(userUUID)
-
-				sendNotification(
-					"message-deleted",
-					notificationMessage,
-					userUUID)
-
-			case "check-status":
-				var input = models.CheckStatusInput{}
-				err := json.Unmarshal(payload.Content, &input)
-				if err != nil {
-					sendError("invalid \"content\"", userUUID)
-					continue
-				}
-
-				if check := utility.IsValidUUID(input.UserId); !check {
-					sendError("invalid user \"id\"", userUUID)
-					continue
-				}
-
-				sendOk(userUUID)
-
-				statusCheck <- statusCheckContext{
-					SenderId: userUUID,
-					UserId:   input.UserId,
-				}
-
-			case "update-status":
-				var input = models.UpdateStatusInput{}
-				err := json.Unmarshal(payload.Content, &input)
-				if err != nil {
-					sendError("invalid \"content\"", userUUID)
-					continue
-				}
-
-				if check := utility.IsValidUUID(input.UserId); !check {
-					sendError("invalid user \"id\"", userUUID)
-					continue
-				}
-
-				if input.Status < -1 || input.Status > 1 {
-					sendError("invalid status", userUUID)
-					continue
-				}
-
-				sendOk(userUUID)
-
-				statusUpdate <- statusUpdateContext{
-					SenderId: userUUID,
-					UserId:   input.UserId,
-					Status:   input.Status,
-				}
-
-			case "get-read-time":
-				var input = models.GetReadTimeInput{}
-				err := json.Unmarshal(payload.Content, &input)
-				if err != nil {
-					sendError("invalid \"content\"", userUUID)
-					continue
-				}
-
-				readTime, err := usecases.GetReadTime(input)
-				if err != nil {
-					sendError(err.Error(), userUUID)
-					continue
-				}
-
-				// Notify user
-				notificationMessage, _ := json.Marshal(readTime)
-
-				sendOk(userUUID)
-
-				sendNotification(
-					"read-time-received",
-					notificationMessage,
-					userUUID)
-
-			case "update-read-time":
-				var input = models.UpdateReadTimeInput{}
-				err := json.Unmarshal(payload.Content, &input)
-				if err != nil {
-					sendError("invalid \"content\"", userUUID)
-					continue
-				}
-
-				input.UserId = userUUID
-
-				readTime, receiverId, err := usecases.UpdateReadTime(input)
-				if err != nil {
-					sendError(err.Error(), userUUID)
-					continue
-				}
-
-				// Notify user
-				notificationMessage, _ := json.Marshal(readTime)
-
-				sendOk(userUUID)
-
-				sendNotification(
-					"read-time-received",
-					notificationMessage,
-					receiverId)
-
-			default:
-				sendError("invalid \"action\"", userUUID)
+	actions.Register(actions.Spec[models.UpdateStatusInput, any]{
+		Name: "update-status",
+		Bind: func(in *models.UpdateStatusInput, c actions.Caller) { in.SenderId = c.Id },
+		Handle: func(in models.UpdateStatusInput) (any, error) {
+			if err := usecases.UpdateStatus(in); err != nil {
+				return nil, err
 			}
-		} else {
-			sendError("invalid websocket message type", userUUID)
-		}
-	}
-
-	unregister <- c
-	c.Close()
+			hub.statusUpdate <- statusUpdateContext{
+				SenderId: in.SenderId,
+				UserId:   in.UserId,
+				Status:   in.Status,
+			}
+			return nil, nil
+		},
+	})
 }
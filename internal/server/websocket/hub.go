@@ -0,0 +1,285 @@
+package websocket
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"spotigram/internal/server/config"
+
+	"github.com/gofiber/websocket/v2"
+)
+
+// Amount of messages a client may have queued before
+// it is considered a slow consumer and dropped.
+const sendBufferSize = 256
+
+// Timings of websocket connections, set by SetupTimings.
+var (
+	// Time allowed to write a single message to the peer.
+	writeWait = 10 * time.Second
+	// Time allowed to read the next pong from the peer.
+	pongWait = 60 * time.Second
+	// Period of sending pings to the peer, must be shorter than pongWait.
+	pingPeriod = pongWait * 9 / 10
+)
+
+// Sets the timings of websocket connections from the server config,
+// non-positive durations keep the defaults. A missing ping period
+// is derived from the pong wait.
+// Panics if pings are not sent more often than pongs are awaited.
+func SetupTimings() {
+	cfg := config.Cfg.Websocket
+	if cfg.WriteWait > 0 {
+		writeWait = cfg.WriteWait
+	}
+	if cfg.PongWait > 0 {
+		pongWait = cfg.PongWait
+	}
+	pingPeriod = pongWait * 9 / 10
+	if cfg.PingPeriod > 0 {
+		pingPeriod = cfg.PingPeriod
+	}
+	if pingPeriod >= pongWait {
+		panic(fmt.Errorf("websocket ping period %v must be shorter than the pong wait %v",
+			pingPeriod, pongWait))
+	}
+}
+
+// The subset of a websocket connection used by the hub.
+// Satisfied by *websocket.Conn.
+type connection interface {
+	WriteMessage(messageType int, data []byte) error
+	SetWriteDeadline(t time.Time) error
+	Close() error
+}
+
+type outboundMessage struct {
+	MessageType int
+	Data        []byte
+}
+
+// A single websocket connection of a user.
+// The send queue is owned by the hub, which is the only one closing it.
+type client struct {
+	userId string
+	conn   connection
+	send   chan outboundMessage
+	done   chan struct{}
+
+	// Time allowed to write a single message to the peer.
+	writeWait time.Duration
+	// Period of sending ping control frames to the peer.
+	pingPeriod time.Duration
+}
+
+// Returns a client with an empty outbound queue.
+func newClient(userId string, conn connection,
+	writeWait time.Duration, pingPeriod time.Duration) *client {
+	return &client{
+		userId:     userId,
+		conn:       conn,
+		send:       make(chan outboundMessage, sendBufferSize),
+		done:       make(chan struct{}),
+		writeWait:  writeWait,
+		pingPeriod: pingPeriod,
+	}
+}
+
+// Writes queued messages and periodic pings to the connection until
+// the queue is closed or a write fails, closing the connection afterwards.
+// Closes done on return.
+func (c *client) writePump() {
+	ticker := time.NewTicker(c.pingPeriod)
+	defer ticker.Stop()
+	defer close(c.done)
+	defer c.conn.Close()
+
+	for {
+		select {
+		case message, ok := <-c.send:
+			c.conn.SetWriteDeadline(time.Now().Add(c.writeWait))
+			if !ok {
+				c.conn.WriteMessage(websocket.CloseMessage, []byte{})
+				return
+			}
+			if err := c.conn.WriteMessage(message.MessageType, message.Data); err != nil {
+				return
+			}
+
+		case <-ticker.C:
+			c.conn.SetWriteDeadline(time.Now().Add(c.writeWait))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// Routes messages between connected clients.
+// The maps are only ever touched by the goroutine running the hub.
+type Hub struct {
+	clients      map[string]*client
+	chatStatuses map[string]*chatStatus
+
+	register     chan *client
+	unregister   chan *client
+	disconnect   chan string
+	broadcast    chan responceContext
+	statusCheck  chan statusCheckContext
+	statusUpdate chan statusUpdateContext
+}
+
+// Returns a hub with no clients.
+func NewHub() *Hub {
+	return &Hub{
+		clients:      make(map[string]*client),
+		chatStatuses: make(map[string]*chatStatus),
+		register:     make(chan *client),
+		unregister:   make(chan *client),
+		disconnect:   make(chan string),
+		broadcast:    make(chan responceContext),
+		statusCheck:  make(chan statusCheckContext),
+		statusUpdate: make(chan statusUpdateContext),
+	}
+}
+
+var hub = NewHub()
+
+// Runs the package's chat hub.
+func RunChatHub() {
+	hub.Run()
+}
+
+// Closes the open connection of a user, after the messages already queued
+// for it, used once the sessions of the user are ended.
+func DisconnectUser(userId string) {
+	hub.disconnect <- userId
+}
+
+// Runs the hub loop, never returns.
+func (h *Hub) Run() {
+	for {
+		select {
+		// Connect
+		case c := <-h.register:
+			if old, ok := h.clients[c.userId]; ok {
+				h.detach(old)
+			}
+			h.clients[c.userId] = c
+
+		// Disconnect
+		case c := <-h.unregister:
+			h.remove(c)
+
+		// Close the connection of a user
+		case userId := <-h.disconnect:
+			if c, ok := h.clients[userId]; ok {
+				h.remove(c)
+			}
+
+		// Send message
+		case ctx := <-h.broadcast:
+			h.deliver(ctx.ReceiverUUID, ctx.MessageType, []byte(ctx.Message))
+
+		// Update status
+		case ctx := <-h.statusUpdate:
+			h.updateStatus(ctx)
+
+		// Check status
+		case ctx := <-h.statusCheck:
+			h.checkStatus(ctx)
+		}
+	}
+}
+
+// Queues a message for a user without blocking.
+// Drops the user's client if its queue is full.
+func (h *Hub) deliver(userId string, messageType int, data []byte) {
+	c, ok := h.clients[userId]
+	if !ok {
+		return
+	}
+
+	select {
+	case c.send <- outboundMessage{MessageType: messageType, Data: data}:
+	default:
+		h.remove(c)
+	}
+}
+
+// Removes the client from the hub if it is still the registered one,
+// resetting its chat status and notifying its chat partner.
+func (h *Hub) remove(c *client) {
+	if h.clients[c.userId] != c {
+		return
+	}
+	h.detach(c)
+
+	status, ok := h.chatStatuses[c.userId]
+	if !ok {
+		return
+	}
+	delete(h.chatStatuses, c.userId)
+	h.notifyStatus(status.UserId, c.userId, "offline", "")
+}
+
+// Unlinks the client from the hub and closes its queue,
+// which makes its write pump close the connection.
+func (h *Hub) detach(c *client) {
+	delete(h.clients, c.userId)
+	close(c.send)
+}
+
+// Sends a status-update notification about a user to a receiver,
+// requestId is set when it answers a request of the receiver.
+func (h *Hub) notifyStatus(receiverId string, userId string, status string, requestId string) {
+	content, _ := json.Marshal(statusCheckResponse{
+		UserId: userId,
+		Status: status,
+	})
+	message, _ := json.Marshal(notificationResponce{
+		Status:    "status-update",
+		RequestId: requestId,
+		Content:   content,
+	})
+	h.deliver(receiverId, websocket.TextMessage, message)
+}
+
+func (h *Hub) updateStatus(ctx statusUpdateContext) {
+	if _, ok := h.clients[ctx.SenderId]; !ok {
+		return
+	}
+	prevChatStatus := h.chatStatuses[ctx.SenderId]
+
+	if ctx.Status != -1 {
+		h.chatStatuses[ctx.SenderId] = &chatStatus{
+			UserId: ctx.UserId,
+			Status: ctx.Status,
+		}
+		h.notifyStatus(ctx.UserId, ctx.SenderId, STATUSES[ctx.Status], "")
+	} else {
+		delete(h.chatStatuses, ctx.SenderId)
+		h.notifyStatus(ctx.UserId, ctx.SenderId, "offline", "")
+	}
+
+	if prevChatStatus != nil && prevChatStatus.UserId != ctx.UserId {
+		h.notifyStatus(prevChatStatus.UserId, ctx.SenderId, "offline", "")
+	}
+}
+
+func (h *Hub) checkStatus(ctx statusCheckContext) {
+	if _, ok := h.clients[ctx.SenderId]; !ok {
+		return
+	}
+
+	status := "offline"
+	if _, ok := h.clients[ctx.UserId]; ok {
+		chatStatus, ok := h.chatStatuses[ctx.UserId]
+		if ok && chatStatus.UserId == ctx.SenderId {
+			status = STATUSES[chatStatus.Status]
+		}
+	}
+
+	h.notifyStatus(ctx.SenderId, ctx.UserId, status, ctx.RequestId)
+}
@@ -0,0 +1,241 @@
+package websocket
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gofiber/websocket/v2"
+)
+
+// An in-memory connection recording the messages written to it.
+// Writes block while the connection is stalled.
+type fakeConnection struct {
+	mu       sync.Mutex
+	messages []outboundMessage
+	closed   bool
+	stall    chan struct{}
+}
+
+func newFakeConnection() *fakeConnection {
+	return &fakeConnection{}
+}
+
+func (f *fakeConnection) WriteMessage(messageType int, data []byte) error {
+	f.mu.Lock()
+	stall := f.stall
+	f.mu.Unlock()
+	if stall != nil {
+		<-stall
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.messages = append(f.messages, outboundMessage{MessageType: messageType, Data: data})
+	return nil
+}
+
+func (f *fakeConnection) SetWriteDeadline(t time.Time) error {
+	return nil
+}
+
+func (f *fakeConnection) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.closed = true
+	return nil
+}
+
+// Returns the data of the text messages written so far.
+func (f *fakeConnection) texts() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var texts []string
+	for _, m := range f.messages {
+		if m.MessageType == websocket.TextMessage {
+			texts = append(texts, string(m.Data))
+		}
+	}
+	return texts
+}
+
+func (f *fakeConnection) isClosed() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.closed
+}
+
+// Starts a hub loop for the test.
+func startHub(t *testing.T) *Hub {
+	t.Helper()
+	h := NewHub()
+	go h.Run()
+	return h
+}
+
+// Waits until the hub handled every event sent before,
+// the hub loop handles one event at a time.
+func (h *Hub) sync() {
+	h.statusCheck <- statusCheckContext{}
+}
+
+func newTestClient(userId string, conn connection) *client {
+	return newClient(userId, conn, time.Second, time.Hour)
+}
+
+func waitDone(t *testing.T, c *client) {
+	t.Helper()
+	select {
+	case <-c.done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("write pump did not stop")
+	}
+}
+
+func TestHubDeliversToRegisteredClient(t *testing.T) {
+	h := startHub(t)
+	conn := newFakeConnection()
+	c := newTestClient("user", conn)
+	go c.writePump()
+
+	h.register <- c
+	h.broadcast <- responceContext{MessageType: websocket.TextMessage, Message: "hello", ReceiverUUID: "user"}
+	h.broadcast <- responceContext{MessageType: websocket.TextMessage, Message: "lost", ReceiverUUID: "nobody"}
+	h.unregister <- c
+	waitDone(t, c)
+
+	if texts := conn.texts(); len(texts) != 1 || texts[0] != "hello" {
+		t.Fatalf("got messages %q, want [hello]", texts)
+	}
+	if !conn.isClosed() {
+		t.Fatal("connection was not closed after unregistering")
+	}
+}
+
+func TestHubUnregisterIgnoresReplacedClient(t *testing.T) {
+	h := startHub(t)
+	oldConn, newConn := newFakeConnection(), newFakeConnection()
+	oldClient := newTestClient("user", oldConn)
+	newClient := newTestClient("user", newConn)
+	go oldClient.writePump()
+	go newClient.writePump()
+
+	h.register <- oldClient
+	h.register <- newClient
+	waitDone(t, oldClient)
+
+	// The late unregister of the replaced client must keep the new one
+	h.unregister <- oldClient
+	h.broadcast <- responceContext{MessageType: websocket.TextMessage, Message: "hello", ReceiverUUID: "user"}
+	h.unregister <- newClient
+	waitDone(t, newClient)
+
+	if texts := newConn.texts(); len(texts) != 1 || texts[0] != "hello" {
+		t.Fatalf("got messages %q, want [hello]", texts)
+	}
+}
+
+func TestHubDropsSlowClient(t *testing.T) {
+	h := startHub(t)
+	conn := newFakeConnection()
+	c := newTestClient("user", conn)
+	// No write pump, nothing drains the queue
+	h.register <- c
+
+	for i := 0; i < sendBufferSize; i++ {
+		h.broadcast <- responceContext{MessageType: websocket.TextMessage, Message: "m", ReceiverUUID: "user"}
+	}
+	h.sync()
+	if len(c.send) != sendBufferSize {
+		t.Fatalf("queue holds %d messages, want %d", len(c.send), sendBufferSize)
+	}
+
+	// The queue is full, the client is dropped instead of blocking the hub
+	h.broadcast <- responceContext{MessageType: websocket.TextMessage, Message: "m", ReceiverUUID: "user"}
+	h.broadcast <- responceContext{MessageType: websocket.TextMessage, Message: "m", ReceiverUUID: "user"}
+	h.sync()
+
+	drained := 0
+	for range c.send {
+		drained++
+	}
+	if drained != sendBufferSize {
+		t.Fatalf("drained %d messages, want %d", drained, sendBufferSize)
+	}
+
+	// Unregistering the dropped client must not close its queue again
+	h.unregister <- c
+	h.sync()
+}
+
+func TestHubRemovesClientWhileSending(t *testing.T) {
+	h := startHub(t)
+
+	for round := 0; round < 20; round++ {
+		conn := newFakeConnection()
+		conn.stall = make(chan struct{})
+		c := newTestClient("user", conn)
+		go c.writePump()
+		h.register <- c
+
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 200; j++ {
+					h.broadcast <- responceContext{MessageType: websocket.TextMessage, Message: "m", ReceiverUUID: "user"}
+				}
+			}()
+		}
+		h.unregister <- c
+		wg.Wait()
+
+		conn.mu.Lock()
+		close(conn.stall)
+		conn.stall = nil
+		conn.mu.Unlock()
+		waitDone(t, c)
+	}
+}
+
+func TestHubStatusCheckEchoesRequestId(t *testing.T) {
+	h := startHub(t)
+	conn := newFakeConnection()
+	c := newTestClient("sender", conn)
+	go c.writePump()
+
+	h.register <- c
+	h.statusCheck <- statusCheckContext{SenderId: "sender", RequestId: "42", UserId: "user"}
+	h.unregister <- c
+	waitDone(t, c)
+
+	want := `{"status":"status-update","request_id":"42","content":{"user_id":"user","status":"offline"}}`
+	if texts := conn.texts(); len(texts) != 1 || texts[0] != want {
+		t.Fatalf("got messages %q, want [%s]", texts, want)
+	}
+}
+
+func TestHubDisconnectsUser(t *testing.T) {
+	h := startHub(t)
+	conn := newFakeConnection()
+	c := newTestClient("user", conn)
+	go c.writePump()
+
+	h.register <- c
+	h.broadcast <- responceContext{MessageType: websocket.TextMessage, Message: "suspended", ReceiverUUID: "user"}
+	h.disconnect <- "user"
+	h.disconnect <- "nobody"
+	waitDone(t, c)
+
+	if texts := conn.texts(); len(texts) != 1 || texts[0] != "suspended" {
+		t.Fatalf("got messages %q, want [suspended]", texts)
+	}
+	if !conn.isClosed() {
+		t.Fatal("connection was not closed")
+	}
+
+	// The read loop of the closed connection unregisters it afterwards
+	h.unregister <- c
+	h.sync()
+}
@@ -0,0 +1,20 @@
+package websocket
+
+const (
+	// Protocol version of clients that do not negotiate one.
+	// Fail echoes carry no error codes.
+	legacyProtocolVersion = 1
+
+	// Latest protocol version.
+	// Fail echoes carry an error code and the invalid fields.
+	latestProtocolVersion = 2
+)
+
+// Protocol error codes sent in fail echoes,
+// use case errors are mapped by customerrors.Code.
+const (
+	codeInvalidJson        = "invalid_json"
+	codeInvalidMessageType = "invalid_message_type"
+	codeInvalidPayload     = "invalid_payload"
+	codeInvalidAction      = "invalid_action"
+)
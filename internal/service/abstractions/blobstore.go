@@ -0,0 +1,28 @@
+package abstractions
+
+import "io"
+
+type BlobStore interface {
+	// Stores size bytes read from r under the key,
+	// replacing the previous blob of the key.
+	// May return ErrInternal on failure.
+	Put(key string, r io.Reader, size int64) error
+
+	// Opens the blob of the key for reading, the caller closes it.
+	// Returns the reader and the size of the blob.
+	// May return ErrInternal or ErrNotFound on failure.
+	Get(key string) (io.ReadCloser, int64, error)
+
+	// Opens length bytes of the blob of the key starting at offset,
+	// the caller closes it. The range must lie within the blob.
+	// May return ErrInternal or ErrNotFound on failure.
+	GetRange(key string, offset int64, length int64) (io.ReadCloser, error)
+
+	// Returns the size of the blob of the key.
+	// May return ErrInternal or ErrNotFound on failure.
+	Stat(key string) (int64, error)
+
+	// Deletes the blob of the key, missing blobs are ignored.
+	// May return ErrInternal on failure.
+	Delete(key string) error
+}
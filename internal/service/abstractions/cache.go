@@ -17,4 +17,9 @@ type JWTCache interface {
 	// Key is meant to be a uuid of a JWT, value is meant to be user uuid.
 	// May return ErrInternal on failure.
 	SetToken(key string, value string, expiresIn time.Duration) error
+
+	// Deletes every token of a user.
+	// Returns the number of deleted tokens.
+	// May return ErrInternal on failure.
+	DeleteUserTokens(userUUID string) (int64, error)
 }
@@ -6,8 +6,12 @@ var FriendRepositoryInstance FriendRepository
 
 var FriendRequestRepositoryInstance FriendRequestRepository
 
+var BlockRepositoryInstance BlockRepository
+
 var ChatRepositoryInstance ChatRepository
 
+var ChatMuteRepositoryInstance ChatMuteRepository
+
 var PlaylistRepositoryInstance PlaylistRepository
 
 var PlaylistSongRepositoryInstance PlaylistSongRepository
@@ -16,6 +20,22 @@ var SongRepositoryInstance SongRepository
 
 var SongChunkRepositoryInstance SongChunkRepository
 
+var ReportRepositoryInstance ReportRepository
+
+var AuditLogRepositoryInstance AuditLogRepository
+
+var ListenRepositoryInstance ListenRepository
+
+var FollowRepositoryInstance FollowRepository
+
+var UploadJobRepositoryInstance UploadJobRepository
+
+var AccountRepositoryInstance AccountRepository
+
+var AlbumRepositoryInstance AlbumRepository
+
+var BlobStoreInstance BlobStore
+
 var ServerInstance Server
 
 var JWTCacheInstance JWTCache
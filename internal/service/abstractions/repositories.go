@@ -2,6 +2,7 @@ package abstractions
 
 import (
 	"spotigram/internal/service/models"
+	"time"
 )
 
 type UserRepository interface {
@@ -24,20 +25,28 @@ type UserRepository interface {
 	// May return ErrInternal or ErrNotFound on failure.
 	GetPublicKey(uuid string) (string, error)
 
-	// Returns a user's picture by its uuid.
+	// Returns a user's picture left in the table by versions
+	// before the blob store, nil if it has none.
 	// UUID validation is not provided.
 	// May return ErrInternal or ErrNotFound on failure.
-	GetPicture(uuid string) ([]byte, error)
+	GetStoredPicture(uuid string) ([]byte, error)
+
+	// Returns the ids of at most limit users with a picture left in the table,
+	// ordered by id and starting after afterId.
+	// May return ErrInternal on failure.
+	GetUserIdsWithStoredPicture(afterId string, limit int) ([]string, error)
 
 	// Returns a user by its uuid.
 	// UUID validation is not provided.
 	// May return ErrInternal or ErrNotFound on failure.
 	GetUser(uuid string) (*models.User, error)
 
-	// Returns a user list filtered by name.
-	// Offset validation is provided.
-	// May return ErrInternal or ErrNotFound on failure.
-	GetUsers(offset int, usernameFilter string) ([]models.User, error)
+	// Returns a page of users filtered by name, starting after the cursor.
+	// Users blocking or blocked by the requester are excluded,
+	// unless the requester uuid is empty.
+	// Cursor validation is provided.
+	// May return ErrInternal, ErrInvalidInput or ErrNotFound on failure.
+	GetUsers(cursor string, limit int, usernameFilter string, requesterUUID string) (*models.Page[models.User], error)
 
 	// Updates a user's name.
 	// UUID and name validation is not provided.
@@ -56,11 +65,83 @@ type UserRepository interface {
 	// May return ErrInternal or ErrNotFound on failure.
 	UpdatePublicKey(uuid string, public_key string) error
 
-	// Updates a user's picture.
-	// UUID and image file validation is not provided.
-	// *Image is meant to be webp of 512 by 512 or lower size*.
+	// Clears a user's picture left in the table once it is in the blob store.
+	// UUID validation is not provided.
+	// May return ErrInternal or ErrNotFound on failure.
+	ClearStoredPicture(uuid string) error
+
+	// Updates a user's suspension status.
+	// UUID validation is not provided.
+	// May return ErrInternal or ErrNotFound on failure.
+	UpdateSuspended(uuid string, suspended bool) error
+
+	// Updates a user's role.
+	// UUID and role validation is not provided.
+	// May return ErrInternal or ErrNotFound on failure.
+	UpdateRole(uuid string, role string) error
+
+	// Updates a user's verification status.
+	// UUID validation is not provided.
+	// May return ErrInternal or ErrNotFound on failure.
+	UpdateVerified(uuid string, verified bool) error
+
+	// Returns a page of users matching the filter ordered by id,
+	// starting after the cursor, the query matches names and emails.
+	// Cursor validation is provided.
+	// May return ErrInternal, ErrInvalidInput or ErrNotFound on failure.
+	SearchUsers(filter models.UserFilter, cursor string, limit int) (*models.Page[models.User], error)
+
+	// Returns the uuid of a user by its handle.
+	// Handle validation is not provided.
+	// May return ErrInternal or ErrNotFound on failure.
+	GetUUIDByHandle(handle string) (string, error)
+
+	// Returns when a user's handle was last changed,
+	// zero time if it never was.
+	// UUID validation is not provided.
 	// May return ErrInternal or ErrNotFound on failure.
-	UpdatePicture(uuid string, image []byte) error
+	GetHandleChangedAt(uuid string) (time.Time, error)
+
+	// Updates a user's handle and its change time by its uuid.
+	// UUID validation is not provided.
+	// May return ErrInternal, ErrInvalidInput or ErrNotFound on failure.
+	UpdateHandle(uuid string, handle string, changedAt time.Time) error
+
+	// Updates a user's bio, location and links by its uuid.
+	// UUID validation is not provided.
+	// May return ErrInternal or ErrNotFound on failure.
+	UpdateProfile(uuid string, bio string, location string, links []string) error
+
+	// Returns a user's banner left in the table by versions
+	// before the blob store, nil if it has none.
+	// UUID validation is not provided.
+	// May return ErrInternal or ErrNotFound on failure.
+	GetStoredBanner(uuid string) ([]byte, error)
+
+	// Returns the ids of at most limit users with a banner left in the table,
+	// ordered by id and starting after afterId.
+	// May return ErrInternal on failure.
+	GetUserIdsWithStoredBanner(afterId string, limit int) ([]string, error)
+
+	// Clears a user's banner left in the table once it is in the blob store.
+	// UUID validation is not provided.
+	// May return ErrInternal or ErrNotFound on failure.
+	ClearStoredBanner(uuid string) error
+
+	// Drops the picture and banner columns left by versions before
+	// the blob store, once everything in them is in the blob store.
+	// May return ErrInternal on failure.
+	DropStoredBlobColumns() error
+
+	// Returns a user's privacy settings by its uuid.
+	// UUID validation is not provided.
+	// May return ErrInternal or ErrNotFound on failure.
+	GetPrivacy(uuid string) (*models.PrivacySettings, error)
+
+	// Updates a user's privacy settings by its uuid.
+	// UUID validation is not provided.
+	// May return ErrInternal or ErrNotFound on failure.
+	UpdatePrivacy(uuid string, privacy models.PrivacySettings) error
 
 	// Returns bool on whether the user uuid is present.
 	// UUID validation is not provided.
@@ -69,10 +150,10 @@ type UserRepository interface {
 }
 
 type FriendRepository interface {
-	// Returns a user's friends by its uuid.
+	// Returns a page of a user's friends by its uuid, starting after the cursor.
 	// UUID validation is not provided.
-	// May return ErrInternal or ErrNotFound on failure.
-	GetFriends(uuid string, offset int) ([]models.Friend, error)
+	// May return ErrInternal, ErrInvalidInput or ErrNotFound on failure.
+	GetFriends(uuid string, cursor string, limit int) (*models.Page[models.Friend], error)
 
 	// Returns a char id with between two user.
 	// UUID sort is provided.
@@ -99,6 +180,23 @@ type FriendRepository interface {
 	// UUID sort is provided.
 	// May return ErrInternal on failure.
 	DoesFriendExist(uuid1, uuid2 string) (bool, error)
+
+	// Returns the number of a user's friends.
+	// UUID validation is not provided.
+	// May return ErrInternal on failure.
+	CountFriends(uuid string) (int, error)
+
+	// Checks whether two users have a friend in common.
+	// UUID validation is not provided.
+	// May return ErrInternal on failure.
+	HasMutualFriend(uuid1, uuid2 string) (bool, error)
+
+	// Returns a page of users the user is not related to, ranked by mutual
+	// friends and by their listens of the user's listened songs or songIds,
+	// starting after the cursor.
+	// UUID validation is not provided.
+	// May return ErrInternal, ErrInvalidInput or ErrNotFound on failure.
+	GetFriendSuggestions(uuid string, songIds []string, cursor string, limit int) (*models.Page[models.FriendSuggestion], error)
 }
 
 type FriendRequestRepository interface {
@@ -114,15 +212,15 @@ type FriendRequestRepository interface {
 	// May return ErrInternal, ErrInvalidInput or ErrNotFound on failure.
 	DeleteFriendRequest(senderUUID, recipientUUID string) error
 
-	// Returns a user's sent friend requests by its uuid.
+	// Returns a page of a user's sent friend requests by its uuid, starting after the cursor.
 	// UUID validation is not provided.
-	// May return ErrInternal or ErrNotFound on failure.
-	GetFriendRequestsSent(uuid string, offset int) ([]models.FriendRequest, error)
+	// May return ErrInternal, ErrInvalidInput or ErrNotFound on failure.
+	GetFriendRequestsSent(uuid string, cursor string, limit int) (*models.Page[models.FriendRequest], error)
 
-	// Returns a user's received friend requests by its uuid.
+	// Returns a page of a user's received friend requests by its uuid, starting after the cursor.
 	// UUID validation is not provided.
-	// May return ErrInternal or ErrNotFound on failure.
-	GetFriendRequestsReceived(uuid string, offset int) ([]models.FriendRequest, error)
+	// May return ErrInternal, ErrInvalidInput or ErrNotFound on failure.
+	GetFriendRequestsReceived(uuid string, cursor string, limit int) (*models.Page[models.FriendRequest], error)
 
 	// Returns bool on whether the friend is present.
 	// UUID validation is not provided.
@@ -130,6 +228,82 @@ type FriendRequestRepository interface {
 	DoesFriendRequestExist(senderUUID, recipientUUID string) (bool, error)
 }
 
+type BlockRepository interface {
+	// Adds a block to the repository.
+	// May return ErrInternal or ErrInvalidInput on failure.
+	AddBlock(block models.Block) error
+
+	// Deletes a block from the repository.
+	// May return ErrInternal or ErrNotFound on failure.
+	DeleteBlock(blockerUUID, blockedUUID string) error
+
+	// Returns a page of the blocks made by a user, ordered by blocked id,
+	// starting after the cursor.
+	// UUID validation is not provided.
+	// May return ErrInternal, ErrInvalidInput or ErrNotFound on failure.
+	GetBlocks(blockerUUID string, cursor string, limit int) (*models.Page[models.Block], error)
+
+	// Returns bool on whether either of the users blocked the other.
+	// UUID validation is not provided.
+	// May return ErrInternal on failure.
+	IsEitherBlocked(uuid1, uuid2 string) (bool, error)
+}
+
+type ChatMuteRepository interface {
+	// Mutes a chat for a user.
+	// May return ErrInternal or ErrInvalidInput on failure.
+	AddMute(userId string, chatId string) error
+
+	// Unmutes a chat for a user.
+	// May return ErrInternal or ErrNotFound on failure.
+	DeleteMute(userId string, chatId string) error
+
+	// Returns bool on whether the user muted the chat.
+	// May return ErrInternal on failure.
+	IsMuted(userId string, chatId string) (bool, error)
+
+	// Deletes every mute of a chat.
+	// May return ErrInternal on failure.
+	DeleteMutesByChatId(chatId string) error
+}
+
+type ReportRepository interface {
+	// Adds a report to the repository.
+	// May return ErrInternal or ErrInvalidInput on failure.
+	AddReport(report models.Report) error
+
+	// Returns a report by its id.
+	// UUID validation is not provided.
+	// May return ErrInternal or ErrNotFound on failure.
+	GetReport(reportId string) (*models.Report, error)
+
+	// Returns a page of reports, oldest first, filtered by status unless empty,
+	// starting after the cursor.
+	// May return ErrInternal, ErrInvalidInput or ErrNotFound on failure.
+	GetReports(status string, cursor string, limit int) (*models.Page[models.Report], error)
+
+	// Sets the status, resolver and resolution of a report.
+	// UUID validation is not provided.
+	// May return ErrInternal or ErrNotFound on failure.
+	ResolveReport(reportId string, moderatorId string, status string, resolution string) error
+
+	// Returns a page of the reports made by a user, oldest first,
+	// starting after the cursor.
+	// UUID validation is not provided.
+	// May return ErrInternal, ErrInvalidInput or ErrNotFound on failure.
+	GetReportsByReporter(uuid string, cursor string, limit int) (*models.Page[models.Report], error)
+}
+
+type AuditLogRepository interface {
+	// Adds an entry to the audit log.
+	// May return ErrInternal or ErrInvalidInput on failure.
+	AddEntry(entry models.AuditLogEntry) error
+
+	// Returns a page of entries, newest first, starting after the cursor.
+	// May return ErrInternal, ErrInvalidInput or ErrNotFound on failure.
+	GetEntries(cursor string, limit int) (*models.Page[models.AuditLogEntry], error)
+}
+
 type ChatRepository interface {
 	// Deletes a whole chat from the repository by its id.
 	// UUID validation is not provided.
@@ -153,10 +327,10 @@ type ChatRepository interface {
 }
 
 type PlaylistRepository interface {
-	// Returns first 100 messages before a certain time id.
+	// Returns a page of a user's playlists ordered by id, starting after the cursor.
 	// UUID validation is not provided.
-	// May return ErrInternal.
-	GetPlaylists(userId string, offset int, playlistNameFilter string) ([]models.Playlist, error)
+	// May return ErrInternal, ErrInvalidInput or ErrNotFound on failure.
+	GetPlaylists(userId string, cursor string, limit int, playlistNameFilter string) (*models.Page[models.Playlist], error)
 
 	// Returns the playlist by its id.
 	// UUID validation is not provided.
@@ -192,7 +366,7 @@ type PlaylistSongRepository interface {
 	// May return ErrInternal.
 	DeletePlaylistSongs(playlistId string) error
 
-	// Adds a song from all playlists.
+	// Deletes a song from all playlists.
 	// May return ErrInternal.
 	DeleteSong(songId string) error
 
@@ -207,10 +381,17 @@ type PlaylistSongRepository interface {
 }
 
 type SongRepository interface {
-	// Returns first 100 messages before a certain time id.
-	// UUID validation is not provided
-	// May return ErrInternal
-	GetSongs(offset int, songNameFilter string, creatorIdFilter string) ([]models.Song, error)
+	// Returns a page of the most streamed songs, starting after the cursor.
+	// Hidden songs are left out.
+	// UUID validation is not provided.
+	// May return ErrInternal, ErrInvalidInput or ErrNotFound on failure.
+	GetSongs(cursor string, limit int, songNameFilter string, creatorIdFilter string) (*models.Page[models.Song], error)
+
+	// Returns a page of the songs of a creator ordered by id,
+	// starting after the cursor, hidden songs included.
+	// UUID validation is not provided.
+	// May return ErrInternal, ErrInvalidInput or ErrNotFound on failure.
+	GetSongsByCreator(creatorId string, cursor string, limit int) (*models.Page[models.Song], error)
 
 	// Returns songs info by its id.
 	// UUID validation is not provided.
@@ -222,43 +403,208 @@ type SongRepository interface {
 	// May return ErrInternal.
 	IncrementStreams(songId string) error
 
-	// Returns songs file by its id.
+	// Returns a page of the visible songs of the creators the user follows,
+	// newest first, starting after the cursor. Songs uploaded before
+	// upload times were recorded are left out.
+	// UUID validation is not provided.
+	// May return ErrInternal, ErrInvalidInput or ErrNotFound on failure.
+	GetFeed(userId string, cursor string, limit int) (*models.Page[models.Song], error)
+
+	// Returns the picture and the file of a song left in the table
+	// by versions before the blob store, nil if it has none.
 	// UUID validation is not provided.
 	// May return ErrInternal or ErrNotFound on failure.
-	GetSongFile(songId string) ([]byte, error)
+	GetStoredBlobs(songId string) (picture []byte, file []byte, err error)
+
+	// Returns the ids of at most limit songs with a picture or a file
+	// left in the table, ordered by id and starting after afterId.
+	// May return ErrInternal on failure.
+	GetSongIdsWithStoredBlobs(afterId string, limit int) ([]string, error)
 
-	// Returns a song's picture by its uuid.
+	// Clears the picture and the file of a song left in the table
+	// once they are in the blob store.
 	// UUID validation is not provided.
 	// May return ErrInternal or ErrNotFound on failure.
-	GetPicture(songId string) ([]byte, error)
+	ClearStoredBlobs(songId string) error
+
+	// Drops the picture and file columns left by versions before
+	// the blob store, once everything in them is in the blob store.
+	// May return ErrInternal on failure.
+	DropStoredBlobColumns() error
 
-	// Adds song to the repository.
+	// Adds song to the repository, its picture
+	// and file are kept in the blob store.
 	// May return ErrInternal or ErrInvalidInput on failure.
-	AddSong(song models.Song, picture []byte, file []byte) error
+	AddSong(song models.Song, waveform []byte) error
+
+	// Returns the waveform peaks of a song, nil if it has none.
+	// UUID validation is not provided.
+	// May return ErrInternal or ErrNotFound on failure.
+	GetWaveform(songId string) ([]byte, error)
+
+	// Sets the waveform peaks of a song.
+	// UUID validation is not provided.
+	// May return ErrInternal or ErrNotFound on failure.
+	UpdateWaveform(songId string, waveform []byte) error
+
+	// Returns the ids of at most limit songs without waveform peaks,
+	// ordered by id and starting after afterId.
+	// May return ErrInternal on failure.
+	GetSongIdsWithoutWaveform(afterId string, limit int) ([]string, error)
 
 	// Updates a song name.
 	// May return ErrInternal or ErrNotFound on failure.
 	UpdateSongName(songId string, newName string) error
 
+	// Updates whether a song is hidden from the songs list.
+	// May return ErrInternal or ErrNotFound on failure.
+	UpdateHidden(songId string, hidden bool) error
+
 	// Deletes a song from the repository.
 	// May return ErrInternal or ErrNotFound on failure.
 	DeleteSong(songId string) error
 }
 
 type SongChunkRepository interface {
-	// Uploads song chunk to the repository.
+	// Uploads song chunk of a rendition to the repository,
+	// the playlist of a rendition has index -1.
 	// May return ErrInternal on failure.
-	AddSongChunk(songId string, id int, chunk []byte) error
+	AddSongChunk(songId string, rendition string, id int, chunk []byte) error
 
-	// Uploads a song chunks to the repository.
+	// Returns a song chunk of a rendition, an empty rendition
+	// stands for the single rendition of songs uploaded before
+	// adaptive bitrate streaming.
 	// May return ErrInternal or ErrNotFound on failure.
-	GetSongChunk(songId string, index int) ([]byte, error)
+	GetSongChunk(songId string, rendition string, index int) ([]byte, error)
 
 	// Deletes a song from the repository.
 	// May return ErrInternal or ErrNotFound on failure.
 	DeleteSongChunks(songId string) error
 }
 
+type FollowRepository interface {
+	// Adds a follow to the repository.
+	// May return ErrInternal or ErrInvalidInput on failure.
+	AddFollow(follow models.Follow) error
+
+	// Deletes a follow from the repository.
+	// May return ErrInternal or ErrNotFound on failure.
+	DeleteFollow(followerUUID, followeeUUID string) error
+
+	// Checks whether the follower follows the followee.
+	// May return ErrInternal on failure.
+	DoesFollowExist(followerUUID, followeeUUID string) (bool, error)
+
+	// Returns a page of the follows of a followee, newest first, starting after the cursor.
+	// May return ErrInternal, ErrInvalidInput or ErrNotFound on failure.
+	GetFollowers(followeeUUID string, cursor string, limit int) (*models.Page[models.Follow], error)
+
+	// Returns a page of the follows of a follower, newest first, starting after the cursor.
+	// May return ErrInternal, ErrInvalidInput or ErrNotFound on failure.
+	GetFollowing(followerUUID string, cursor string, limit int) (*models.Page[models.Follow], error)
+
+	// Returns the number of followers and followed users of a user.
+	// May return ErrInternal on failure.
+	CountFollows(uuid string) (*models.FollowCounts, error)
+}
+
+type AlbumRepository interface {
+	// Adds an album to the repository.
+	// May return ErrInternal or ErrInvalidInput on failure.
+	AddAlbum(album models.Album) error
+
+	// Returns an album by its id.
+	// UUID validation is not provided.
+	// May return ErrInternal or ErrNotFound on failure.
+	GetAlbum(albumId string) (*models.Album, error)
+
+	// Returns a page of albums, newest releases first, starting after the cursor.
+	// Empty filters are ignored.
+	// May return ErrInternal, ErrInvalidInput or ErrNotFound on failure.
+	GetAlbums(cursor string, limit int, nameFilter string, creatorIdFilter string, typeFilter string) (*models.Page[models.Album], error)
+
+	// Returns the visible songs of an album ordered by track number,
+	// songs without a track number come last.
+	// UUID validation is not provided.
+	// May return ErrInternal on failure.
+	GetAlbumTracks(albumId string) ([]models.Song, error)
+
+	// Returns the cover of an album left in the table by versions
+	// before the blob store, nil if it has none.
+	// UUID validation is not provided.
+	// May return ErrInternal or ErrNotFound on failure.
+	GetStoredCover(albumId string) ([]byte, error)
+
+	// Returns the ids of at most limit albums with a cover left in the table,
+	// ordered by id and starting after afterId.
+	// May return ErrInternal on failure.
+	GetAlbumIdsWithStoredCover(afterId string, limit int) ([]string, error)
+
+	// Clears the cover of an album left in the table once it is in the blob store.
+	// UUID validation is not provided.
+	// May return ErrInternal or ErrNotFound on failure.
+	ClearStoredCover(albumId string) error
+
+	// Drops the cover column left by versions before the blob store,
+	// once every cover in it is in the blob store.
+	// May return ErrInternal on failure.
+	DropStoredBlobColumns() error
+
+	// Deletes an album, its songs are kept without an album.
+	// UUID validation is not provided.
+	// May return ErrInternal or ErrNotFound on failure.
+	DeleteAlbum(albumId string) error
+}
+
+type AccountRepository interface {
+	// Deletes a user with every row referring to them in one transaction,
+	// recording the cleanups of their data kept elsewhere.
+	// Returns the ids of the user's former friends.
+	// UUID validation is not provided.
+	// May return ErrInternal or ErrNotFound on failure.
+	DeleteAccount(userId string) ([]string, error)
+
+	// Returns at most limit cleanups left by deleted accounts.
+	// May return ErrInternal on failure.
+	GetAccountCleanups(limit int) ([]models.AccountCleanup, error)
+
+	// Deletes a cleanup once done, missing cleanups are ignored.
+	// May return ErrInternal on failure.
+	DeleteAccountCleanup(cleanup models.AccountCleanup) error
+}
+
+type UploadJobRepository interface {
+	// Adds an upload job to the repository.
+	// May return ErrInternal or ErrInvalidInput on failure.
+	AddJob(job models.UploadJob) error
+
+	// Returns an upload job by its id.
+	// UUID validation is not provided.
+	// May return ErrInternal or ErrNotFound on failure.
+	GetJob(id string) (*models.UploadJob, error)
+
+	// Sets the status of an upload job, errorMessage is empty unless it failed.
+	// UUID validation is not provided.
+	// May return ErrInternal or ErrNotFound on failure.
+	UpdateJobStatus(id string, status string, errorMessage string) error
+
+	// Marks every queued or processing job as failed.
+	// Returns the failed jobs.
+	// May return ErrInternal on failure.
+	FailUnfinishedJobs(errorMessage string) ([]models.UploadJob, error)
+}
+
+type ListenRepository interface {
+	// Adds a listen to the repository.
+	// May return ErrInternal or ErrInvalidInput on failure.
+	AddListen(listen models.LastStream) error
+
+	// Returns a page of the listens of a user, newest first, starting after the cursor.
+	// UUID validation is not provided.
+	// May return ErrInternal, ErrInvalidInput or ErrNotFound on failure.
+	GetListens(userId string, cursor string, limit int) (*models.Page[models.LastStream], error)
+}
+
 type ReadTimeRepository interface {
 	// Adds ReadTime to the repository.
 	// May return ErrInternal on failure.
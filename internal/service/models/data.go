@@ -2,12 +2,66 @@ package models
 
 import "time"
 
+// User roles.
+const (
+	RoleUser      = "user"
+	RoleArtist    = "artist"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+)
+
 type User struct {
-	Id       string `json:"id"`
-	Name     string `json:"name"`
-	Email    string `json:"email"`
-	Password string `json:"-"`
-	Verified bool   `json:"verified"`
+	Id        string   `json:"id"`
+	Name      string   `json:"name"`
+	Email     string   `json:"email"`
+	Password  string   `json:"-"`
+	Verified  bool     `json:"verified"`
+	Role      string   `json:"role"`
+	Suspended bool     `json:"suspended"`
+	Handle    string   `json:"handle"`
+	Bio       string   `json:"bio"`
+	Location  string   `json:"location"`
+	Links     []string `json:"links"`
+}
+
+// Filters of the admin user search, nil and empty fields are not applied.
+type UserFilter struct {
+	Query     string
+	Role      string
+	Suspended *bool
+	Verified  *bool
+}
+
+// A user with their content, as seen by admins.
+type UserOverview struct {
+	User        *User      `json:"user"`
+	Songs       []Song     `json:"songs"`
+	Playlists   []Playlist `json:"playlists"`
+	FriendCount int        `json:"friend_count"`
+}
+
+// Everything stored about a user, as exported to them.
+type AccountExport struct {
+	User                   *User            `json:"user"`
+	PublicKey              string           `json:"public_key"`
+	Friends                []Friend         `json:"friends"`
+	FriendRequestsSent     []FriendRequest  `json:"friend_requests_sent"`
+	FriendRequestsReceived []FriendRequest  `json:"friend_requests_received"`
+	Blocks                 []Block          `json:"blocks"`
+	Playlists              []PlaylistExport `json:"playlists"`
+	Songs                  []Song           `json:"songs"`
+	Albums                 []Album          `json:"albums"`
+	Reports                []Report         `json:"reports"`
+	Messages               []Message        `json:"messages"`
+	Privacy                *PrivacySettings `json:"privacy"`
+	Listens                []LastStream     `json:"listens"`
+	Following              []Follow         `json:"following"`
+	Followers              []Follow         `json:"followers"`
+}
+
+type PlaylistExport struct {
+	Playlist
+	SongIds []string `json:"song_ids"`
 }
 
 type Friend struct {
@@ -16,6 +70,20 @@ type Friend struct {
 	ChatId string `json:"chat_id"`
 }
 
+// A user the current user may know, ranked by MutualFriends and SharedSongs.
+type FriendSuggestion struct {
+	Id            string `json:"id"`
+	Name          string `json:"name"`
+	Handle        string `json:"handle"`
+	MutualFriends int    `json:"mutual_friends"`
+	SharedSongs   int    `json:"shared_songs"`
+}
+
+type Block struct {
+	BlockerId string `json:"blocker_id"`
+	BlockedId string `json:"blocked_id"`
+}
+
 type FriendRequest struct {
 	SenderId    string `json:"sender_id"`
 	RecipientId string `json:"recipient_id"`
@@ -43,17 +111,149 @@ type PlaylistSong struct {
 }
 
 type Song struct {
-	Id        string `json:"id"`
-	CreatorId string `json:"creator_id"`
-	Name      string `json:"name"`
-	Length    int    `json:"length"`
-	Streams   int    `json:"streams"`
+	Id          string     `json:"id"`
+	CreatorId   string     `json:"creator_id"`
+	Name        string     `json:"name"`
+	Length      int        `json:"length"`
+	Artist      string     `json:"artist"`
+	Album       string     `json:"album"`
+	TrackNumber int        `json:"track_number"`
+	Year        int        `json:"year"`
+	Genre       string     `json:"genre"`
+	Duration    float64    `json:"duration"`
+	AlbumId     string     `json:"album_id,omitempty"`
+	Loudness    *float64   `json:"loudness,omitempty"`
+	TruePeak    *float64   `json:"true_peak,omitempty"`
+	TrackGain   *float64   `json:"track_gain,omitempty"`
+	Normalized  bool       `json:"normalized"`
+	Streams     int        `json:"streams"`
+	Hidden      bool       `json:"hidden"`
+	CreatedAt   *time.Time `json:"created_at,omitempty"`
+}
+
+// The original file of a song as uploaded.
+type SongFileInfo struct {
+	// Download file name, the song name with the format extension
+	Name        string `json:"name"`
+	Format      string `json:"format"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+	// Quoted entity tag, files never change once uploaded
+	ETag string `json:"etag"`
+}
+
+// Waveform peaks of a song, min/max pairs of samples scaled to -128..127.
+type Waveform struct {
+	Peaks [][2]int8 `json:"peaks"`
+}
+
+// Types of an album.
+const (
+	AlbumTypeAlbum  = "album"
+	AlbumTypeEP     = "ep"
+	AlbumTypeSingle = "single"
+)
+
+// An album, EP or single of a song creator,
+// ReleaseDate is formatted as YYYY-MM-DD.
+type Album struct {
+	Id          string    `json:"id"`
+	CreatorId   string    `json:"creator_id"`
+	Name        string    `json:"name"`
+	Type        string    `json:"type"`
+	ReleaseDate string    `json:"release_date"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// An album with its songs ordered by track number.
+type AlbumInfo struct {
+	Album
+	Tracks []Song `json:"tracks"`
 }
 
+// A page of a keyset paginated list,
+// NextCursor is empty on the last page.
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"next_cursor"`
+}
+
+// Kinds of data of a deleted account kept outside of the sql database.
+const (
+	// Messages of a chat.
+	AccountCleanupChat = "chat"
+	// Songs of a playlist.
+	AccountCleanupPlaylist = "playlist"
+	// Chunks, files and playlist entries of a song.
+	AccountCleanupSong = "song"
+	// Cover of an album.
+	AccountCleanupAlbum = "album"
+	// Picture, banner and sessions of the user.
+	AccountCleanupUser = "user"
+)
+
+// Data of a deleted account left to delete outside of the sql database.
+type AccountCleanup struct {
+	Kind     string
+	TargetId string
+}
+
+// Statuses of an upload job.
+const (
+	UploadJobQueued     = "queued"
+	UploadJobProcessing = "processing"
+	UploadJobDone       = "done"
+	UploadJobFailed     = "failed"
+)
+
+// An asynchronous song ingestion job, SongId is the id
+// the song gets once the job is done.
+type UploadJob struct {
+	Id        string    `json:"id"`
+	UserId    string    `json:"user_id"`
+	SongId    string    `json:"song_id"`
+	SongName  string    `json:"song_name"`
+	Status    string    `json:"status"`
+	Error     string    `json:"error,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type Follow struct {
+	FollowerId string    `json:"follower_id"`
+	FolloweeId string    `json:"followee_id"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type FollowCounts struct {
+	Followers int `json:"followers"`
+	Following int `json:"following"`
+}
+
+// A song listened to by a user, Time is in unix microseconds.
 type LastStream struct {
-	UserId string
-	SongId string
-	Time   int64
+	UserId string `json:"user_id"`
+	SongId string `json:"song_id"`
+	Time   int64  `json:"time"`
+}
+
+// Privacy levels.
+const (
+	PrivacyEveryone         = "everyone"
+	PrivacyFriendsOfFriends = "friends-of-friends"
+	PrivacyFriends          = "friends"
+	PrivacyNobody           = "nobody"
+)
+
+type PrivacySettings struct {
+	// Whether the user is listed by /user/all to non-friends.
+	Discoverable bool `json:"discoverable"`
+	// Who can send friend requests: everyone, friends-of-friends or nobody.
+	FriendRequests string `json:"friend_requests"`
+	// Who can see the picture: everyone, friends or nobody.
+	PictureVisibility string `json:"picture_visibility"`
+	// Who can see the listening activity: everyone, friends or nobody.
+	ActivityVisibility string `json:"activity_visibility"`
 }
 
 type ReadTime struct {
@@ -61,3 +261,42 @@ type ReadTime struct {
 	ChatId string
 	TimeId int64
 }
+
+// Report target types.
+const (
+	ReportTargetSong     = "song"
+	ReportTargetPlaylist = "playlist"
+	ReportTargetUser     = "user"
+	ReportTargetMessage  = "message"
+)
+
+// Report statuses.
+const (
+	ReportStatusOpen      = "open"
+	ReportStatusResolved  = "resolved"
+	ReportStatusDismissed = "dismissed"
+)
+
+// For message reports TargetId is the chat id and MessageId the message time id.
+type Report struct {
+	Id         string    `json:"id"`
+	ReporterId string    `json:"reporter_id"`
+	TargetType string    `json:"target_type"`
+	TargetId   string    `json:"target_id"`
+	MessageId  int64     `json:"message_id,omitempty"`
+	Reason     string    `json:"reason"`
+	Status     string    `json:"status"`
+	CreatedAt  time.Time `json:"created_at"`
+	ResolvedBy string    `json:"resolved_by,omitempty"`
+	Resolution string    `json:"resolution,omitempty"`
+}
+
+type AuditLogEntry struct {
+	Id          string    `json:"id"`
+	ModeratorId string    `json:"moderator_id"`
+	Action      string    `json:"action"`
+	TargetType  string    `json:"target_type"`
+	TargetId    string    `json:"target_id"`
+	Details     string    `json:"details"`
+	CreatedAt   time.Time `json:"created_at"`
+}
@@ -5,6 +5,13 @@ import "encoding/json"
 // Fields not marked with tags are meant to be set manually.
 // Fields markes with 'json' tag are meant to be unmarshalled from the request.
 
+// Keyset pagination of list inputs, Cursor is the next_cursor
+// of the previous page, Limit falls back to the default page size.
+type PageInput struct {
+	Cursor string `json:"cursor"`
+	Limit  int    `json:"limit"`
+}
+
 // Auth
 type SignUpInput struct {
 	Name              string `validate:"required,min=5,max=100" json:"name"`
@@ -33,21 +40,38 @@ type DeserializeTokenInput struct {
 }
 
 // User
+// RequesterUUID is the user asking, blocks between
+// the requester and the users are enforced if set.
 type GetUsersInput struct {
-	Offset         int    `validate:"required" json:"offset"`
+	RequesterUUID string `json:"-"`
+	PageInput
 	UserNameFilter string `validate:"max=100" json:"username_filter"`
 }
 
 type GetUserInfoInput struct {
-	UserUUID string `validate:"required,min=8,max=130" json:"id"`
+	RequesterUUID string `json:"-"`
+	UserUUID      string `validate:"required,min=8,max=130" json:"id"`
 }
 
 type GetPublicKeyInput struct {
-	UserUUID string `validate:"required,min=8,max=130" json:"id"`
+	RequesterUUID string `json:"-"`
+	UserUUID      string `validate:"required,min=8,max=130" json:"id"`
 }
 
 type GetPictureInput struct {
-	UserUUID string `validate:"required,min=8,max=130" json:"id"`
+	RequesterUUID string `json:"-"`
+	UserUUID      string `validate:"required,min=8,max=130" json:"id"`
+}
+
+type GetActivityInput struct {
+	RequesterUUID string `json:"-"`
+	UserUUID      string `validate:"required,min=8,max=130" json:"id"`
+	PageInput
+}
+
+type GetBannerInput struct {
+	RequesterUUID string `json:"-"`
+	UserUUID      string `validate:"required,min=8,max=130" json:"id"`
 }
 
 // Me
@@ -73,10 +97,49 @@ type ChangePictureInput struct {
 	UserUUID string
 }
 
+type ChangeHandleInput struct {
+	Handle   string `validate:"required,min=3,max=31" json:"handle"`
+	UserUUID string
+}
+
+type UpdateProfileInput struct {
+	Bio      string   `validate:"max=500" json:"bio"`
+	Location string   `validate:"max=100" json:"location"`
+	Links    []string `validate:"max=5,dive,url,max=200" json:"links"`
+	UserUUID string
+}
+
+type ChangeBannerInput struct {
+	Image    []byte
+	UserUUID string
+}
+
+type GetPrivacyInput struct {
+	UserUUID string
+}
+
+// Fields left out keep their current values.
+type UpdatePrivacyInput struct {
+	Discoverable       *bool  `json:"discoverable"`
+	FriendRequests     string `validate:"omitempty,oneof=everyone friends-of-friends nobody" json:"friend_requests"`
+	PictureVisibility  string `validate:"omitempty,oneof=everyone friends nobody" json:"picture_visibility"`
+	ActivityVisibility string `validate:"omitempty,oneof=everyone friends nobody" json:"activity_visibility"`
+	UserUUID           string
+}
+
+type DeleteAccountInput struct {
+	Password string `validate:"required,min=8,max=72" json:"password"`
+	UserUUID string `json:"-"`
+}
+
+type ExportAccountInput struct {
+	UserUUID string
+}
+
 // Friends
 type GetFriendsInput struct {
 	UserUUID string
-	Offset   int `validate:"required" json:"offset"`
+	PageInput
 }
 
 type DeleteFriendInput struct {
@@ -84,15 +147,20 @@ type DeleteFriendInput struct {
 	User2UUID string `validate:"required,min=8,max=130" json:"id"`
 }
 
+type GetFriendSuggestionsInput struct {
+	UserUUID string `json:"-"`
+	PageInput
+}
+
 // Friend requests
 type GetFriendRequestsSentInput struct {
 	UserUUID string
-	Offset   int `validate:"required" json:"offset"`
+	PageInput
 }
 
 type GetFriendRequestsReceivedInput struct {
 	UserUUID string
-	Offset   int `validate:"required" json:"offset"`
+	PageInput
 }
 
 type AddFriendRequestInput struct {
@@ -116,10 +184,54 @@ type AcceptFriendRequestInput struct {
 	RecipientUUID string
 }
 
+// Blocks
+type GetBlockedUsersInput struct {
+	UserUUID string
+	PageInput
+}
+
+type BlockUserInput struct {
+	UserUUID    string
+	BlockedUUID string `validate:"required,min=8,max=130" json:"id"`
+}
+
+type UnblockUserInput struct {
+	UserUUID    string
+	BlockedUUID string `validate:"required,min=8,max=130" json:"id"`
+}
+
+// Follows
+type FollowInput struct {
+	UserUUID   string
+	FolloweeId string `validate:"required,min=3,max=130" json:"id"`
+}
+
+type UnfollowInput struct {
+	UserUUID   string
+	FolloweeId string `validate:"required,min=3,max=130" json:"id"`
+}
+
+type GetFollowsInput struct {
+	RequesterUUID string `json:"-"`
+	UserUUID      string `validate:"required,min=3,max=130" json:"id"`
+	PageInput
+}
+
+type GetFollowCountsInput struct {
+	RequesterUUID string `json:"-"`
+	UserUUID      string `validate:"required,min=3,max=130" json:"id"`
+}
+
+type GetFeedInput struct {
+	UserUUID string
+	PageInput
+}
+
 // Websocket
 type WebsocketPayload struct {
-	Action  string          `json:"action"`
-	Content json.RawMessage `json:"content"`
+	RequestId string          `json:"request_id"`
+	Action    string          `json:"action"`
+	Content   json.RawMessage `json:"content"`
 }
 
 // Chat
@@ -148,59 +260,163 @@ type DeleteMessageInput struct {
 	TimeId int64  `json:"id"`
 }
 
+type MuteChatInput struct {
+	UserId string `json:"-"`
+	ChatId string `json:"chat_id"`
+}
+
+type UnmuteChatInput struct {
+	UserId string `json:"-"`
+	ChatId string `json:"chat_id"`
+}
+
 type CheckStatusInput struct {
-	UserId string `json:"user_id"`
+	SenderId string `json:"-"`
+	// Id of the websocket request, echoed by the status update.
+	RequestId string `json:"-"`
+	UserId    string `json:"user_id"`
 }
 
 type UpdateStatusInput struct {
-	UserId string `json:"user_id"`
-	Status int    `json:"status"`
+	SenderId string `json:"-"`
+	UserId   string `json:"user_id"`
+	Status   int    `json:"status"`
 }
 
 // Songs
 type GetSongsInput struct {
-	Offset          int    `validate:"required" json:"offset"`
+	PageInput
 	SongNameFilter  string `validate:"max=100" json:"songname_filter"`
 	CreatorIdFilter string `validate:"max=130" json:"creatorid_filter"`
 }
 
 type GetSongInfoInput struct {
-	SongId string `json:"id"`
+	UserId   string `json:"-"`
+	UserRole string `json:"-"`
+	SongId   string `json:"id"`
 }
 
 type GetSongFileInput struct {
-	SongId string `json:"id"`
+	UserId   string `json:"-"`
+	UserRole string `json:"-"`
+	SongId   string `json:"id"`
 }
 
 type GetSongPictureInput struct {
-	SongId string `json:"id"`
+	UserId   string `json:"-"`
+	UserRole string `json:"-"`
+	SongId   string `json:"id"`
 }
 
 type GetSongChunkInput struct {
 	FileName string
+	UserId   string
+	UserRole string
 }
 
+type GetSongStreamUrlInput struct {
+	SongId   string `json:"id"`
+	UserId   string `json:"-"`
+	UserRole string `json:"-"`
+}
+
+// The query of a signed HLS file url along with the file name.
+type GetSignedSongChunkInput struct {
+	FileName  string `json:"-"`
+	UserId    string `json:"user"`
+	Expires   int64  `json:"expires"`
+	Signature string `json:"signature"`
+}
+
+// Empty metadata fields are taken from the tags of the file.
 type AddSongInput struct {
-	UserId string
-	Name   string `validate:"required,min=5,max=100" json:"name"`
-	File   []byte
+	UserId      string
+	UserRole    string `json:"-"`
+	Name        string `validate:"required,min=5,max=100" json:"name"`
+	Artist      string `validate:"max=100" json:"artist"`
+	Album       string `validate:"max=100" json:"album"`
+	TrackNumber int    `validate:"min=0,max=999" json:"track_number"`
+	Year        int    `validate:"min=0,max=9999" json:"year"`
+	Genre       string `validate:"max=50" json:"genre"`
+	AlbumId     string `json:"album_id"`
+	File        []byte
+}
+
+// Albums
+type GetAlbumsInput struct {
+	PageInput
+	AlbumNameFilter string `validate:"max=100" json:"albumname_filter"`
+	CreatorIdFilter string `validate:"max=130" json:"creatorid_filter"`
+	TypeFilter      string `validate:"omitempty,oneof=album ep single" json:"type_filter"`
+}
+
+type GetAlbumInfoInput struct {
+	AlbumId string `json:"id"`
+}
+
+type GetAlbumCoverInput struct {
+	AlbumId string `json:"id"`
+}
+
+type AddAlbumInput struct {
+	UserId      string
+	UserRole    string `json:"-"`
+	Name        string `validate:"required,min=1,max=100" json:"name"`
+	Type        string `validate:"required,oneof=album ep single" json:"type"`
+	ReleaseDate string `json:"release_date"`
+}
+
+type ChangeAlbumCoverInput struct {
+	UserId   string
+	UserRole string `json:"-"`
+	AlbumId  string `json:"id"`
+	Image    []byte
+}
+
+type DeleteAlbumInput struct {
+	UserId   string
+	UserRole string `json:"-"`
+	AlbumId  string `json:"id"`
+}
+
+// Songs of an album upload, in track order.
+type AddAlbumSongsInput struct {
+	UserId   string
+	UserRole string `json:"-"`
+	AlbumId  string `json:"id"`
+	Files    [][]byte
+}
+
+type GetSongWaveformInput struct {
+	UserId   string `json:"-"`
+	UserRole string `json:"-"`
+	SongId   string `json:"id"`
+	Format   string `json:"format"`
+}
+
+type GetUploadStatusInput struct {
+	UserId   string `json:"-"`
+	UserRole string `json:"-"`
+	JobId    string `json:"id"`
 }
 
 type UpdateSongNameInput struct {
-	UserId string
-	SongId string `json:"id"`
-	Name   string `validate:"required,min=5,max=100" json:"new_name"`
+	UserId   string
+	UserRole string `json:"-"`
+	SongId   string `json:"id"`
+	Name     string `validate:"required,min=5,max=100" json:"new_name"`
 }
 
 type DeleteSongInput struct {
-	UserId string
-	SongId string `json:"id"`
+	UserId   string
+	UserRole string `json:"-"`
+	SongId   string `json:"id"`
 }
 
 // Playlists
 type GetPlaylistsInput struct {
-	UserId             string
-	Offset             int    `validate:"required" json:"offset"`
+	UserId string
+	PageInput
 	PlaylistNameFilter string `validate:"max=100" json:"playlistname_filter"`
 }
 
@@ -211,35 +427,40 @@ type AddPlaylistInput struct {
 
 type UpdatePlaylistNameInput struct {
 	UserId     string
+	UserRole   string `json:"-"`
 	PlaylistId string `json:"id"`
 	Name       string `validate:"required,min=5,max=100" json:"new_name"`
 }
 
 type DeletePlaylistInput struct {
 	UserId     string
+	UserRole   string `json:"-"`
 	PlaylistId string `json:"id"`
 }
 
 type AddPlaylistSongInput struct {
 	UserId     string
+	UserRole   string `json:"-"`
 	PlaylistId string `json:"id"`
 	SongId     string `json:"song_id"`
 }
 
 type DeletePlaylistSongInput struct {
 	UserId     string
+	UserRole   string `json:"-"`
 	PlaylistId string `json:"id"`
 	SongId     string `json:"song_id"`
 }
 
 type GetPlaylistSongsInput struct {
 	UserId     string
+	UserRole   string `json:"-"`
 	PlaylistId string `json:"id"`
 }
 
 // Read Times
 type GetReadTimeInput struct {
-	UserId string `json:"user_id"`
+	UserId string `json:"-"`
 	ChatId string `json:"chat_id"`
 }
 
@@ -248,3 +469,59 @@ type UpdateReadTimeInput struct {
 	ChatId string `json:"chat_id"`
 	TimeId int64  `json:"time_id"`
 }
+
+// Moderation
+type ReportInput struct {
+	UserId     string `json:"-"`
+	TargetType string `validate:"required,oneof=song playlist user message" json:"target_type"`
+	TargetId   string `validate:"required,min=8,max=130" json:"target_id"`
+	MessageId  int64  `json:"message_id"`
+	Reason     string `validate:"required,min=1,max=1000" json:"reason"`
+}
+
+type GetReportsInput struct {
+	UserId   string `json:"-"`
+	UserRole string `json:"-"`
+	Status   string `validate:"omitempty,oneof=open resolved dismissed" json:"status"`
+	PageInput
+}
+
+type ResolveReportInput struct {
+	UserId     string `json:"-"`
+	UserRole   string `json:"-"`
+	ReportId   string `validate:"required,min=8,max=130" json:"id"`
+	Status     string `validate:"required,oneof=resolved dismissed" json:"status"`
+	Resolution string `validate:"max=1000" json:"resolution"`
+}
+
+// Used to hide, unhide, suspend and unsuspend,
+// Reason is recorded in the audit log.
+type ModerationInput struct {
+	UserId   string `json:"-"`
+	UserRole string `json:"-"`
+	TargetId string `validate:"required,min=8,max=130" json:"id"`
+	Reason   string `validate:"max=1000" json:"reason"`
+}
+
+type GetAuditLogInput struct {
+	UserId   string `json:"-"`
+	UserRole string `json:"-"`
+	PageInput
+}
+
+// Admin
+type AdminGetUsersInput struct {
+	UserRole string `json:"-"`
+	PageInput
+	Query     string `validate:"max=100" json:"query"`
+	Role      string `validate:"omitempty,oneof=user artist moderator admin" json:"role"`
+	Suspended *bool  `json:"suspended"`
+	Verified  *bool  `json:"verified"`
+}
+
+type SetRoleInput struct {
+	UserId   string `json:"-"`
+	UserRole string `json:"-"`
+	TargetId string `validate:"required,min=8,max=130" json:"id"`
+	Role     string `validate:"required,oneof=user artist moderator admin" json:"role"`
+}
@@ -0,0 +1,338 @@
+package usecases
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"log"
+	"math"
+	"spotigram/internal/customerrors"
+	"spotigram/internal/service/abstractions"
+	"spotigram/internal/service/models"
+	"spotigram/internal/utility"
+)
+
+// A use case to delete the user's own account with everything they own.
+// Expects access token deserialization beforehand.
+// Validates the passed uuid and password.
+// Returns the ids of the user's former friends.
+// May return ErrInvalidInput, ErrInternal, ErrNotFound on failure.
+func DeleteAccount(input models.DeleteAccountInput) ([]string, error) {
+	if check := utility.IsValidUUID(input.UserUUID); !check {
+		return nil, customerrors.InvalidField("uuid", "")
+	}
+	if !utility.IsValidStructField(input, "Password") {
+		return nil, customerrors.InvalidField("password", "must be 8-72 chars long")
+	}
+
+	passwordHash, err := abstractions.UserRepositoryInstance.GetPassword(input.UserUUID)
+	if err != nil {
+		return nil, err
+	}
+	err = utility.ValidatePassword([]byte(passwordHash), []byte(input.Password))
+	if err != nil {
+		return nil, &customerrors.ErrInvalidInput{
+			Message: "incorrect password"}
+	}
+
+	return deleteAccount(input.UserUUID)
+}
+
+// A use case to export everything stored about the user
+// as a zip archive of account.json, the user's picture
+// and the files of the user's songs.
+// Expects access token deserialization beforehand.
+// Validates the passed uuid.
+// May return ErrInvalidInput, ErrInternal, ErrNotFound on failure.
+func ExportAccount(input models.ExportAccountInput) ([]byte, error) {
+	if check := utility.IsValidUUID(input.UserUUID); !check {
+		return nil, customerrors.InvalidField("uuid", "")
+	}
+	uid := input.UserUUID
+
+	user, err := abstractions.UserRepositoryInstance.GetUser(uid)
+	if err != nil {
+		return nil, err
+	}
+	export := models.AccountExport{
+		User:      user,
+		Playlists: []models.PlaylistExport{},
+		Messages:  []models.Message{},
+	}
+
+	export.PublicKey, err = abstractions.UserRepositoryInstance.GetPublicKey(uid)
+	if err != nil {
+		return nil, err
+	}
+
+	export.Privacy, err = abstractions.UserRepositoryInstance.GetPrivacy(uid)
+	if err != nil {
+		return nil, err
+	}
+	export.Listens, err = collectCursorPages(func(cursor string, limit int) (*models.Page[models.LastStream], error) {
+		return abstractions.ListenRepositoryInstance.GetListens(uid, cursor, limit)
+	})
+	if err != nil {
+		return nil, err
+	}
+	export.Following, err = collectCursorPages(func(cursor string, limit int) (*models.Page[models.Follow], error) {
+		return abstractions.FollowRepositoryInstance.GetFollowing(uid, cursor, limit)
+	})
+	if err != nil {
+		return nil, err
+	}
+	export.Followers, err = collectCursorPages(func(cursor string, limit int) (*models.Page[models.Follow], error) {
+		return abstractions.FollowRepositoryInstance.GetFollowers(uid, cursor, limit)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	export.Friends, err = collectCursorPages(func(cursor string, limit int) (*models.Page[models.Friend], error) {
+		return abstractions.FriendRepositoryInstance.GetFriends(uid, cursor, limit)
+	})
+	if err != nil {
+		return nil, err
+	}
+	export.FriendRequestsSent, err = collectCursorPages(func(cursor string, limit int) (*models.Page[models.FriendRequest], error) {
+		return abstractions.FriendRequestRepositoryInstance.GetFriendRequestsSent(uid, cursor, limit)
+	})
+	if err != nil {
+		return nil, err
+	}
+	export.FriendRequestsReceived, err = collectCursorPages(func(cursor string, limit int) (*models.Page[models.FriendRequest], error) {
+		return abstractions.FriendRequestRepositoryInstance.GetFriendRequestsReceived(uid, cursor, limit)
+	})
+	if err != nil {
+		return nil, err
+	}
+	export.Blocks, err = collectCursorPages(func(cursor string, limit int) (*models.Page[models.Block], error) {
+		return abstractions.BlockRepositoryInstance.GetBlocks(uid, cursor, limit)
+	})
+	if err != nil {
+		return nil, err
+	}
+	export.Reports, err = collectCursorPages(func(cursor string, limit int) (*models.Page[models.Report], error) {
+		return abstractions.ReportRepositoryInstance.GetReportsByReporter(uid, cursor, limit)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	playlists, err := collectCursorPages(func(cursor string, limit int) (*models.Page[models.Playlist], error) {
+		return abstractions.PlaylistRepositoryInstance.GetPlaylists(uid, cursor, limit, "")
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range playlists {
+		playlist := models.PlaylistExport{Playlist: p, SongIds: []string{}}
+		songs, err := abstractions.PlaylistSongRepositoryInstance.GetPlaylistSongs(p.Id)
+		if err != nil && !customerrors.IsNotFound(err) {
+			return nil, err
+		}
+		for _, s := range songs {
+			playlist.SongIds = append(playlist.SongIds, s.SongId)
+		}
+		export.Playlists = append(export.Playlists, playlist)
+	}
+
+	export.Songs, err = collectCursorPages(func(cursor string, limit int) (*models.Page[models.Song], error) {
+		return abstractions.SongRepositoryInstance.GetSongsByCreator(uid, cursor, limit)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	export.Albums, err = collectCursorPages(func(cursor string, limit int) (*models.Page[models.Album], error) {
+		return abstractions.AlbumRepositoryInstance.GetAlbums(cursor, limit, "", uid, "")
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, f := range export.Friends {
+		timeId := int64(math.MaxInt64)
+		for {
+			messages, err := abstractions.ChatRepositoryInstance.GetMessages(f.ChatId, timeId)
+			if customerrors.IsNotFound(err) {
+				break
+			} else if err != nil {
+				return nil, err
+			}
+			export.Messages = append(export.Messages, messages...)
+			timeId = messages[len(messages)-1].TimeId
+		}
+	}
+
+	buf := new(bytes.Buffer)
+	archive := zip.NewWriter(buf)
+
+	account, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		return nil, &customerrors.ErrInternal{Message: err.Error()}
+	}
+	if err := addZipFile(archive, "account.json", account); err != nil {
+		return nil, err
+	}
+
+	picture, err := readBlob(userPictureKey(uid))
+	if err != nil {
+		return nil, err
+	}
+	if err := addZipFile(archive, "picture.webp", picture); err != nil {
+		return nil, err
+	}
+
+	banner, err := readBlob(userBannerKey(uid))
+	if err != nil {
+		return nil, err
+	}
+	if err := addZipFile(archive, "banner.webp", banner); err != nil {
+		return nil, err
+	}
+
+	for _, s := range export.Songs {
+		file, err := readBlob(songFileKey(s.Id))
+		if err != nil {
+			return nil, err
+		}
+		format, ok := utility.DetectAudioFormat(file)
+		if !ok {
+			format = utility.AudioFormatMP3
+		}
+		if err := addZipFile(archive, "songs/"+s.Id+"."+format, file); err != nil {
+			return nil, err
+		}
+
+		picture, err := readBlob(songPictureKey(s.Id))
+		if err != nil {
+			return nil, err
+		}
+		if err := addZipFile(archive, "songs/"+s.Id+".webp", picture); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, a := range export.Albums {
+		cover, err := readBlob(albumCoverKey(a.Id))
+		if err != nil {
+			return nil, err
+		}
+		if err := addZipFile(archive, "albums/"+a.Id+".webp", cover); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return nil, &customerrors.ErrInternal{Message: err.Error()}
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Writes a file to the archive, empty files are skipped.
+func addZipFile(archive *zip.Writer, name string, content []byte) error {
+	if len(content) == 0 {
+		return nil
+	}
+	w, err := archive.Create(name)
+	if err != nil {
+		return &customerrors.ErrInternal{Message: err.Error()}
+	}
+	if _, err := w.Write(content); err != nil {
+		return &customerrors.ErrInternal{Message: err.Error()}
+	}
+	return nil
+}
+
+// Deletes a user together with their friendships, chats, friend requests,
+// blocks, follows, playlists, songs, albums, listens and reports, then revokes their sessions.
+// The database rows go in one transaction, the data kept elsewhere
+// is cleaned up afterwards and retried by RunAccountCleanups on failure.
+// Returns the ids of the user's former friends.
+// UUID validation is not provided.
+// May return ErrInternal, ErrNotFound on failure.
+func deleteAccount(userId string) ([]string, error) {
+	friendIds, err := abstractions.AccountRepositoryInstance.DeleteAccount(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	// The account is gone either way, leftovers are retried later
+	if err := RunAccountCleanups(); err != nil {
+		log.Printf("account cleanup of %s failed: %v", userId, err)
+	}
+	return friendIds, nil
+}
+
+// Deletes the data of deleted accounts kept outside of the sql database,
+// chat messages, playlist songs, song chunks and files, pictures and sessions.
+// Every cleanup is idempotent and only forgotten once done,
+// so the failed ones are retried by the next run.
+// May return ErrInternal on failure.
+func RunAccountCleanups() error {
+	for {
+		cleanups, err := abstractions.AccountRepositoryInstance.GetAccountCleanups(100)
+		if err != nil {
+			return err
+		}
+		if len(cleanups) == 0 {
+			return nil
+		}
+
+		var firstErr error
+		for _, c := range cleanups {
+			err := runAccountCleanup(c)
+			if err == nil {
+				err = abstractions.AccountRepositoryInstance.DeleteAccountCleanup(c)
+			}
+			if err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+		// The failed cleanups would be fetched again right away
+		if firstErr != nil {
+			return firstErr
+		}
+	}
+}
+
+// Deletes the data of a single cleanup, missing data is ignored.
+// May return ErrInternal on failure.
+func runAccountCleanup(c models.AccountCleanup) error {
+	var err error
+	switch c.Kind {
+	case models.AccountCleanupChat:
+		err = abstractions.ChatRepositoryInstance.DeleteChat(c.TargetId)
+
+	case models.AccountCleanupPlaylist:
+		err = abstractions.PlaylistSongRepositoryInstance.DeletePlaylistSongs(c.TargetId)
+
+	case models.AccountCleanupSong:
+		err = abstractions.PlaylistSongRepositoryInstance.DeleteSong(c.TargetId)
+		if err == nil || customerrors.IsNotFound(err) {
+			err = abstractions.SongChunkRepositoryInstance.DeleteSongChunks(c.TargetId)
+		}
+		if err == nil || customerrors.IsNotFound(err) {
+			err = deleteSongBlobs(c.TargetId)
+		}
+
+	case models.AccountCleanupAlbum:
+		err = abstractions.BlobStoreInstance.Delete(albumCoverKey(c.TargetId))
+
+	case models.AccountCleanupUser:
+		err = abstractions.BlobStoreInstance.Delete(userPictureKey(c.TargetId))
+		if err == nil {
+			err = abstractions.BlobStoreInstance.Delete(userBannerKey(c.TargetId))
+		}
+		if err == nil {
+			_, err = abstractions.JWTCacheInstance.DeleteUserTokens(c.TargetId)
+		}
+	}
+
+	if customerrors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,198 @@
+package usecases
+
+import (
+	"spotigram/internal/customerrors"
+	"spotigram/internal/service/abstractions"
+	"spotigram/internal/service/models"
+	"spotigram/internal/utility"
+)
+
+// A use case to get a page of users searched by name or email, role,
+// suspension and verification.
+// Expects access token deserialization beforehand, admins only.
+// May return ErrInvalidInput, ErrForbidden, ErrInternal, ErrNotFound on failure.
+func AdminGetUsers(input models.AdminGetUsersInput) (*models.Page[models.User], error) {
+	if err := requireRole(input.UserRole, models.RoleAdmin); err != nil {
+		return nil, err
+	}
+	limit, err := pageLimit(input.Limit)
+	if err != nil {
+		return nil, err
+	}
+	if !utility.IsValidStructField(input, "Query") {
+		return nil, customerrors.InvalidField("query", "under 100 chars")
+	}
+	if !utility.IsValidStructField(input, "Role") {
+		return nil, customerrors.InvalidField("role", "user, artist, moderator or admin")
+	}
+
+	return abstractions.UserRepositoryInstance.SearchUsers(models.UserFilter{
+		Query:     input.Query,
+		Role:      input.Role,
+		Suspended: input.Suspended,
+		Verified:  input.Verified,
+	}, input.Cursor, limit)
+}
+
+// A use case to view a user with their songs, playlists and friend count.
+// Expects access token deserialization beforehand, admins only.
+// May return ErrInvalidInput, ErrForbidden, ErrInternal, ErrNotFound on failure.
+func AdminGetUserOverview(input models.ModerationInput) (*models.UserOverview, error) {
+	if err := validateAdminInput(input); err != nil {
+		return nil, err
+	}
+
+	user, err := abstractions.UserRepositoryInstance.GetUser(input.TargetId)
+	if err != nil {
+		return nil, err
+	}
+	overview := models.UserOverview{
+		User:      user,
+		Songs:     []models.Song{},
+		Playlists: []models.Playlist{},
+	}
+
+	songs, err := abstractions.SongRepositoryInstance.GetSongsByCreator(input.TargetId, "", maxPageSize)
+	if err != nil && !customerrors.IsNotFound(err) {
+		return nil, err
+	} else if err == nil {
+		overview.Songs = songs.Items
+	}
+
+	playlists, err := abstractions.PlaylistRepositoryInstance.GetPlaylists(input.TargetId, "", maxPageSize, "")
+	if err != nil && !customerrors.IsNotFound(err) {
+		return nil, err
+	} else if err == nil {
+		overview.Playlists = playlists.Items
+	}
+
+	overview.FriendCount, err = abstractions.FriendRepositoryInstance.CountFriends(input.TargetId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &overview, nil
+}
+
+// A use case to mark a user as verified without the email flow.
+// Expects access token deserialization beforehand, admins only.
+// May return ErrInvalidInput, ErrForbidden, ErrInternal, ErrNotFound on failure.
+func AdminVerifyUser(input models.ModerationInput) error {
+	if err := validateAdminInput(input); err != nil {
+		return err
+	}
+
+	err := abstractions.UserRepositoryInstance.UpdateVerified(input.TargetId, true)
+	if err != nil {
+		return err
+	}
+
+	return recordModeration(input.UserId, "verify-user",
+		models.ReportTargetUser, input.TargetId, input.Reason)
+}
+
+// A use case to replace a user's password with a random one
+// and revoke their sessions.
+// Expects access token deserialization beforehand, admins only.
+// Returns the new password.
+// May return ErrInvalidInput, ErrForbidden, ErrInternal, ErrNotFound on failure.
+func AdminResetPassword(input models.ModerationInput) (string, error) {
+	if err := validateAdminInput(input); err != nil {
+		return "", err
+	}
+
+	password := utility.GenerateRandomPassword()
+	hash, err := utility.HashPassword(password)
+	if err != nil {
+		return "", &customerrors.ErrInternal{Message: err.Error()}
+	}
+
+	err = abstractions.UserRepositoryInstance.UpdatePassword(input.TargetId, hash)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = abstractions.JWTCacheInstance.DeleteUserTokens(input.TargetId)
+	if err != nil {
+		return "", err
+	}
+
+	err = recordModeration(input.UserId, "reset-password",
+		models.ReportTargetUser, input.TargetId, input.Reason)
+	if err != nil {
+		return "", err
+	}
+
+	return password, nil
+}
+
+// A use case to sign a user out of every session.
+// Expects access token deserialization beforehand, admins only.
+// Returns the number of revoked tokens.
+// May return ErrInvalidInput, ErrForbidden, ErrInternal, ErrNotFound on failure.
+func AdminRevokeSessions(input models.ModerationInput) (int64, error) {
+	if err := validateAdminInput(input); err != nil {
+		return 0, err
+	}
+
+	exists, err := abstractions.UserRepositoryInstance.DoesUserExist(input.TargetId)
+	if err != nil {
+		return 0, err
+	}
+	if !exists {
+		return 0, &customerrors.ErrNotFound{
+			Message: "user not found"}
+	}
+
+	revoked, err := abstractions.JWTCacheInstance.DeleteUserTokens(input.TargetId)
+	if err != nil {
+		return 0, err
+	}
+
+	return revoked, recordModeration(input.UserId, "revoke-sessions",
+		models.ReportTargetUser, input.TargetId, input.Reason)
+}
+
+// A use case to permanently delete an account with its content.
+// Expects access token deserialization beforehand, admins only.
+// Returns the ids of the user's former friends.
+// May return ErrInvalidInput, ErrForbidden, ErrInternal, ErrNotFound on failure.
+func AdminDeleteUser(input models.ModerationInput) ([]string, error) {
+	if err := validateAdminInput(input); err != nil {
+		return nil, err
+	}
+	if input.TargetId == input.UserId {
+		return nil, &customerrors.ErrInvalidInput{
+			Message: "cannot delete yourself"}
+	}
+
+	user, err := abstractions.UserRepositoryInstance.GetUser(input.TargetId)
+	if err != nil {
+		return nil, err
+	}
+	if user.Role == models.RoleAdmin {
+		return nil, &customerrors.ErrForbidden{
+			Message: "cannot delete an admin, change their role first"}
+	}
+
+	friendIds, err := deleteAccount(input.TargetId)
+	if err != nil {
+		return nil, err
+	}
+
+	return friendIds, recordModeration(input.UserId, "delete-user",
+		models.ReportTargetUser, input.TargetId, input.Reason)
+}
+
+func validateAdminInput(input models.ModerationInput) error {
+	if err := requireRole(input.UserRole, models.RoleAdmin); err != nil {
+		return err
+	}
+	if check := utility.IsValidUUID(input.TargetId); !check {
+		return customerrors.InvalidField("id", "")
+	}
+	if !utility.IsValidStructField(input, "Reason") {
+		return customerrors.InvalidField("reason", "under 1000 chars")
+	}
+	return nil
+}
@@ -0,0 +1,220 @@
+package usecases
+
+import (
+	"fmt"
+	"spotigram/internal/customerrors"
+	"spotigram/internal/service/abstractions"
+	"spotigram/internal/service/models"
+	"spotigram/internal/utility"
+	"time"
+)
+
+// A use case to get the albums list, newest releases first.
+// May return ErrInvalidInput, ErrInternal, ErrNotFound on failure.
+func GetAlbums(input models.GetAlbumsInput) (*models.Page[models.Album], error) {
+	limit, err := pageLimit(input.Limit)
+	if err != nil {
+		return nil, err
+	}
+	if !utility.IsValidStructField(input, "AlbumNameFilter") {
+		return nil, customerrors.InvalidField("albumname_filter", "under 100 chars")
+	}
+	if input.CreatorIdFilter != "" {
+		if check := utility.IsValidUUID(input.CreatorIdFilter); !check {
+			return nil, customerrors.InvalidField("creatorid_filter", "")
+		}
+	}
+	if !utility.IsValidStructField(input, "TypeFilter") {
+		return nil, customerrors.InvalidField("type_filter", "album, ep or single")
+	}
+
+	return abstractions.AlbumRepositoryInstance.GetAlbums(
+		input.Cursor, limit, input.AlbumNameFilter,
+		input.CreatorIdFilter, input.TypeFilter)
+}
+
+// A use case to get an album with its track list.
+// Validates the passed uuid.
+// May return ErrInvalidInput, ErrInternal, ErrNotFound on failure.
+func GetAlbumInfo(input models.GetAlbumInfoInput) (*models.AlbumInfo, error) {
+	if check := utility.IsValidUUID(input.AlbumId); !check {
+		return nil, customerrors.InvalidField("id", "")
+	}
+
+	album, err := abstractions.AlbumRepositoryInstance.GetAlbum(input.AlbumId)
+	if err != nil {
+		return nil, err
+	}
+
+	tracks, err := abstractions.AlbumRepositoryInstance.GetAlbumTracks(input.AlbumId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.AlbumInfo{Album: *album, Tracks: tracks}, nil
+}
+
+// A use case to get the cover of an album.
+// Validates the passed uuid.
+// May return ErrInvalidInput, ErrInternal, ErrNotFound on failure.
+func GetAlbumCover(input models.GetAlbumCoverInput) ([]byte, error) {
+	if check := utility.IsValidUUID(input.AlbumId); !check {
+		return nil, customerrors.InvalidField("id", "")
+	}
+	if _, err := abstractions.AlbumRepositoryInstance.GetAlbum(input.AlbumId); err != nil {
+		return nil, err
+	}
+
+	cover, err := readBlob(albumCoverKey(input.AlbumId))
+	if err != nil {
+		return nil, err
+	}
+	if len(cover) == 0 {
+		return nil, &customerrors.ErrNotFound{Message: "cover not found"}
+	}
+	return cover, nil
+}
+
+// A use case to create an album, EP or single,
+// the release date defaults to today.
+// Expects access token deserialization beforehand.
+// Returns the created album.
+// May return ErrInvalidInput, ErrForbidden, ErrInternal on failure.
+func AddAlbum(input models.AddAlbumInput) (*models.Album, error) {
+	if check := utility.IsValidUUID(input.UserId); !check {
+		return nil, customerrors.InvalidField("uuid", "")
+	}
+	if err := requireRole(input.UserRole, models.RoleArtist, models.RoleAdmin); err != nil {
+		return nil, err
+	}
+	if !utility.IsValidStructField(input, "Name") {
+		return nil, customerrors.InvalidField("name", "must be 1-100 chars long")
+	}
+	if !utility.IsValidStructField(input, "Type") {
+		return nil, customerrors.InvalidField("type", "album, ep or single")
+	}
+
+	now := time.Now().UTC()
+	if input.ReleaseDate == "" {
+		input.ReleaseDate = now.Format("2006-01-02")
+	} else if _, err := time.Parse("2006-01-02", input.ReleaseDate); err != nil {
+		return nil, customerrors.InvalidField("release_date", "YYYY-MM-DD")
+	}
+
+	album := models.Album{
+		Id:          utility.GenerateUUID(),
+		CreatorId:   input.UserId,
+		Name:        input.Name,
+		Type:        input.Type,
+		ReleaseDate: input.ReleaseDate,
+		CreatedAt:   now,
+	}
+
+	err := abstractions.AlbumRepositoryInstance.AddAlbum(album)
+	if err != nil {
+		return nil, err
+	}
+	return &album, nil
+}
+
+// A use case to change the cover of an album (raw bytes).
+// Expects access token deserialization beforehand.
+// Validates the passed uuid and image.
+// May return ErrInvalidInput, ErrForbidden, ErrInternal, ErrNotFound on failure.
+func ChangeAlbumCover(input models.ChangeAlbumCoverInput) error {
+	album, err := getOwnAlbum(input.UserId, input.UserRole, input.AlbumId)
+	if err != nil {
+		return err
+	}
+
+	if len(input.Image) == 0 || len(input.Image) > 5*1024*1024 {
+		return &customerrors.ErrInvalidInput{
+			Message: "invalid image, must be a png, a webp or a jpg (under 5 megabytes)"}
+	}
+
+	imageWebP, err := utility.ConvertAndResizeImageToWebP(input.Image, 512, 512)
+	if err != nil {
+		return &customerrors.ErrInvalidInput{
+			Message: "invalid image, must be a png, a webp or a jpg (under 5 megabytes)"}
+	}
+
+	return putBlob(albumCoverKey(album.Id), imageWebP)
+}
+
+// A use case to delete an album, its songs are kept without an album.
+// Expects access token deserialization beforehand.
+// Validates the passed uuid.
+// May return ErrInvalidInput, ErrForbidden, ErrInternal, ErrNotFound on failure.
+func DeleteAlbum(input models.DeleteAlbumInput) error {
+	album, err := getOwnAlbum(input.UserId, input.UserRole, input.AlbumId)
+	if err != nil {
+		return err
+	}
+	if err := abstractions.AlbumRepositoryInstance.DeleteAlbum(album.Id); err != nil {
+		return err
+	}
+	return abstractions.BlobStoreInstance.Delete(albumCoverKey(album.Id))
+}
+
+// A use case to upload several songs into an album at once,
+// each song gets its own upload job. Every file is validated
+// before any of them is queued, and either all of them are
+// queued or none. Batches larger than the ingestion queue
+// are rejected.
+// Expects access token deserialization beforehand.
+// Returns the queued upload jobs, in the order of the files.
+// May return ErrInvalidInput, ErrForbidden, ErrInternal, ErrNotFound on failure.
+func AddAlbumSongs(input models.AddAlbumSongsInput) ([]models.UploadJob, error) {
+	if _, err := getOwnAlbum(input.UserId, input.UserRole, input.AlbumId); err != nil {
+		return nil, err
+	}
+	maxFiles := 100
+	if capacity := ingestionQueueCapacity(); capacity > 0 && capacity < maxFiles {
+		maxFiles = capacity
+	}
+	if len(input.Files) == 0 || len(input.Files) > maxFiles {
+		return nil, customerrors.InvalidField("files",
+			fmt.Sprintf("must be 1-%d files", maxFiles))
+	}
+
+	tasks := []ingestionTask{}
+	for i, file := range input.Files {
+		task, err := prepareSongUpload(models.AddSongInput{
+			UserId:   input.UserId,
+			UserRole: input.UserRole,
+			AlbumId:  input.AlbumId,
+			File:     file,
+		})
+		if invalid, ok := err.(*customerrors.ErrInvalidInput); ok {
+			invalid.Message = fmt.Sprintf("file %d: %s", i+1, invalid.Message)
+			return nil, invalid
+		} else if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, *task)
+	}
+
+	return queueSongUploads(tasks)
+}
+
+// Returns an album the user created, any album for admins.
+// May return ErrInvalidInput, ErrForbidden, ErrInternal, ErrNotFound on failure.
+func getOwnAlbum(userId string, role string, albumId string) (*models.Album, error) {
+	if check := utility.IsValidUUID(userId); !check {
+		return nil, customerrors.InvalidField("uuid", "")
+	}
+	if check := utility.IsValidUUID(albumId); !check {
+		return nil, customerrors.InvalidField("id", "")
+	}
+
+	album, err := abstractions.AlbumRepositoryInstance.GetAlbum(albumId)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isOwnerOrAdmin(album.CreatorId, userId, role) {
+		return nil, &customerrors.ErrForbidden{
+			Message: "you are not the creator of the album"}
+	}
+	return album, nil
+}
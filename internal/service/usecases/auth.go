@@ -62,6 +62,7 @@ func SignUpUser(sui models.SignUpInput) (e error) {
 		Email:    sui.Email,
 		Password: hashedPassword,
 		Verified: false,
+		Role:     models.RoleUser,
 	})
 
 	if err != nil {
@@ -81,14 +82,12 @@ func SignInUser(sii models.SignInInput, cfg *config.Config) (
 
 	valid := utility.IsValidStructField(sii, "Email")
 	if !valid {
-		return "", nil, nil, &customerrors.ErrInvalidInput{
-			Message: "invalid \"email\" (must be 5-100 chars long)"}
+		return "", nil, nil, customerrors.InvalidField("email", "must be 5-100 chars long")
 	}
 
 	valid = utility.IsValidStructField(sii, "Password")
 	if !valid {
-		return "", nil, nil, &customerrors.ErrInvalidInput{
-			Message: "invalid \"password\" (must be 8-72 chars long)"}
+		return "", nil, nil, customerrors.InvalidField("password", "must be 8-72 chars long")
 	}
 
 	uuid, passwordHash, err :=
@@ -103,14 +102,23 @@ func SignInUser(sii models.SignInInput, cfg *config.Config) (
 			Message: "incorrect password"}
 	}
 
+	user, err := abstractions.UserRepositoryInstance.GetUser(uuid)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	if user.Suspended {
+		return "", nil, nil, &customerrors.ErrUnauthorized{
+			Message: "account is suspended"}
+	}
+
 	accessTD, err = utility.CreateToken(
-		uuid, cfg.AccessToken.ExpiresIn, cfg.AccessToken.PrivateKey)
+		uuid, user.Role, cfg.AccessToken.ExpiresIn, cfg.AccessToken.PrivateKey)
 	if err != nil {
 		return "", nil, nil, &customerrors.ErrInternal{Message: err.Error()}
 	}
 
 	refreshTD, err = utility.CreateToken(
-		uuid, cfg.RefreshToken.ExpiresIn, cfg.RefreshToken.PrivateKey)
+		uuid, user.Role, cfg.RefreshToken.ExpiresIn, cfg.RefreshToken.PrivateKey)
 	if err != nil {
 		return "", nil, nil, &customerrors.ErrInternal{Message: err.Error()}
 	}
@@ -190,18 +198,13 @@ func RefreshAccessToken(rati models.RefreshAccessTokenInput, cfg *config.Config)
 		return nil, &customerrors.ErrNotFound{
 			Message: "invalid \"uuid\""}
 	}
-	exists, err :=
-		abstractions.UserRepositoryInstance.DoesUserExist(userUuid)
+	user, err := getActiveUser(userUuid)
 	if err != nil {
 		return nil, err
 	}
-	if !exists {
-		return nil, &customerrors.ErrNotFound{
-			Message: "user belonging to this token no longer exists"}
-	}
 
 	accessTokenDetails, err := utility.CreateToken(
-		userUuid, cfg.AccessToken.ExpiresIn, cfg.AccessToken.PrivateKey)
+		userUuid, user.Role, cfg.AccessToken.ExpiresIn, cfg.AccessToken.PrivateKey)
 	if err != nil {
 		return nil, &customerrors.ErrInternal{Message: err.Error()}
 	}
@@ -0,0 +1,194 @@
+package usecases
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"spotigram/internal/customerrors"
+	"spotigram/internal/service/abstractions"
+)
+
+// Returns the blob store key of the file of a song.
+func songFileKey(songId string) string {
+	return "songs/" + songId + "/file"
+}
+
+// Returns the blob store key of the picture of a song.
+func songPictureKey(songId string) string {
+	return "songs/" + songId + "/picture"
+}
+
+// Returns the blob store key of the picture of a user.
+func userPictureKey(userId string) string {
+	return "users/" + userId + "/picture"
+}
+
+// Returns the blob store key of the banner of a user.
+func userBannerKey(userId string) string {
+	return "users/" + userId + "/banner"
+}
+
+// Returns the blob store key of the cover of an album.
+func albumCoverKey(albumId string) string {
+	return "albums/" + albumId + "/cover"
+}
+
+// Stores a blob held in memory.
+// May return ErrInternal on failure.
+func putBlob(key string, data []byte) error {
+	return abstractions.BlobStoreInstance.Put(key, bytes.NewReader(data), int64(len(data)))
+}
+
+// Reads a whole blob into memory, missing blobs are returned as nil.
+// May return ErrInternal on failure.
+func readBlob(key string) ([]byte, error) {
+	r, _, err := abstractions.BlobStoreInstance.Get(key)
+	if customerrors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, &customerrors.ErrInternal{Message: err.Error()}
+	}
+	return data, nil
+}
+
+// Writes a blob into a new file at the path without holding it in memory.
+// May return ErrInternal or ErrNotFound on failure.
+func copyBlobToFile(key string, path string) error {
+	r, _, err := abstractions.BlobStoreInstance.Get(key)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	f, err := os.Create(path)
+	if err != nil {
+		return &customerrors.ErrInternal{Message: err.Error()}
+	}
+	_, err = io.Copy(f, r)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return &customerrors.ErrInternal{Message: err.Error()}
+	}
+	return nil
+}
+
+// Deletes the file and the picture of a song from the blob store.
+// May return ErrInternal on failure.
+func deleteSongBlobs(songId string) error {
+	if err := abstractions.BlobStoreInstance.Delete(songFileKey(songId)); err != nil {
+		return err
+	}
+	return abstractions.BlobStoreInstance.Delete(songPictureKey(songId))
+}
+
+// Moves the song files, song pictures, user pictures, user banners
+// and album covers left in the database by versions before
+// the blob store into the blob store, then drops their columns.
+// Safe to run on every start.
+// May return ErrInternal on failure.
+func MigrateBlobs() error {
+	err := migrateStoredBlobs(abstractions.SongRepositoryInstance.GetSongIdsWithStoredBlobs,
+		func(id string) error {
+			picture, file, err := abstractions.SongRepositoryInstance.GetStoredBlobs(id)
+			if err != nil {
+				return err
+			}
+			if file != nil {
+				if err := putBlob(songFileKey(id), file); err != nil {
+					return err
+				}
+			}
+			if picture != nil {
+				if err := putBlob(songPictureKey(id), picture); err != nil {
+					return err
+				}
+			}
+			return abstractions.SongRepositoryInstance.ClearStoredBlobs(id)
+		})
+	if err != nil {
+		return err
+	}
+
+	err = migrateStoredBlobs(abstractions.UserRepositoryInstance.GetUserIdsWithStoredPicture,
+		func(id string) error {
+			picture, err := abstractions.UserRepositoryInstance.GetStoredPicture(id)
+			if err != nil {
+				return err
+			}
+			if err := putBlob(userPictureKey(id), picture); err != nil {
+				return err
+			}
+			return abstractions.UserRepositoryInstance.ClearStoredPicture(id)
+		})
+	if err != nil {
+		return err
+	}
+
+	err = migrateStoredBlobs(abstractions.UserRepositoryInstance.GetUserIdsWithStoredBanner,
+		func(id string) error {
+			banner, err := abstractions.UserRepositoryInstance.GetStoredBanner(id)
+			if err != nil {
+				return err
+			}
+			if err := putBlob(userBannerKey(id), banner); err != nil {
+				return err
+			}
+			return abstractions.UserRepositoryInstance.ClearStoredBanner(id)
+		})
+	if err != nil {
+		return err
+	}
+
+	err = migrateStoredBlobs(abstractions.AlbumRepositoryInstance.GetAlbumIdsWithStoredCover,
+		func(id string) error {
+			cover, err := abstractions.AlbumRepositoryInstance.GetStoredCover(id)
+			if err != nil {
+				return err
+			}
+			if err := putBlob(albumCoverKey(id), cover); err != nil {
+				return err
+			}
+			return abstractions.AlbumRepositoryInstance.ClearStoredCover(id)
+		})
+	if err != nil {
+		return err
+	}
+
+	if err := abstractions.SongRepositoryInstance.DropStoredBlobColumns(); err != nil {
+		return err
+	}
+	if err := abstractions.UserRepositoryInstance.DropStoredBlobColumns(); err != nil {
+		return err
+	}
+	return abstractions.AlbumRepositoryInstance.DropStoredBlobColumns()
+}
+
+// Calls migrate on every id returned by getIds, 100 ids at a time.
+// May return ErrInternal on failure.
+func migrateStoredBlobs(getIds func(afterId string, limit int) ([]string, error), migrate func(id string) error) error {
+	afterId := ""
+	for {
+		ids, err := getIds(afterId, 100)
+		if err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+
+		for _, id := range ids {
+			if err := migrate(id); err != nil {
+				return err
+			}
+		}
+		afterId = ids[len(ids)-1]
+	}
+}
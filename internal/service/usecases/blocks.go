@@ -0,0 +1,106 @@
+package usecases
+
+import (
+	"spotigram/internal/customerrors"
+	"spotigram/internal/service/abstractions"
+	"spotigram/internal/service/models"
+	"spotigram/internal/utility"
+)
+
+// A use case to get a page of users blocked by the current user.
+// Expects access token deserialization beforehand.
+// Validates the passed uuid.
+// May return ErrInvalidInput, ErrInternal, ErrNotFound on failure.
+func GetBlockedUsers(input models.GetBlockedUsersInput) (*models.Page[models.Block], error) {
+	if check := utility.IsValidUUID(input.UserUUID); !check {
+		return nil, customerrors.InvalidField("uuid", "")
+	}
+	limit, err := pageLimit(input.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	blocks, err := abstractions.BlockRepositoryInstance.
+		GetBlocks(input.UserUUID, input.Cursor, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return blocks, nil
+}
+
+// A use case to block a user.
+// Pending friend requests between the users are deleted.
+// Expects access token deserialization beforehand.
+// Validates the passed uuid.
+// May return ErrInvalidInput, ErrInternal on failure.
+func BlockUser(input models.BlockUserInput) error {
+	if check := utility.IsValidUUID(input.UserUUID); !check {
+		return customerrors.InvalidField("uuid", "")
+	}
+	if check := utility.IsValidUUID(input.BlockedUUID); !check {
+		return customerrors.InvalidField("id", "")
+	}
+
+	if input.UserUUID == input.BlockedUUID {
+		return &customerrors.ErrInvalidInput{
+			Message: "cannot block yourself"}
+	}
+
+	err := abstractions.BlockRepositoryInstance.AddBlock(models.Block{
+		BlockerId: input.UserUUID,
+		BlockedId: input.BlockedUUID,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = deleteFriendRequestIfExists(input.UserUUID, input.BlockedUUID)
+	if err != nil {
+		return err
+	}
+
+	return deleteFriendRequestIfExists(input.BlockedUUID, input.UserUUID)
+}
+
+// A use case to unblock a user.
+// Expects access token deserialization beforehand.
+// Validates the passed uuid.
+// May return ErrInvalidInput, ErrInternal, ErrNotFound on failure.
+func UnblockUser(input models.UnblockUserInput) error {
+	if check := utility.IsValidUUID(input.UserUUID); !check {
+		return customerrors.InvalidField("uuid", "")
+	}
+	if check := utility.IsValidUUID(input.BlockedUUID); !check {
+		return customerrors.InvalidField("id", "")
+	}
+
+	return abstractions.BlockRepositoryInstance.
+		DeleteBlock(input.UserUUID, input.BlockedUUID)
+}
+
+// Returns err if either of the users blocked the other, nil otherwise.
+// UUID validation is not provided.
+// May return ErrInternal on failure.
+func checkNotBlocked(uuid1, uuid2 string, err error) error {
+	blocked, repoErr := abstractions.BlockRepositoryInstance.
+		IsEitherBlocked(uuid1, uuid2)
+	if repoErr != nil {
+		return repoErr
+	}
+	if blocked {
+		return err
+	}
+	return nil
+}
+
+// Deletes a friend request, a missing one is not an error.
+func deleteFriendRequestIfExists(senderUUID, recipientUUID string) error {
+	err := abstractions.FriendRequestRepositoryInstance.
+		DeleteFriendRequest(senderUUID, recipientUUID)
+
+	if customerrors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
@@ -110,11 +110,17 @@ func SendMessage(smi models.Message) (string, *models.Message, error) {
 		return "", nil, &customerrors.ErrInvalidInput{
 			Message: "user is not a member of this chat"}
 	}
+
+	err = checkNotBlocked(smi.UserId, uuidRecipient,
+		&customerrors.ErrForbidden{Message: "cannot message this user"})
+	if err != nil {
+		return "", nil, err
+	}
 	smi.Date = time.Now().UTC()
 
 	err = abstractions.ChatRepositoryInstance.AddMessage(smi)
 	if err != nil {
-		return "", nil, nil
+		return "", nil, err
 	}
 
 	return uuidRecipient, &smi, nil
@@ -150,7 +156,7 @@ func DeleteMessage(input models.DeleteMessageInput) (string, error) {
 
 	err = abstractions.ChatRepositoryInstance.DeleteMessage(input.ChatId, input.TimeId)
 	if err != nil {
-		return "", nil
+		return "", err
 	}
 
 	return uuidRecipient, nil
@@ -225,3 +231,99 @@ func GetReadTime(input models.GetReadTimeInput) (*models.ReadTime, error) {
 
 	return readTime, nil
 }
+
+// A use case to check the chat status of a user.
+// Statuses are kept by the server, blocked users are reported as not found.
+func CheckStatus(input models.CheckStatusInput) error {
+	if check := utility.IsValidUUID(input.SenderId); !check {
+		return &customerrors.ErrInvalidInput{
+			Message: "invalid sender \"id\""}
+	}
+	if check := utility.IsValidUUID(input.UserId); !check {
+		return &customerrors.ErrInvalidInput{
+			Message: "invalid user \"id\""}
+	}
+
+	if input.SenderId != input.UserId {
+		return checkNotBlocked(input.SenderId, input.UserId,
+			&customerrors.ErrNotFound{Message: "user not found"})
+	}
+
+	return nil
+}
+
+// A use case to update the chat status of the current user.
+// Only validates the input, statuses are kept by the server.
+func UpdateStatus(input models.UpdateStatusInput) error {
+	if check := utility.IsValidUUID(input.SenderId); !check {
+		return &customerrors.ErrInvalidInput{
+			Message: "invalid sender \"id\""}
+	}
+	if check := utility.IsValidUUID(input.UserId); !check {
+		return &customerrors.ErrInvalidInput{
+			Message: "invalid user \"id\""}
+	}
+	if input.Status < -1 || input.Status > 1 {
+		return &customerrors.ErrInvalidInput{
+			Message: "invalid status"}
+	}
+
+	return nil
+}
+
+// A use case to mute a chat's notifications for the current user.
+// Messages are still delivered and stored.
+func MuteChat(input models.MuteChatInput) error {
+	if err := checkChatMember(input.UserId, input.ChatId); err != nil {
+		return err
+	}
+
+	return abstractions.ChatMuteRepositoryInstance.
+		AddMute(input.UserId, input.ChatId)
+}
+
+// A use case to unmute a chat's notifications for the current user.
+func UnmuteChat(input models.UnmuteChatInput) error {
+	if err := checkChatMember(input.UserId, input.ChatId); err != nil {
+		return err
+	}
+
+	return abstractions.ChatMuteRepositoryInstance.
+		DeleteMute(input.UserId, input.ChatId)
+}
+
+// Reports whether the user muted the chat.
+// Failures are reported as not muted, so notifications are not lost.
+func IsChatMuted(userId string, chatId string) bool {
+	muted, err := abstractions.ChatMuteRepositoryInstance.
+		IsMuted(userId, chatId)
+	if err != nil {
+		return false
+	}
+	return muted
+}
+
+// Returns an error if the uuids are invalid
+// or the user is not a member of the chat.
+func checkChatMember(userId string, chatId string) error {
+	if check := utility.IsValidUUID(userId); !check {
+		return &customerrors.ErrInvalidInput{
+			Message: "invalid user \"uuid\""}
+	}
+	if check := utility.IsValidUUID(chatId); !check {
+		return &customerrors.ErrInvalidInput{
+			Message: "invalid chat \"uuid\""}
+	}
+
+	friend, err := abstractions.FriendRepositoryInstance.GetFriendByChatId(chatId)
+	if err != nil {
+		return err
+	}
+
+	if userId != friend.Id1 && userId != friend.Id2 {
+		return &customerrors.ErrInvalidInput{
+			Message: "user is not a member of this chat"}
+	}
+
+	return nil
+}
@@ -9,44 +9,64 @@ import (
 )
 
 // A use case for access token deserialization.
-// Validates access token.
+// Validates access token, returns the user's uuid and role.
+// Tokens issued before a role change are rejected, so that
+// they get refreshed with the current role.
 // May return ErrUnauthorized, ErrInternal, ErrNotFound on failure.
 func DeserializeToken(dti models.DeserializeTokenInput, cfg *config.Config) (
-	userUuid string, accessTokenUuid string, err error) {
+	userUuid string, accessTokenUuid string, role string, err error) {
 
 	if dti.AccessToken == "" {
-		return "", "", &customerrors.ErrUnauthorized{
+		return "", "", "", &customerrors.ErrUnauthorized{
 			Message: "you are not logged in"}
 	}
 
 	tokenClaims, err := utility.ValidateToken(
 		dti.AccessToken, cfg.AccessToken.PublicKey)
 	if err != nil {
-		return "", "", &customerrors.ErrUnauthorized{
+		return "", "", "", &customerrors.ErrUnauthorized{
 			Message: err.Error()}
 	}
 
 	userUuid, err = abstractions.JWTCacheInstance.GetToken(tokenClaims.TokenUUID)
 	if err != nil {
-		return "", "", &customerrors.ErrUnauthorized{
+		return "", "", "", &customerrors.ErrUnauthorized{
 			Message: "token is invalid or session has expired"}
 	}
 
 	if check := utility.IsValidUUID(userUuid); !check {
-		return "", "", &customerrors.ErrNotFound{
+		return "", "", "", &customerrors.ErrNotFound{
 			Message: "invalid \"uuid\""}
 	}
-	exists, err :=
-		abstractions.UserRepositoryInstance.DoesUserExist(userUuid)
+	user, err := getActiveUser(userUuid)
 	if err != nil {
-		return "", "", err
+		return "", "", "", err
 	}
-	if !exists {
-		return "", "", &customerrors.ErrNotFound{
-			Message: "user belonging to this token no longer exists"}
+	if tokenClaims.Role != user.Role {
+		return "", "", "", &customerrors.ErrUnauthorized{
+			Message: "role has changed, refresh the access token"}
 	}
 
 	accessTokenUuid = tokenClaims.TokenUUID
 
-	return userUuid, accessTokenUuid, nil
+	return userUuid, accessTokenUuid, user.Role, nil
+}
+
+// Returns the user, ErrNotFound if the user no longer exists
+// and ErrUnauthorized if the user is suspended.
+// UUID validation is not provided.
+func getActiveUser(userUuid string) (*models.User, error) {
+	user, err := abstractions.UserRepositoryInstance.GetUser(userUuid)
+	if err != nil {
+		if customerrors.IsNotFound(err) {
+			return nil, &customerrors.ErrNotFound{
+				Message: "user belonging to this token no longer exists"}
+		}
+		return nil, err
+	}
+	if user.Suspended {
+		return nil, &customerrors.ErrUnauthorized{
+			Message: "account is suspended"}
+	}
+	return user, nil
 }
@@ -0,0 +1,177 @@
+package usecases
+
+import (
+	"spotigram/internal/customerrors"
+	"spotigram/internal/service/abstractions"
+	"spotigram/internal/service/models"
+	"spotigram/internal/utility"
+	"time"
+)
+
+// A use case to follow a song creator.
+// Expects access token deserialization beforehand.
+// Validates the passed uuid or handle.
+// May return ErrInvalidInput, ErrInternal, ErrNotFound on failure.
+func FollowUser(input models.FollowInput) error {
+	if check := utility.IsValidUUID(input.UserUUID); !check {
+		return customerrors.InvalidField("uuid", "")
+	}
+	followeeId, err := resolveUserId(input.FolloweeId)
+	if err != nil {
+		return err
+	}
+
+	if input.UserUUID == followeeId {
+		return &customerrors.ErrInvalidInput{
+			Message: "cannot follow yourself"}
+	}
+	err = checkNotBlocked(input.UserUUID, followeeId,
+		&customerrors.ErrNotFound{Message: "user not found"})
+	if err != nil {
+		return err
+	}
+
+	_, err = abstractions.SongRepositoryInstance.GetSongsByCreator(followeeId, "", 1)
+	if customerrors.IsNotFound(err) {
+		return &customerrors.ErrInvalidInput{
+			Message: "only song creators can be followed"}
+	} else if err != nil {
+		return err
+	}
+
+	exists, err := abstractions.FollowRepositoryInstance.
+		DoesFollowExist(input.UserUUID, followeeId)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return &customerrors.ErrInvalidInput{
+			Message: "already following"}
+	}
+
+	return abstractions.FollowRepositoryInstance.AddFollow(models.Follow{
+		FollowerId: input.UserUUID,
+		FolloweeId: followeeId,
+		CreatedAt:  time.Now().UTC(),
+	})
+}
+
+// A use case to unfollow a user.
+// Expects access token deserialization beforehand.
+// Validates the passed uuid or handle.
+// May return ErrInvalidInput, ErrInternal, ErrNotFound on failure.
+func UnfollowUser(input models.UnfollowInput) error {
+	if check := utility.IsValidUUID(input.UserUUID); !check {
+		return customerrors.InvalidField("uuid", "")
+	}
+	followeeId, err := resolveUserId(input.FolloweeId)
+	if err != nil {
+		return err
+	}
+
+	return abstractions.FollowRepositoryInstance.
+		DeleteFollow(input.UserUUID, followeeId)
+}
+
+// A use case to get a page of the followers of a user.
+// Expects access token deserialization beforehand.
+// Validates the passed uuid or handle.
+// May return ErrInvalidInput, ErrInternal, ErrNotFound on failure.
+func GetFollowers(input models.GetFollowsInput) (*models.Page[models.Follow], error) {
+	limit, err := pageLimit(input.Limit)
+	if err != nil {
+		return nil, err
+	}
+	userId, err := validateFollowsInput(input.RequesterUUID, input.UserUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	return abstractions.FollowRepositoryInstance.
+		GetFollowers(userId, input.Cursor, limit)
+}
+
+// A use case to get a page of the users followed by a user.
+// Expects access token deserialization beforehand.
+// Validates the passed uuid or handle.
+// May return ErrInvalidInput, ErrInternal, ErrNotFound on failure.
+func GetFollowing(input models.GetFollowsInput) (*models.Page[models.Follow], error) {
+	limit, err := pageLimit(input.Limit)
+	if err != nil {
+		return nil, err
+	}
+	userId, err := validateFollowsInput(input.RequesterUUID, input.UserUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	return abstractions.FollowRepositoryInstance.
+		GetFollowing(userId, input.Cursor, limit)
+}
+
+// A use case to get the follower and following counts of a user.
+// Expects access token deserialization beforehand.
+// Validates the passed uuid or handle.
+// May return ErrInvalidInput, ErrInternal, ErrNotFound on failure.
+func GetFollowCounts(input models.GetFollowCountsInput) (*models.FollowCounts, error) {
+	userId, err := validateFollowsInput(input.RequesterUUID, input.UserUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	return abstractions.FollowRepositoryInstance.CountFollows(userId)
+}
+
+// A use case to get the newest songs of the creators followed by the user.
+// Expects access token deserialization beforehand.
+// Validates the passed uuid.
+// May return ErrInvalidInput, ErrInternal, ErrNotFound on failure.
+func GetFeed(input models.GetFeedInput) (*models.Page[models.Song], error) {
+	if check := utility.IsValidUUID(input.UserUUID); !check {
+		return nil, customerrors.InvalidField("uuid", "")
+	}
+	limit, err := pageLimit(input.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return abstractions.SongRepositoryInstance.
+		GetFeed(input.UserUUID, input.Cursor, limit)
+}
+
+// Returns the ids of every follower of a user.
+// UUID validation is not provided.
+// May return ErrInternal on failure.
+func GetFollowerIds(userId string) ([]string, error) {
+	follows, err := collectCursorPages(func(cursor string, limit int) (*models.Page[models.Follow], error) {
+		return abstractions.FollowRepositoryInstance.GetFollowers(userId, cursor, limit)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(follows))
+	for _, f := range follows {
+		ids = append(ids, f.FollowerId)
+	}
+	return ids, nil
+}
+
+// Resolves the user whose follows are requested,
+// users blocking each other cannot see each other's follows.
+func validateFollowsInput(requesterId, idOrHandle string) (string, error) {
+	userId, err := resolveUserId(idOrHandle)
+	if err != nil {
+		return "", err
+	}
+
+	if requesterId != "" && requesterId != userId {
+		err := checkNotBlocked(requesterId, userId,
+			&customerrors.ErrNotFound{Message: "user not found"})
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return userId, nil
+}
@@ -7,19 +7,22 @@ import (
 	"spotigram/internal/utility"
 )
 
-// A use case to get user's sent friend request.
+// A use case to get a page of user's sent friend requests.
 // Expects access token deserialization beforehand.
 // Validates the passed uuid.
 // May return ErrInvalidInput, ErrInternal, ErrNotFound on failure.
-func GetFriendRequestsSent(gfrsi models.GetFriendRequestsSentInput) ([]models.FriendRequest, error) {
+func GetFriendRequestsSent(gfrsi models.GetFriendRequestsSentInput) (*models.Page[models.FriendRequest], error) {
 	if check := utility.IsValidUUID(gfrsi.UserUUID); !check {
-		return nil, &customerrors.ErrInvalidInput{
-			Message: "invalid \"uuid\""}
+		return nil, customerrors.InvalidField("uuid", "")
+	}
+	limit, err := pageLimit(gfrsi.Limit)
+	if err != nil {
+		return nil, err
 	}
 
 	friendsRequests, err :=
 		abstractions.FriendRequestRepositoryInstance.
-			GetFriendRequestsSent(gfrsi.UserUUID, gfrsi.Offset)
+			GetFriendRequestsSent(gfrsi.UserUUID, gfrsi.Cursor, limit)
 	if err != nil {
 		return nil, err
 	}
@@ -27,19 +30,22 @@ func GetFriendRequestsSent(gfrsi models.GetFriendRequestsSentInput) ([]models.Fr
 	return friendsRequests, nil
 }
 
-// A use case to get user's received friend request.
+// A use case to get a page of user's received friend requests.
 // Expects access token deserialization beforehand.
 // Validates the passed uuid.
 // May return ErrInvalidInput, ErrInternal, ErrNotFound on failure.
-func GetFriendRequestsReceived(gfrri models.GetFriendRequestsReceivedInput) ([]models.FriendRequest, error) {
+func GetFriendRequestsReceived(gfrri models.GetFriendRequestsReceivedInput) (*models.Page[models.FriendRequest], error) {
 	if check := utility.IsValidUUID(gfrri.UserUUID); !check {
-		return nil, &customerrors.ErrInvalidInput{
-			Message: "invalid \"uuid\""}
+		return nil, customerrors.InvalidField("uuid", "")
+	}
+	limit, err := pageLimit(gfrri.Limit)
+	if err != nil {
+		return nil, err
 	}
 
 	friendsRequests, err :=
 		abstractions.FriendRequestRepositoryInstance.
-			GetFriendRequestsReceived(gfrri.UserUUID, gfrri.Offset)
+			GetFriendRequestsReceived(gfrri.UserUUID, gfrri.Cursor, limit)
 	if err != nil {
 		return nil, err
 	}
@@ -53,12 +59,10 @@ func GetFriendRequestsReceived(gfrri models.GetFriendRequestsReceivedInput) ([]m
 // May return ErrInvalidInput, ErrInternal on failure.
 func AddFriendRequest(afri models.AddFriendRequestInput) error {
 	if check := utility.IsValidUUID(afri.SenderUUID); !check {
-		return &customerrors.ErrInvalidInput{
-			Message: "invalid \"uuid\""}
+		return customerrors.InvalidField("uuid", "")
 	}
 	if check := utility.IsValidUUID(afri.RecipientUUID); !check {
-		return &customerrors.ErrInvalidInput{
-			Message: "invalid \"uuid\""}
+		return customerrors.InvalidField("uuid", "")
 	}
 
 	if afri.SenderUUID == afri.RecipientUUID {
@@ -66,6 +70,32 @@ func AddFriendRequest(afri models.AddFriendRequestInput) error {
 			Message: "cannot befriend yourself"}
 	}
 
+	err := checkNotBlocked(afri.SenderUUID, afri.RecipientUUID,
+		&customerrors.ErrInvalidInput{Message: "cannot befriend this user"})
+	if err != nil {
+		return err
+	}
+
+	privacy, err := abstractions.UserRepositoryInstance.GetPrivacy(afri.RecipientUUID)
+	if err != nil {
+		return err
+	}
+	switch privacy.FriendRequests {
+	case models.PrivacyNobody:
+		return &customerrors.ErrInvalidInput{
+			Message: "this user does not accept friend requests"}
+	case models.PrivacyFriendsOfFriends:
+		mutual, err := abstractions.FriendRepositoryInstance.
+			HasMutualFriend(afri.SenderUUID, afri.RecipientUUID)
+		if err != nil {
+			return err
+		}
+		if !mutual {
+			return &customerrors.ErrInvalidInput{
+				Message: "this user only accepts friend requests from friends of friends"}
+		}
+	}
+
 	check, err := abstractions.FriendRepositoryInstance.
 		DoesFriendExist(afri.SenderUUID, afri.RecipientUUID)
 	if err != nil {
@@ -112,12 +142,10 @@ func AddFriendRequest(afri models.AddFriendRequestInput) error {
 // May return ErrInvalidInput, ErrInternal, ErrNotFound on failure.
 func UpdateFriendRequest(ufri models.UpdateFriendRequestInput) error {
 	if check := utility.IsValidUUID(ufri.SenderUUID); !check {
-		return &customerrors.ErrInvalidInput{
-			Message: "invalid \"uuid\""}
+		return customerrors.InvalidField("uuid", "")
 	}
 	if check := utility.IsValidUUID(ufri.RecipientUUID); !check {
-		return &customerrors.ErrInvalidInput{
-			Message: "invalid \"uuid\""}
+		return customerrors.InvalidField("uuid", "")
 	}
 
 	if ufri.SenderUUID == ufri.RecipientUUID {
@@ -136,12 +164,10 @@ func UpdateFriendRequest(ufri models.UpdateFriendRequestInput) error {
 // May return ErrInvalidInput, ErrInternal, ErrNotFound on failure.
 func DeleteFriendRequest(dfri models.DeleteFriendRequestInput) error {
 	if check := utility.IsValidUUID(dfri.SenderUUID); !check {
-		return &customerrors.ErrInvalidInput{
-			Message: "invalid \"uuid\""}
+		return customerrors.InvalidField("uuid", "")
 	}
 	if check := utility.IsValidUUID(dfri.RecipientUUID); !check {
-		return &customerrors.ErrInvalidInput{
-			Message: "invalid \"uuid\""}
+		return customerrors.InvalidField("uuid", "")
 	}
 
 	err := abstractions.FriendRequestRepositoryInstance.
@@ -156,12 +182,10 @@ func DeleteFriendRequest(dfri models.DeleteFriendRequestInput) error {
 // May return ErrInvalidInput, ErrInternal, ErrNotFound on failure.
 func AcceptFriendRequest(afri models.AcceptFriendRequestInput) (*models.Friend, error) {
 	if check := utility.IsValidUUID(afri.SenderUUID); !check {
-		return nil, &customerrors.ErrInvalidInput{
-			Message: "invalid \"uuid\""}
+		return nil, customerrors.InvalidField("uuid", "")
 	}
 	if check := utility.IsValidUUID(afri.RecipientUUID); !check {
-		return nil, &customerrors.ErrInvalidInput{
-			Message: "invalid \"uuid\""}
+		return nil, customerrors.InvalidField("uuid", "")
 	}
 
 	if afri.SenderUUID == afri.RecipientUUID {
@@ -7,18 +7,21 @@ import (
 	"spotigram/internal/utility"
 )
 
-// A use case to get user's friends.
+// A use case to get a page of user's friends.
 // Expects access token deserialization beforehand.
 // Validates the passed uuid.
 // May return ErrInvalidInput, ErrInternal, ErrNotFound on failure.
-func GetFriends(gfi models.GetFriendsInput) ([]models.Friend, error) {
+func GetFriends(gfi models.GetFriendsInput) (*models.Page[models.Friend], error) {
 	if check := utility.IsValidUUID(gfi.UserUUID); !check {
-		return nil, &customerrors.ErrInvalidInput{
-			Message: "invalid \"uuid\""}
+		return nil, customerrors.InvalidField("uuid", "")
+	}
+	limit, err := pageLimit(gfi.Limit)
+	if err != nil {
+		return nil, err
 	}
 
 	friends, err :=
-		abstractions.FriendRepositoryInstance.GetFriends(gfi.UserUUID, gfi.Offset)
+		abstractions.FriendRepositoryInstance.GetFriends(gfi.UserUUID, gfi.Cursor, limit)
 	if err != nil {
 		return nil, err
 	}
@@ -26,18 +29,52 @@ func GetFriends(gfi models.GetFriendsInput) ([]models.Friend, error) {
 	return friends, nil
 }
 
+// A use case to suggest a page of friends to a user, ranked by mutual friends and by
+// other users' listens of the songs the user listened to or has in playlists.
+// Expects access token deserialization beforehand.
+// Validates the passed uuid.
+// May return ErrInvalidInput, ErrInternal, ErrNotFound on failure.
+func GetFriendSuggestions(input models.GetFriendSuggestionsInput) (*models.Page[models.FriendSuggestion], error) {
+	if check := utility.IsValidUUID(input.UserUUID); !check {
+		return nil, customerrors.InvalidField("uuid", "")
+	}
+	limit, err := pageLimit(input.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	playlists, err := collectCursorPages(func(cursor string, limit int) (*models.Page[models.Playlist], error) {
+		return abstractions.PlaylistRepositoryInstance.GetPlaylists(input.UserUUID, cursor, limit, "")
+	})
+	if err != nil {
+		return nil, err
+	}
+	var songIds []string
+	for _, p := range playlists {
+		songs, err := abstractions.PlaylistSongRepositoryInstance.
+			GetPlaylistSongs(p.Id)
+		if err != nil && !customerrors.IsNotFound(err) {
+			return nil, err
+		}
+		for _, s := range songs {
+			songIds = append(songIds, s.SongId)
+		}
+	}
+
+	return abstractions.FriendRepositoryInstance.
+		GetFriendSuggestions(input.UserUUID, songIds, input.Cursor, limit)
+}
+
 // A use case to delete a user's friend.
 // Expects access token deserialization beforehand.
 // Validates the passed uuid.
 // May return ErrInvalidInput, ErrInternal, ErrNotFound on failure.
 func DeleteFriend(dfi models.DeleteFriendInput) error {
 	if check := utility.IsValidUUID(dfi.User1UUID); !check {
-		return &customerrors.ErrInvalidInput{
-			Message: "invalid \"uuid\""}
+		return customerrors.InvalidField("uuid", "")
 	}
 	if check := utility.IsValidUUID(dfi.User2UUID); !check {
-		return &customerrors.ErrInvalidInput{
-			Message: "invalid \"uuid\""}
+		return customerrors.InvalidField("uuid", "")
 	}
 
 	chatId, err := abstractions.FriendRepositoryInstance.
@@ -58,6 +95,12 @@ func DeleteFriend(dfi models.DeleteFriendInput) error {
 		return err
 	}
 
+	err = abstractions.ChatMuteRepositoryInstance.
+		DeleteMutesByChatId(chatId)
+	if err != nil {
+		return err
+	}
+
 	err = abstractions.FriendRepositoryInstance.
 		DeleteFriend(dfi.User1UUID, dfi.User2UUID)
 
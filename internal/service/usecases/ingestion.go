@@ -0,0 +1,166 @@
+package usecases
+
+import (
+	"spotigram/internal/config"
+	"spotigram/internal/customerrors"
+	"spotigram/internal/service/abstractions"
+	"spotigram/internal/service/models"
+	"spotigram/internal/utility"
+	"sync"
+)
+
+// A song upload waiting for an ingestion worker,
+// the file waits in the blob store under the song file key.
+type ingestionTask struct {
+	job     models.UploadJob
+	song    models.Song
+	format  string
+	picture []byte
+	// The uploaded file until it is spooled into the blob store
+	file []byte
+}
+
+var ingestionQueue chan ingestionTask
+
+// Serializes the enqueuing of tasks so that a batch
+// checked to fit the queue is queued as a whole.
+var ingestionQueueMutex sync.Mutex
+
+var normalizeLoudness = false
+var targetLoudness = -16.0
+
+var uploadJobListener = func(models.UploadJob, *models.Song) {}
+
+// Sets the function called on every upload job status change,
+// the song is only set once the job is done.
+func SetUploadJobListener(f func(models.UploadJob, *models.Song)) {
+	uploadJobListener = f
+}
+
+// Starts the song ingestion workers, fails the jobs
+// left unfinished by a previous run beforehand.
+// Non-positive sizes fall back to a single worker
+// and a queue of 16 uploads, a target loudness of 0
+// keeps -16 LUFS.
+func StartSongIngestion(cfg *config.Config) {
+	normalizeLoudness = cfg.Ingestion.Normalize
+	if cfg.Ingestion.TargetLoudness < 0 {
+		targetLoudness = cfg.Ingestion.TargetLoudness
+	}
+
+	workers := cfg.Ingestion.Workers
+	if workers <= 0 {
+		workers = 1
+	}
+	queueSize := cfg.Ingestion.QueueSize
+	if queueSize <= 0 {
+		queueSize = 16
+	}
+
+	jobs, err := abstractions.UploadJobRepositoryInstance.
+		FailUnfinishedJobs("interrupted by a server restart")
+	if err != nil {
+		panic(err)
+	}
+	for _, job := range jobs {
+		_ = abstractions.SongChunkRepositoryInstance.DeleteSongChunks(job.SongId)
+		_ = deleteSongBlobs(job.SongId)
+	}
+
+	ingestionQueue = make(chan ingestionTask, queueSize)
+	for i := 0; i < workers; i++ {
+		go ingestionWorker()
+	}
+}
+
+// Returns how many tasks the ingestion queue holds at most,
+// 0 if it is not started.
+func ingestionQueueCapacity() int {
+	return cap(ingestionQueue)
+}
+
+// Puts every task in the ingestion queue without blocking,
+// or none of them if they do not all fit.
+// Returns false if the queue is too full or not started.
+func enqueueIngestion(tasks ...ingestionTask) bool {
+	ingestionQueueMutex.Lock()
+	defer ingestionQueueMutex.Unlock()
+
+	// Workers only take tasks out, the free space cannot shrink meanwhile
+	if ingestionQueue == nil || cap(ingestionQueue)-len(ingestionQueue) < len(tasks) {
+		return false
+	}
+	for _, task := range tasks {
+		ingestionQueue <- task
+	}
+	return true
+}
+
+// Processes the queued uploads one by one.
+func ingestionWorker() {
+	for task := range ingestionQueue {
+		processUploadJob(task)
+	}
+}
+
+// Runs the ingestion of an upload job and reports its status,
+// the partially saved chunks of a failed job are deleted.
+func processUploadJob(task ingestionTask) {
+	job := task.job
+	setUploadJobStatus(&job, models.UploadJobProcessing, "", nil)
+
+	song, err := ingestSongSafely(task)
+	if err != nil {
+		_ = abstractions.SongChunkRepositoryInstance.DeleteSongChunks(job.SongId)
+		_ = deleteSongBlobs(job.SongId)
+		setUploadJobStatus(&job, models.UploadJobFailed, err.Error(), nil)
+		return
+	}
+
+	setUploadJobStatus(&job, models.UploadJobDone, "", song)
+}
+
+// Calls ingestSong, turning a panic into ErrInternal
+// so that a worker never dies on a broken upload.
+func ingestSongSafely(task ingestionTask) (song *models.Song, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			song, err = nil, &customerrors.ErrInternal{
+				Message: "song ingestion failed"}
+		}
+	}()
+	return ingestSong(task)
+}
+
+// Saves the new status of an upload job and notifies the listener.
+func setUploadJobStatus(job *models.UploadJob, status string, errorMessage string, song *models.Song) {
+	job.Status, job.Error = status, errorMessage
+	_ = abstractions.UploadJobRepositoryInstance.
+		UpdateJobStatus(job.Id, status, errorMessage)
+	if updated, err := abstractions.UploadJobRepositoryInstance.
+		GetJob(job.Id); err == nil {
+		job.UpdatedAt = updated.UpdatedAt
+	}
+	uploadJobListener(*job, song)
+}
+
+// A use case to get the status of an upload job.
+// Expects access token deserialization beforehand.
+// Validates the passed uuid.
+// May return ErrInvalidInput, ErrInternal, ErrNotFound on failure.
+func GetUploadStatus(input models.GetUploadStatusInput) (*models.UploadJob, error) {
+	if check := utility.IsValidUUID(input.JobId); !check {
+		return nil, customerrors.InvalidField("id", "")
+	}
+
+	job, err := abstractions.UploadJobRepositoryInstance.GetJob(input.JobId)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isOwnerOrAdmin(job.UserId, input.UserId, input.UserRole) {
+		return nil, &customerrors.ErrNotFound{Message: "upload job not found"}
+	}
+
+	return job, nil
+}
@@ -0,0 +1,35 @@
+package usecases
+
+import "testing"
+
+func TestEnqueueIngestionQueuesWholeBatches(t *testing.T) {
+	defer func(queue chan ingestionTask) { ingestionQueue = queue }(ingestionQueue)
+
+	ingestionQueue = nil
+	if enqueueIngestion(ingestionTask{}) {
+		t.Fatal("task queued before the queue started")
+	}
+
+	ingestionQueue = make(chan ingestionTask, 3)
+	if enqueueIngestion(make([]ingestionTask, 4)...) {
+		t.Fatal("batch larger than the queue was queued")
+	}
+	if len(ingestionQueue) != 0 {
+		t.Fatalf("rejected batch left %d tasks in the queue", len(ingestionQueue))
+	}
+
+	if !enqueueIngestion(make([]ingestionTask, 2)...) {
+		t.Fatal("batch fitting the queue was rejected")
+	}
+	if enqueueIngestion(make([]ingestionTask, 2)...) {
+		t.Fatal("batch larger than the free space was queued")
+	}
+	if len(ingestionQueue) != 2 {
+		t.Fatalf("queue holds %d tasks, want 2", len(ingestionQueue))
+	}
+
+	<-ingestionQueue
+	if !enqueueIngestion(make([]ingestionTask, 2)...) {
+		t.Fatal("batch fitting the freed space was rejected")
+	}
+}
@@ -14,14 +14,12 @@ import (
 // May return ErrInvalidInput, ErrInternal, ErrNotFound on failure.
 func ChangeName(cni models.ChangeNameInput) error {
 	if check := utility.IsValidUUID(cni.UserUUID); !check {
-		return &customerrors.ErrInvalidInput{
-			Message: "invalid \"uuid\""}
+		return customerrors.InvalidField("uuid", "")
 	}
 
 	valid := utility.IsValidStructField(cni, "Name")
 	if !valid {
-		return &customerrors.ErrInvalidInput{
-			Message: "invalid \"name\" (must be 8-100 chars long)"}
+		return customerrors.InvalidField("name", "must be 8-100 chars long")
 	}
 
 	err := abstractions.UserRepositoryInstance.UpdateName(
@@ -36,24 +34,20 @@ func ChangeName(cni models.ChangeNameInput) error {
 // May return ErrInvalidInput, ErrInternal, ErrNotFound on failure.
 func ChangePassword(cpi models.ChangePasswordInput) error {
 	if check := utility.IsValidUUID(cpi.UserUUID); !check {
-		return &customerrors.ErrInvalidInput{
-			Message: "invalid \"uuid\""}
+		return customerrors.InvalidField("uuid", "")
 	}
 
 	valid := utility.IsValidStructField(cpi, "OldPassword")
 	if !valid {
-		return &customerrors.ErrInvalidInput{
-			Message: "invalid \"old_password\" (must be 8-72 chars long)"}
+		return customerrors.InvalidField("old_password", "must be 8-72 chars long")
 	}
 	valid = utility.IsValidStructField(cpi, "NewPassword")
 	if !valid {
-		return &customerrors.ErrInvalidInput{
-			Message: "invalid \"new_password\" (must be 8-72 chars long)"}
+		return customerrors.InvalidField("new_password", "must be 8-72 chars long")
 	}
 	valid = utility.IsValidStructField(cpi, "NewPasswordConfirmed")
 	if !valid {
-		return &customerrors.ErrInvalidInput{
-			Message: "invalid \"new_password_confirmed\" (must be 8-72 chars long)"}
+		return customerrors.InvalidField("new_password_confirmed", "must be 8-72 chars long")
 	}
 
 	oldPasswordHash, err := abstractions.UserRepositoryInstance.GetPassword(cpi.UserUUID)
@@ -89,14 +83,12 @@ func ChangePassword(cpi models.ChangePasswordInput) error {
 // May return ErrInvalidInput, ErrInternal, ErrNotFound on failure.
 func ChangePublicKey(cpki models.ChangePublicKeyInput) error {
 	if check := utility.IsValidUUID(cpki.UserUUID); !check {
-		return &customerrors.ErrInvalidInput{
-			Message: "invalid \"uuid\""}
+		return customerrors.InvalidField("uuid", "")
 	}
 
 	valid := utility.IsValidStructField(cpki, "PublicKey")
 	if cpki.PublicKey == "" || !valid {
-		return &customerrors.ErrInvalidInput{
-			Message: "invalid \"public_key\" (must be 1-6120 chars long)"}
+		return customerrors.InvalidField("public_key", "must be 1-6120 chars long")
 	}
 
 	err := abstractions.UserRepositoryInstance.UpdatePublicKey(
@@ -111,8 +103,7 @@ func ChangePublicKey(cpki models.ChangePublicKeyInput) error {
 // May return ErrInvalidInput, ErrInternal, ErrNotFound on failure.
 func ChangePicture(cpi models.ChangePictureInput) error {
 	if check := utility.IsValidUUID(cpi.UserUUID); !check {
-		return &customerrors.ErrInvalidInput{
-			Message: "invalid \"uuid\""}
+		return customerrors.InvalidField("uuid", "")
 	}
 
 	if cpi.Image == nil || len(cpi.Image) == 0 || len(cpi.Image) > 5*1024*1024 {
@@ -126,8 +117,5 @@ func ChangePicture(cpi models.ChangePictureInput) error {
 			Message: "invalid image, must be a png, a webp or a jpg (under 5 megabytes)"}
 	}
 
-	err = abstractions.UserRepositoryInstance.UpdatePicture(
-		cpi.UserUUID, imageWebP)
-
-	return err
+	return putBlob(userPictureKey(cpi.UserUUID), imageWebP)
 }
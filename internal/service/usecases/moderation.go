@@ -0,0 +1,277 @@
+package usecases
+
+import (
+	"spotigram/internal/customerrors"
+	"spotigram/internal/service/abstractions"
+	"spotigram/internal/service/models"
+	"spotigram/internal/utility"
+	"time"
+)
+
+// A use case to report a song, playlist, user or message.
+// Expects access token deserialization beforehand.
+// Returns the id of the created report.
+// May return ErrInvalidInput, ErrInternal, ErrNotFound on failure.
+func ReportContent(input models.ReportInput) (string, error) {
+	if check := utility.IsValidUUID(input.UserId); !check {
+		return "", &customerrors.ErrInvalidInput{
+			Message: "invalid user \"uuid\""}
+	}
+	if !utility.IsValidStructField(input, "TargetType") {
+		return "", customerrors.InvalidField("target_type", "song, playlist, user or message")
+	}
+	if check := utility.IsValidUUID(input.TargetId); !check {
+		return "", customerrors.InvalidField("target_id", "")
+	}
+	if !utility.IsValidStructField(input, "Reason") {
+		return "", customerrors.InvalidField("reason", "must be 1-1000 chars long")
+	}
+
+	switch input.TargetType {
+	case models.ReportTargetSong:
+		if _, err := abstractions.SongRepositoryInstance.
+			GetSongInfo(input.TargetId); err != nil {
+			return "", err
+		}
+	case models.ReportTargetPlaylist:
+		if _, err := abstractions.PlaylistRepositoryInstance.
+			GetPlaylist(input.TargetId); err != nil {
+			return "", err
+		}
+	case models.ReportTargetUser:
+		if input.TargetId == input.UserId {
+			return "", &customerrors.ErrInvalidInput{
+				Message: "cannot report yourself"}
+		}
+		exists, err := abstractions.UserRepositoryInstance.
+			DoesUserExist(input.TargetId)
+		if err != nil {
+			return "", err
+		}
+		if !exists {
+			return "", &customerrors.ErrNotFound{
+				Message: "user not found"}
+		}
+	case models.ReportTargetMessage:
+		if input.MessageId <= 0 {
+			return "", customerrors.InvalidField("message_id", "")
+		}
+		if err := checkChatMember(input.UserId, input.TargetId); err != nil {
+			return "", err
+		}
+	}
+	if input.TargetType != models.ReportTargetMessage {
+		input.MessageId = 0
+	}
+
+	report := models.Report{
+		Id:         utility.GenerateUUID(),
+		ReporterId: input.UserId,
+		TargetType: input.TargetType,
+		TargetId:   input.TargetId,
+		MessageId:  input.MessageId,
+		Reason:     input.Reason,
+		Status:     models.ReportStatusOpen,
+		CreatedAt:  time.Now().UTC(),
+	}
+
+	err := abstractions.ReportRepositoryInstance.AddReport(report)
+	if err != nil {
+		return "", err
+	}
+
+	return report.Id, nil
+}
+
+// A use case to get a page of the moderation queue.
+// Expects access token deserialization beforehand, moderators and admins only.
+// May return ErrInvalidInput, ErrForbidden, ErrInternal, ErrNotFound on failure.
+func GetReports(input models.GetReportsInput) (*models.Page[models.Report], error) {
+	if err := requireModerator(input.UserRole); err != nil {
+		return nil, err
+	}
+	limit, err := pageLimit(input.Limit)
+	if err != nil {
+		return nil, err
+	}
+	if !utility.IsValidStructField(input, "Status") {
+		return nil, customerrors.InvalidField("status", "open, resolved or dismissed")
+	}
+
+	return abstractions.ReportRepositoryInstance.
+		GetReports(input.Status, input.Cursor, limit)
+}
+
+// A use case to resolve or dismiss a report.
+// Expects access token deserialization beforehand, moderators and admins only.
+// May return ErrInvalidInput, ErrForbidden, ErrInternal, ErrNotFound on failure.
+func ResolveReport(input models.ResolveReportInput) error {
+	if err := requireModerator(input.UserRole); err != nil {
+		return err
+	}
+	if check := utility.IsValidUUID(input.ReportId); !check {
+		return customerrors.InvalidField("id", "")
+	}
+	if !utility.IsValidStructField(input, "Status") {
+		return customerrors.InvalidField("status", "resolved or dismissed")
+	}
+	if !utility.IsValidStructField(input, "Resolution") {
+		return customerrors.InvalidField("resolution", "under 1000 chars")
+	}
+
+	report, err := abstractions.ReportRepositoryInstance.GetReport(input.ReportId)
+	if err != nil {
+		return err
+	}
+	if report.Status != models.ReportStatusOpen {
+		return &customerrors.ErrInvalidInput{
+			Message: "report is already closed"}
+	}
+
+	err = abstractions.ReportRepositoryInstance.ResolveReport(
+		input.ReportId, input.UserId, input.Status, input.Resolution)
+	if err != nil {
+		return err
+	}
+
+	action := "resolve-report"
+	if input.Status == models.ReportStatusDismissed {
+		action = "dismiss-report"
+	}
+	return recordModeration(input.UserId, action,
+		"report", input.ReportId, input.Resolution)
+}
+
+// A use case to hide a song from the songs list.
+// Expects access token deserialization beforehand, moderators and admins only.
+// May return ErrInvalidInput, ErrForbidden, ErrInternal, ErrNotFound on failure.
+func HideSong(input models.ModerationInput) error {
+	return setSongHidden(input, true)
+}
+
+// A use case to show a hidden song in the songs list again.
+// Expects access token deserialization beforehand, moderators and admins only.
+// May return ErrInvalidInput, ErrForbidden, ErrInternal, ErrNotFound on failure.
+func UnhideSong(input models.ModerationInput) error {
+	return setSongHidden(input, false)
+}
+
+// A use case to suspend an account, suspended users cannot sign in
+// and their sessions are revoked.
+// Expects access token deserialization beforehand, moderators and admins only.
+// May return ErrInvalidInput, ErrForbidden, ErrInternal, ErrNotFound on failure.
+func SuspendUser(input models.ModerationInput) error {
+	return setUserSuspended(input, true)
+}
+
+// A use case to lift an account suspension.
+// Expects access token deserialization beforehand, moderators and admins only.
+// May return ErrInvalidInput, ErrForbidden, ErrInternal, ErrNotFound on failure.
+func UnsuspendUser(input models.ModerationInput) error {
+	return setUserSuspended(input, false)
+}
+
+// A use case to read a page of the moderation audit log.
+// Expects access token deserialization beforehand, moderators and admins only.
+// May return ErrInvalidInput, ErrForbidden, ErrInternal, ErrNotFound on failure.
+func GetAuditLog(input models.GetAuditLogInput) (*models.Page[models.AuditLogEntry], error) {
+	if err := requireModerator(input.UserRole); err != nil {
+		return nil, err
+	}
+	limit, err := pageLimit(input.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return abstractions.AuditLogRepositoryInstance.GetEntries(input.Cursor, limit)
+}
+
+func setSongHidden(input models.ModerationInput, hidden bool) error {
+	if err := validateModerationInput(input); err != nil {
+		return err
+	}
+
+	err := abstractions.SongRepositoryInstance.UpdateHidden(input.TargetId, hidden)
+	if err != nil {
+		return err
+	}
+
+	action := "hide-song"
+	if !hidden {
+		action = "unhide-song"
+	}
+	return recordModeration(input.UserId, action,
+		models.ReportTargetSong, input.TargetId, input.Reason)
+}
+
+func setUserSuspended(input models.ModerationInput, suspended bool) error {
+	if err := validateModerationInput(input); err != nil {
+		return err
+	}
+	if input.TargetId == input.UserId {
+		return &customerrors.ErrInvalidInput{
+			Message: "cannot suspend yourself"}
+	}
+
+	user, err := abstractions.UserRepositoryInstance.GetUser(input.TargetId)
+	if err != nil {
+		return err
+	}
+	if user.Role == models.RoleAdmin {
+		return &customerrors.ErrForbidden{
+			Message: "cannot suspend an admin"}
+	}
+	if user.Role == models.RoleModerator && input.UserRole != models.RoleAdmin {
+		return &customerrors.ErrForbidden{
+			Message: "only admins can suspend moderators"}
+	}
+
+	err = abstractions.UserRepositoryInstance.UpdateSuspended(input.TargetId, suspended)
+	if err != nil {
+		return err
+	}
+	if suspended {
+		_, err = abstractions.JWTCacheInstance.DeleteUserTokens(input.TargetId)
+		if err != nil {
+			return err
+		}
+	}
+
+	action := "suspend-user"
+	if !suspended {
+		action = "unsuspend-user"
+	}
+	return recordModeration(input.UserId, action,
+		models.ReportTargetUser, input.TargetId, input.Reason)
+}
+
+func validateModerationInput(input models.ModerationInput) error {
+	if err := requireModerator(input.UserRole); err != nil {
+		return err
+	}
+	if check := utility.IsValidUUID(input.TargetId); !check {
+		return customerrors.InvalidField("id", "")
+	}
+	if !utility.IsValidStructField(input, "Reason") {
+		return customerrors.InvalidField("reason", "under 1000 chars")
+	}
+	return nil
+}
+
+// Returns ErrForbidden unless the role is moderator or admin.
+func requireModerator(role string) error {
+	return requireRole(role, models.RoleModerator, models.RoleAdmin)
+}
+
+// Adds a moderator action to the audit log.
+func recordModeration(moderatorId, action, targetType, targetId, details string) error {
+	return abstractions.AuditLogRepositoryInstance.AddEntry(models.AuditLogEntry{
+		Id:          utility.GenerateUUID(),
+		ModeratorId: moderatorId,
+		Action:      action,
+		TargetType:  targetType,
+		TargetId:    targetId,
+		Details:     details,
+		CreatedAt:   time.Now().UTC(),
+	})
+}
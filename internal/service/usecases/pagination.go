@@ -0,0 +1,57 @@
+package usecases
+
+import (
+	"spotigram/internal/config"
+	"spotigram/internal/customerrors"
+	"spotigram/internal/service/models"
+	"strconv"
+)
+
+var defaultPageSize = 20
+var maxPageSize = 100
+
+// Sets the page sizes of cursor paginated lists,
+// non-positive sizes keep the defaults.
+func SetupPagination(cfg *config.Config) {
+	if cfg.Pagination.MaxPageSize > 0 {
+		maxPageSize = cfg.Pagination.MaxPageSize
+	}
+	if cfg.Pagination.DefaultPageSize > 0 {
+		defaultPageSize = cfg.Pagination.DefaultPageSize
+	}
+	if defaultPageSize > maxPageSize {
+		defaultPageSize = maxPageSize
+	}
+}
+
+// Returns the page size to use for the requested limit,
+// the default one if the limit is not set.
+// May return ErrInvalidInput on failure.
+func pageLimit(limit int) (int, error) {
+	if limit == 0 {
+		return defaultPageSize, nil
+	}
+	if limit < 0 || limit > maxPageSize {
+		return 0, customerrors.InvalidField("limit", "must be 1-"+strconv.Itoa(maxPageSize))
+	}
+	return limit, nil
+}
+
+// Returns every item of a cursor paginated list.
+func collectCursorPages[T any](get func(cursor string, limit int) (*models.Page[T], error)) ([]T, error) {
+	items := []T{}
+	cursor := ""
+	for {
+		page, err := get(cursor, maxPageSize)
+		if customerrors.IsNotFound(err) {
+			return items, nil
+		} else if err != nil {
+			return nil, err
+		}
+		items = append(items, page.Items...)
+		if page.NextCursor == "" {
+			return items, nil
+		}
+		cursor = page.NextCursor
+	}
+}
@@ -7,24 +7,22 @@ import (
 	"spotigram/internal/utility"
 )
 
-func GetPlaylists(input models.GetPlaylistsInput) ([]models.Playlist, error) {
+func GetPlaylists(input models.GetPlaylistsInput) (*models.Page[models.Playlist], error) {
 	if check := utility.IsValidUUID(input.UserId); !check {
-		return nil, &customerrors.ErrInvalidInput{
-			Message: "invalid \"uuid\""}
+		return nil, customerrors.InvalidField("uuid", "")
 	}
-	if check := input.Offset >= 0; !check {
-		return nil, &customerrors.ErrInvalidInput{
-			Message: "invalid \"offset\""}
+	limit, err := pageLimit(input.Limit)
+	if err != nil {
+		return nil, err
 	}
 	valid := utility.IsValidStructField(input, "PlaylistNameFilter")
 	if !valid {
-		return nil, &customerrors.ErrInvalidInput{
-			Message: "invalid \"playlistname_filter\" (must be 5-100 chars long)"}
+		return nil, customerrors.InvalidField("playlistname_filter", "must be 5-100 chars long")
 	}
 
 	playlists, err :=
 		abstractions.PlaylistRepositoryInstance.GetPlaylists(
-			input.UserId, input.Offset, input.PlaylistNameFilter)
+			input.UserId, input.Cursor, limit, input.PlaylistNameFilter)
 	if err != nil {
 		return nil, err
 	}
@@ -46,7 +44,7 @@ func GetPlaylistSongs(input models.GetPlaylistSongsInput) ([]models.PlaylistSong
 	if err != nil {
 		return nil, err
 	}
-	if playlist.UserId != input.UserId {
+	if !isOwnerOrAdmin(playlist.UserId, input.UserId, input.UserRole) {
 		return nil, &customerrors.ErrInvalidInput{
 			Message: "you are not the owner"}
 	}
@@ -77,7 +75,7 @@ func DeletePlaylist(input models.DeletePlaylistInput) error {
 		return err
 	}
 
-	if playlist.UserId != input.UserId {
+	if !isOwnerOrAdmin(playlist.UserId, input.UserId, input.UserRole) {
 		return &customerrors.ErrInvalidInput{
 			Message: "you are not the owner"}
 	}
@@ -96,14 +94,12 @@ func DeletePlaylist(input models.DeletePlaylistInput) error {
 
 func AddPlaylist(input models.AddPlaylistInput) (string, error) {
 	if check := utility.IsValidUUID(input.UserId); !check {
-		return "", &customerrors.ErrInvalidInput{
-			Message: "invalid \"id\""}
+		return "", customerrors.InvalidField("id", "")
 	}
 
 	valid := utility.IsValidStructField(input, "Name")
 	if !valid {
-		return "", &customerrors.ErrInvalidInput{
-			Message: "invalid \"name\" (must be 5-100 chars long)"}
+		return "", customerrors.InvalidField("name", "must be 5-100 chars long")
 	}
 
 	uuid := utility.GenerateUUID()
@@ -132,8 +128,7 @@ func UpdatePlaylistName(input models.UpdatePlaylistNameInput) error {
 
 	valid := utility.IsValidStructField(input, "Name")
 	if !valid {
-		return &customerrors.ErrInvalidInput{
-			Message: "invalid \"new_name\" (must be 5-100 chars long)"}
+		return customerrors.InvalidField("new_name", "must be 5-100 chars long")
 	}
 
 	playlist, err := abstractions.PlaylistRepositoryInstance.
@@ -142,7 +137,7 @@ func UpdatePlaylistName(input models.UpdatePlaylistNameInput) error {
 		return err
 	}
 
-	if playlist.UserId != input.UserId {
+	if !isOwnerOrAdmin(playlist.UserId, input.UserId, input.UserRole) {
 		return &customerrors.ErrInvalidInput{
 			Message: "you are not the owner"}
 	}
@@ -176,7 +171,7 @@ func AddPlaylistSong(input models.AddPlaylistSongInput) error {
 	if err != nil {
 		return err
 	}
-	if playlist.UserId != input.UserId {
+	if !isOwnerOrAdmin(playlist.UserId, input.UserId, input.UserRole) {
 		return &customerrors.ErrInvalidInput{
 			Message: "you are not the owner"}
 	}
@@ -240,7 +235,7 @@ func DeletePlaylistSong(input models.DeletePlaylistSongInput) error {
 	if err != nil {
 		return err
 	}
-	if playlist.UserId != input.UserId {
+	if !isOwnerOrAdmin(playlist.UserId, input.UserId, input.UserRole) {
 		return &customerrors.ErrInvalidInput{
 			Message: "you are not the owner"}
 	}
@@ -0,0 +1,121 @@
+package usecases
+
+import (
+	"spotigram/internal/customerrors"
+	"spotigram/internal/service/abstractions"
+	"spotigram/internal/service/models"
+	"spotigram/internal/utility"
+)
+
+// A use case for current user's privacy settings.
+// Expects access token deserialization beforehand.
+// Validates the passed uuid.
+// May return ErrInvalidInput, ErrInternal, ErrNotFound on failure.
+func GetPrivacy(input models.GetPrivacyInput) (*models.PrivacySettings, error) {
+	if check := utility.IsValidUUID(input.UserUUID); !check {
+		return nil, customerrors.InvalidField("uuid", "")
+	}
+
+	return abstractions.UserRepositoryInstance.GetPrivacy(input.UserUUID)
+}
+
+// A use case for changing current user's privacy settings.
+// Expects access token deserialization beforehand.
+// Validates the passed uuid and settings.
+// May return ErrInvalidInput, ErrInternal, ErrNotFound on failure.
+func UpdatePrivacy(input models.UpdatePrivacyInput) error {
+	if check := utility.IsValidUUID(input.UserUUID); !check {
+		return customerrors.InvalidField("uuid", "")
+	}
+	if !utility.IsValidStructField(input, "FriendRequests") {
+		return customerrors.InvalidField("friend_requests", "everyone, friends-of-friends or nobody")
+	}
+	if !utility.IsValidStructField(input, "PictureVisibility") {
+		return customerrors.InvalidField("picture_visibility", "everyone, friends or nobody")
+	}
+	if !utility.IsValidStructField(input, "ActivityVisibility") {
+		return customerrors.InvalidField("activity_visibility", "everyone, friends or nobody")
+	}
+
+	privacy, err := abstractions.UserRepositoryInstance.GetPrivacy(input.UserUUID)
+	if err != nil {
+		return err
+	}
+	if input.Discoverable != nil {
+		privacy.Discoverable = *input.Discoverable
+	}
+	if input.FriendRequests != "" {
+		privacy.FriendRequests = input.FriendRequests
+	}
+	if input.PictureVisibility != "" {
+		privacy.PictureVisibility = input.PictureVisibility
+	}
+	if input.ActivityVisibility != "" {
+		privacy.ActivityVisibility = input.ActivityVisibility
+	}
+
+	return abstractions.UserRepositoryInstance.UpdatePrivacy(input.UserUUID, *privacy)
+}
+
+// A use case for a page of a user's recently listened songs.
+// Expects access token deserialization beforehand.
+// Validates the passed uuid or handle.
+// May return ErrInvalidInput, ErrInternal, ErrNotFound on failure.
+func GetActivity(input models.GetActivityInput) (*models.Page[models.LastStream], error) {
+	limit, err := pageLimit(input.Limit)
+	if err != nil {
+		return nil, err
+	}
+	userId, err := resolveUserId(input.UserUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.RequesterUUID != "" && input.RequesterUUID != userId {
+		err := checkNotBlocked(input.RequesterUUID, userId,
+			&customerrors.ErrNotFound{Message: "user not found"})
+		if err != nil {
+			return nil, err
+		}
+
+		privacy, err := abstractions.UserRepositoryInstance.GetPrivacy(userId)
+		if err != nil {
+			return nil, err
+		}
+		visible, err := isVisibleTo(privacy.ActivityVisibility, userId, input.RequesterUUID)
+		if err != nil {
+			return nil, err
+		}
+		if !visible {
+			return nil, &customerrors.ErrNotFound{
+				Message: "activity not found"}
+		}
+	}
+
+	return abstractions.ListenRepositoryInstance.GetListens(userId, input.Cursor, limit)
+}
+
+// Reports whether something of the owner with the given
+// visibility can be seen by the requester.
+// Owners and unset requesters can see everything.
+// May return ErrInternal on failure.
+func isVisibleTo(visibility string, ownerId string, requesterId string) (bool, error) {
+	if requesterId == "" || requesterId == ownerId {
+		return true, nil
+	}
+
+	switch visibility {
+	case models.PrivacyEveryone:
+		return true, nil
+	case models.PrivacyFriends:
+		return abstractions.FriendRepositoryInstance.DoesFriendExist(ownerId, requesterId)
+	case models.PrivacyFriendsOfFriends:
+		friends, err := abstractions.FriendRepositoryInstance.DoesFriendExist(ownerId, requesterId)
+		if err != nil || friends {
+			return friends, err
+		}
+		return abstractions.FriendRepositoryInstance.HasMutualFriend(ownerId, requesterId)
+	default:
+		return false, nil
+	}
+}
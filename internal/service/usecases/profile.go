@@ -0,0 +1,158 @@
+package usecases
+
+import (
+	"fmt"
+	"regexp"
+	"spotigram/internal/customerrors"
+	"spotigram/internal/service/abstractions"
+	"spotigram/internal/service/models"
+	"spotigram/internal/utility"
+	"strings"
+	"time"
+)
+
+// How long a user has to wait between handle changes.
+const handleChangeCooldown = 30 * 24 * time.Hour
+
+var handleRegexp = regexp.MustCompile(`^[a-z0-9_]{3,30}$`)
+
+// Handles nobody can take, so they cannot be used to impersonate staff
+// or be confused with routes.
+var reservedHandles = map[string]bool{
+	"admin": true, "administrator": true, "moderator": true, "mod": true,
+	"staff": true, "support": true, "help": true, "root": true,
+	"system": true, "spotigram": true, "official": true, "me": true,
+	"user": true, "users": true, "api": true, "auth": true,
+	"settings": true, "null": true, "undefined": true, "anonymous": true,
+}
+
+// A use case for changing a user's @handle.
+// Handles are lowercase, 3-30 letters, digits or underscores,
+// the leading @ is optional.
+// Expects access token deserialization beforehand.
+// Validates the passed uuid and handle.
+// May return ErrInvalidInput, ErrInternal, ErrNotFound on failure.
+func ChangeHandle(input models.ChangeHandleInput) error {
+	if check := utility.IsValidUUID(input.UserUUID); !check {
+		return customerrors.InvalidField("uuid", "")
+	}
+
+	handle := strings.ToLower(strings.TrimPrefix(input.Handle, "@"))
+	if !handleRegexp.MatchString(handle) {
+		return customerrors.InvalidField("handle", "3-30 letters, digits or underscores")
+	}
+	if reservedHandles[handle] {
+		return &customerrors.ErrInvalidInput{
+			Message: "this handle is reserved"}
+	}
+
+	changedAt, err := abstractions.UserRepositoryInstance.
+		GetHandleChangedAt(input.UserUUID)
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	if next := changedAt.Add(handleChangeCooldown); now.Before(next) {
+		return &customerrors.ErrInvalidInput{
+			Message: fmt.Sprintf("the handle can be changed again after %v",
+				next.Format(time.RFC3339))}
+	}
+
+	return abstractions.UserRepositoryInstance.
+		UpdateHandle(input.UserUUID, handle, now)
+}
+
+// A use case for replacing a user's bio, location and links.
+// Expects access token deserialization beforehand.
+// Validates the passed uuid and profile fields.
+// May return ErrInvalidInput, ErrInternal, ErrNotFound on failure.
+func UpdateProfile(input models.UpdateProfileInput) error {
+	if check := utility.IsValidUUID(input.UserUUID); !check {
+		return customerrors.InvalidField("uuid", "")
+	}
+	if !utility.IsValidStructField(input, "Bio") {
+		return customerrors.InvalidField("bio", "under 500 chars")
+	}
+	if !utility.IsValidStructField(input, "Location") {
+		return customerrors.InvalidField("location", "under 100 chars")
+	}
+	if !utility.IsValidStructField(input, "Links") {
+		return customerrors.InvalidField("links", "at most 5 urls under 200 chars")
+	}
+
+	return abstractions.UserRepositoryInstance.UpdateProfile(
+		input.UserUUID, input.Bio, input.Location, input.Links)
+}
+
+// A use case for changing a user's banner (raw bytes).
+// Expects access token deserialization beforehand.
+// Validates the passed uuid and image.
+// May return ErrInvalidInput, ErrInternal, ErrNotFound on failure.
+func ChangeBanner(input models.ChangeBannerInput) error {
+	if check := utility.IsValidUUID(input.UserUUID); !check {
+		return customerrors.InvalidField("uuid", "")
+	}
+
+	if len(input.Image) == 0 || len(input.Image) > 5*1024*1024 {
+		return &customerrors.ErrInvalidInput{
+			Message: "invalid image, must be a png, a webp or a jpg (under 5 megabytes)"}
+	}
+
+	imageWebP, err := utility.ConvertAndResizeImageToWebP(input.Image, 1500, 500)
+	if err != nil {
+		return &customerrors.ErrInvalidInput{
+			Message: "invalid image, must be a png, a webp or a jpg (under 5 megabytes)"}
+	}
+
+	return putBlob(userBannerKey(input.UserUUID), imageWebP)
+}
+
+// A use case for a user's banner.
+// Expects access token deserialization beforehand.
+// Validates the passed uuid or handle.
+// May return ErrInvalidInput, ErrInternal, ErrNotFound on failure.
+func GetBanner(input models.GetBannerInput) ([]byte, error) {
+	userId, err := resolveUserId(input.UserUUID)
+	if err != nil {
+		return nil, err
+	}
+	if input.RequesterUUID != "" && input.RequesterUUID != userId {
+		err := checkNotBlocked(input.RequesterUUID, userId,
+			&customerrors.ErrNotFound{Message: "user not found"})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	exists, err := abstractions.UserRepositoryInstance.DoesUserExist(userId)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, &customerrors.ErrNotFound{Message: "user not found"}
+	}
+
+	banner, err := readBlob(userBannerKey(userId))
+	if err != nil {
+		return nil, err
+	}
+	if len(banner) == 0 {
+		return nil, &customerrors.ErrNotFound{Message: "banner not found"}
+	}
+	return banner, nil
+}
+
+// Returns the uuid of a user given either the uuid or the @handle.
+// May return ErrInvalidInput, ErrInternal, ErrNotFound on failure.
+func resolveUserId(idOrHandle string) (string, error) {
+	if utility.IsValidUUID(idOrHandle) {
+		return idOrHandle, nil
+	}
+
+	handle := strings.ToLower(strings.TrimPrefix(idOrHandle, "@"))
+	if !handleRegexp.MatchString(handle) {
+		return "", customerrors.InvalidField("id", "uuid or @handle")
+	}
+
+	return abstractions.UserRepositoryInstance.GetUUIDByHandle(handle)
+}
@@ -0,0 +1,52 @@
+package usecases
+
+import (
+	"spotigram/internal/customerrors"
+	"spotigram/internal/service/abstractions"
+	"spotigram/internal/service/models"
+	"spotigram/internal/utility"
+)
+
+// A use case to change the role of a user.
+// The user's current access tokens are rejected until refreshed.
+// Expects access token deserialization beforehand, admins only.
+// May return ErrInvalidInput, ErrForbidden, ErrInternal, ErrNotFound on failure.
+func SetRole(input models.SetRoleInput) error {
+	if err := requireRole(input.UserRole, models.RoleAdmin); err != nil {
+		return err
+	}
+	if check := utility.IsValidUUID(input.TargetId); !check {
+		return customerrors.InvalidField("id", "")
+	}
+	if !utility.IsValidStructField(input, "Role") {
+		return customerrors.InvalidField("role", "user, artist, moderator or admin")
+	}
+	if input.TargetId == input.UserId {
+		return &customerrors.ErrInvalidInput{
+			Message: "cannot change your own role"}
+	}
+
+	err := abstractions.UserRepositoryInstance.UpdateRole(input.TargetId, input.Role)
+	if err != nil {
+		return err
+	}
+
+	return recordModeration(input.UserId, "set-role",
+		models.ReportTargetUser, input.TargetId, input.Role)
+}
+
+// Returns ErrForbidden unless the role is one of the roles.
+func requireRole(role string, roles ...string) error {
+	for _, r := range roles {
+		if role == r {
+			return nil
+		}
+	}
+	return &customerrors.ErrForbidden{
+		Message: "insufficient role"}
+}
+
+// Reports whether the user owns a resource or is an admin.
+func isOwnerOrAdmin(ownerId string, userId string, role string) bool {
+	return ownerId == userId || role == models.RoleAdmin
+}
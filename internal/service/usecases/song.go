@@ -1,6 +1,8 @@
 package usecases
 
 import (
+	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"spotigram/internal/customerrors"
@@ -9,107 +11,207 @@ import (
 	"spotigram/internal/utility"
 	"strconv"
 	"strings"
+	"time"
 )
 
+// The renditions every uploaded song is encoded into
+// for adaptive bitrate streaming.
+var songRenditions = []utility.HLSRendition{
+	{Name: "64k", Bitrate: 64000},
+	{Name: "128k", Bitrate: 128000},
+	{Name: "256k", Bitrate: 256000},
+}
+
+// The rendition the master playlist of a song is stored under.
+const masterRendition = "master"
+
 // A use case to get the songs list.
-func GetSongs(gsi models.GetSongsInput) ([]models.Song, error) {
-	if check := gsi.Offset >= 0; !check {
-		return nil, &customerrors.ErrInvalidInput{
-			Message: "invalid \"offset\""}
+func GetSongs(gsi models.GetSongsInput) (*models.Page[models.Song], error) {
+	limit, err := pageLimit(gsi.Limit)
+	if err != nil {
+		return nil, err
 	}
 	if !utility.IsValidStructField(gsi, "SongNameFilter") {
-		return nil, &customerrors.ErrInvalidInput{
-			Message: "invalid \"songname_filter\" (empty or under 100 chars)"}
+		return nil, customerrors.InvalidField("songname_filter", "empty or under 100 chars")
 	}
 	if gsi.CreatorIdFilter != "" {
 		if check := utility.IsValidUUID(gsi.CreatorIdFilter); !check {
-			return nil, &customerrors.ErrInvalidInput{
-				Message: "invalid \"creatorid_filter\""}
+			return nil, customerrors.InvalidField("creatorid_filter", "")
 		}
 	}
 
 	songs, err :=
 		abstractions.SongRepositoryInstance.GetSongs(
-			gsi.Offset, gsi.SongNameFilter, gsi.CreatorIdFilter)
+			gsi.Cursor, limit, gsi.SongNameFilter, gsi.CreatorIdFilter)
 	if err != nil {
 		return nil, err
 	}
 	return songs, nil
 }
 
-// A use case to get the songs list.
+// A use case to get a song, hidden songs are only
+// found by their creator, moderators and admins.
+// May return ErrInvalidInput, ErrInternal, ErrNotFound on failure.
 func GetSongInfo(gsi models.GetSongInfoInput) (*models.Song, error) {
 	if check := utility.IsValidUUID(gsi.SongId); !check {
-		return nil, &customerrors.ErrInvalidInput{
-			Message: "invalid \"uuid\""}
+		return nil, customerrors.InvalidField("uuid", "")
 	}
-	song, err :=
-		abstractions.SongRepositoryInstance.GetSongInfo(gsi.SongId)
+	song, err := getVisibleSong(gsi.SongId, gsi.UserId, gsi.UserRole)
 	if err != nil {
 		return nil, err
 	}
 	return song, nil
 }
 
-// A use case to get the songs list.
-func GetSongFile(gsi models.GetSongFileInput) ([]byte, error) {
+// Returns a song unless it is hidden from the user, hidden songs
+// are only found by their creator, moderators and admins.
+// May return ErrInternal or ErrNotFound on failure.
+func getVisibleSong(songId string, userId string, userRole string) (*models.Song, error) {
+	song, err := abstractions.SongRepositoryInstance.GetSongInfo(songId)
+	if err != nil {
+		return nil, err
+	}
+	if song.Hidden && song.CreatorId != userId && requireModerator(userRole) != nil {
+		return nil, &customerrors.ErrNotFound{Message: "no such song"}
+	}
+	return song, nil
+}
+
+// A use case to describe the original file of a song,
+// its format is detected from the first bytes of the file.
+// May return ErrInvalidInput, ErrInternal, ErrNotFound on failure.
+func GetSongFileInfo(gsi models.GetSongFileInput) (*models.SongFileInfo, error) {
 	if check := utility.IsValidUUID(gsi.SongId); !check {
-		return nil, &customerrors.ErrInvalidInput{
-			Message: "invalid \"uuid\""}
+		return nil, customerrors.InvalidField("uuid", "")
+	}
+	song, err := getVisibleSong(gsi.SongId, gsi.UserId, gsi.UserRole)
+	if err != nil {
+		return nil, err
 	}
-	file, err :=
-		abstractions.SongRepositoryInstance.GetSongFile(gsi.SongId)
+	size, err := abstractions.BlobStoreInstance.Stat(songFileKey(gsi.SongId))
 	if err != nil {
 		return nil, err
 	}
-	return file, nil
+
+	format := utility.AudioFormatMP3
+	if size > 0 {
+		// Enough for every signature DetectAudioFormat looks for
+		headSize := int64(64)
+		if size < headSize {
+			headSize = size
+		}
+		head, err := abstractions.BlobStoreInstance.GetRange(
+			songFileKey(gsi.SongId), 0, headSize)
+		if err != nil {
+			return nil, err
+		}
+		defer head.Close()
+		data, err := io.ReadAll(head)
+		if err != nil {
+			return nil, &customerrors.ErrInternal{Message: err.Error()}
+		}
+		if detected, ok := utility.DetectAudioFormat(data); ok {
+			format = detected
+		}
+	}
+
+	return &models.SongFileInfo{
+		Name:        song.Name + "." + format,
+		Format:      format,
+		ContentType: utility.AudioMIMETypes[format],
+		Size:        size,
+		ETag:        fmt.Sprintf(`"%s-%x"`, song.Id, size),
+	}, nil
 }
 
+// A use case to open length bytes of the original file of a song
+// starting at offset, the caller must close the stream.
+// The range is expected to come from GetSongFileInfo.
+// May return ErrInvalidInput, ErrInternal, ErrNotFound on failure.
+func GetSongFile(gsi models.GetSongFileInput, offset int64, length int64) (io.ReadCloser, error) {
+	if check := utility.IsValidUUID(gsi.SongId); !check {
+		return nil, customerrors.InvalidField("uuid", "")
+	}
+	if length == 0 {
+		return io.NopCloser(strings.NewReader("")), nil
+	}
+	return abstractions.BlobStoreInstance.GetRange(songFileKey(gsi.SongId), offset, length)
+}
+
+// A use case to get the picture of a song,
+// songs without an embedded picture fall back to their album cover.
+// Validates the passed uuid.
+// May return ErrInvalidInput, ErrInternal, ErrNotFound on failure.
 func GetSongPicture(input models.GetSongPictureInput) ([]byte, error) {
 	if check := utility.IsValidUUID(input.SongId); !check {
-		return nil, &customerrors.ErrInvalidInput{
-			Message: "invalid \"uuid\""}
+		return nil, customerrors.InvalidField("uuid", "")
 	}
-	pic, err :=
-		abstractions.SongRepositoryInstance.GetPicture(input.SongId)
+	song, err := getVisibleSong(input.SongId, input.UserId, input.UserRole)
 	if err != nil {
 		return nil, err
 	}
+	pic, err := readBlob(songPictureKey(input.SongId))
+	if err != nil {
+		return nil, err
+	}
+	if len(pic) > 0 {
+		return pic, nil
+	}
+
+	if song.AlbumId != "" {
+		cover, err := readBlob(albumCoverKey(song.AlbumId))
+		if err != nil {
+			return nil, err
+		}
+		if len(cover) > 0 {
+			return cover, nil
+		}
+	}
 	return pic, nil
 }
 
 // A use case to delete a song.
 // Expects access token deserialization beforehand.
 // Validates the passed uuid.
-// May return ErrInvalidInput, ErrInternal, ErrNotFound on failure.
+// May return ErrInvalidInput, ErrForbidden, ErrInternal, ErrNotFound on failure.
 func DeleteSong(input models.DeleteSongInput) error {
 	if check := utility.IsValidUUID(input.UserId); !check {
-		return &customerrors.ErrInvalidInput{
-			Message: "invalid \"uuid\""}
+		return customerrors.InvalidField("uuid", "")
 	}
 	if check := utility.IsValidUUID(input.SongId); !check {
-		return &customerrors.ErrInvalidInput{
-			Message: "invalid \"uuid\""}
+		return customerrors.InvalidField("uuid", "")
 	}
 
-	user, err := abstractions.UserRepositoryInstance.
-		GetUser(input.UserId)
+	song, err := abstractions.SongRepositoryInstance.
+		GetSongInfo(input.SongId)
 	if err != nil {
 		return err
 	}
 
-	if !user.Verified {
-		return &customerrors.ErrUnauthorized{
-			Message: "you are not verified",
-		}
+	if !isOwnerOrAdmin(song.CreatorId, input.UserId, input.UserRole) {
+		return &customerrors.ErrForbidden{
+			Message: "you are not the creator"}
 	}
 
-	/*
-		err = abstractions.PlaylistSongRepositoryInstance.
-			DeleteSong(input.SongId)
+	if input.UserRole != models.RoleAdmin {
+		user, err := abstractions.UserRepositoryInstance.
+			GetUser(input.UserId)
 		if err != nil {
 			return err
-		}*/
+		}
+
+		if !user.Verified {
+			return &customerrors.ErrForbidden{
+				Message: "you are not verified",
+			}
+		}
+	}
+
+	err = abstractions.PlaylistSongRepositoryInstance.
+		DeleteSong(input.SongId)
+	if err != nil {
+		return err
+	}
 
 	err = abstractions.SongRepositoryInstance.
 		DeleteSong(input.SongId)
@@ -118,129 +220,370 @@ func DeleteSong(input models.DeleteSongInput) error {
 	}
 	err = abstractions.SongChunkRepositoryInstance.
 		DeleteSongChunks(input.SongId)
-	return err
+	if err != nil {
+		return err
+	}
+	return deleteSongBlobs(input.SongId)
 }
 
-// A use case to upload a song
-// Returns the uuid of the recipient
-func AddSong(input models.AddSongInput) error {
-
-	valid := utility.IsValidStructField(input, "Name")
-	if !valid {
-		return &customerrors.ErrInvalidInput{
-			Message: "invalid \"name\" (must be 5-100 chars long)"}
+// A use case to upload a song, the song is transcoded
+// and chunked in the background by the ingestion workers.
+// Metadata missing from the input is taken from the tags of the file,
+// songs uploaded into an album take its name.
+// Expects access token deserialization beforehand.
+// Returns the queued upload job.
+// May return ErrInvalidInput, ErrForbidden, ErrInternal, ErrNotFound on failure.
+func AddSong(input models.AddSongInput) (*models.UploadJob, error) {
+	task, err := prepareSongUpload(input)
+	if err != nil {
+		return nil, err
 	}
+	jobs, err := queueSongUploads([]ingestionTask{*task})
+	if err != nil {
+		return nil, err
+	}
+	return &jobs[0], nil
+}
 
+// Validates a song upload and resolves its metadata.
+// Returns the ingestion task of the upload.
+func prepareSongUpload(input models.AddSongInput) (*ingestionTask, error) {
 	if check := utility.IsValidUUID(input.UserId); !check {
-		return &customerrors.ErrInvalidInput{
+		return nil, &customerrors.ErrInvalidInput{
 			Message: "invalid user \"uuid\""}
 	}
-	var albumCover []byte = nil
-	if utility.IsValidMP3(input.File) {
-		pic, _, err := utility.GetMP3AlbumCover(input.File)
+	if err := requireRole(input.UserRole, models.RoleArtist, models.RoleAdmin); err != nil {
+		return nil, err
+	}
+	format, ok := utility.DetectAudioFormat(input.File)
+	if !ok {
+		return nil, customerrors.InvalidField("file",
+			"unsupported audio format, must be mp3, flac, ogg, opus, wav, m4a or aac")
+	}
+
+	if input.AlbumId != "" {
+		album, err := getOwnAlbum(input.UserId, input.UserRole, input.AlbumId)
 		if err != nil {
-			return &customerrors.ErrInternal{
-				Message: "cannot read tags of the song",
-			}
+			return nil, err
+		}
+		if input.Album == "" {
+			input.Album = album.Name
 		}
+	}
 
-		if pic != nil {
-			if len(pic) == 0 || len(pic) > 2*1024*1024 {
-				return &customerrors.ErrInvalidInput{
-					Message: "invalid album cover, must be a png or a jpg (under 2 megabytes)"}
-			}
+	tags, err := utility.ReadAudioTags(input.File)
+	if err != nil {
+		return nil, &customerrors.ErrInvalidInput{
+			Message: "cannot read tags of the song"}
+	}
+	applyAudioTags(&input, tags)
 
-			albumCover, err = utility.ConvertAndResizeImageToWebP(pic, 512, 512)
-			if err != nil {
-				return &customerrors.ErrInvalidInput{
-					Message: "invalid album cover, must be a png or a jpg (under 2 megabytes)"}
-			}
-		}
+	if !utility.IsValidStructField(input, "Name") {
+		return nil, customerrors.InvalidField("name", "must be 5-100 chars long")
+	}
+	if !utility.IsValidStructField(input, "Artist") {
+		return nil, customerrors.InvalidField("artist", "under 100 chars")
+	}
+	if !utility.IsValidStructField(input, "Album") {
+		return nil, customerrors.InvalidField("album", "under 100 chars")
+	}
+	if !utility.IsValidStructField(input, "TrackNumber") {
+		return nil, customerrors.InvalidField("track_number", "must be 0-999")
+	}
+	if !utility.IsValidStructField(input, "Year") {
+		return nil, customerrors.InvalidField("year", "must be 0-9999")
+	}
+	if !utility.IsValidStructField(input, "Genre") {
+		return nil, customerrors.InvalidField("genre", "under 50 chars")
 	}
 
-	song := models.Song{
+	now := time.Now().UTC()
+	job := models.UploadJob{
 		Id:        utility.GenerateUUID(),
-		CreatorId: input.UserId,
-		Name:      input.Name,
-		Length:    0,
+		UserId:    input.UserId,
+		SongId:    utility.GenerateUUID(),
+		SongName:  input.Name,
+		Status:    models.UploadJobQueued,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	return &ingestionTask{
+		job: job,
+		song: models.Song{
+			Id:          job.SongId,
+			CreatorId:   input.UserId,
+			Name:        input.Name,
+			Artist:      input.Artist,
+			Album:       input.Album,
+			TrackNumber: input.TrackNumber,
+			Year:        input.Year,
+			Genre:       input.Genre,
+			AlbumId:     input.AlbumId,
+		},
+		format:  format,
+		picture: tags.Picture,
+		file:    input.File,
+	}, nil
+}
+
+// Saves the upload jobs of the tasks, spools their files into the
+// blob store and hands every task to the ingestion workers,
+// or none of them if they do not all fit the queue.
+// Queued tasks no longer hold their file.
+// Returns the queued upload jobs, in the order of the tasks.
+func queueSongUploads(tasks []ingestionTask) ([]models.UploadJob, error) {
+	jobs := []models.UploadJob{}
+	for i := range tasks {
+		err := abstractions.UploadJobRepositoryInstance.AddJob(tasks[i].job)
+		if err == nil {
+			jobs = append(jobs, tasks[i].job)
+			err = putBlob(songFileKey(tasks[i].job.SongId), tasks[i].file)
+		}
+		if err != nil {
+			failUploadJobs(jobs, "upload cancelled")
+			return nil, err
+		}
+		tasks[i].file = nil
+	}
+
+	if !enqueueIngestion(tasks...) {
+		failUploadJobs(jobs, "ingestion queue is full")
+		return nil, &customerrors.ErrInternal{
+			Message: "ingestion queue is full, try again later"}
 	}
 
+	return jobs, nil
+}
+
+// Marks upload jobs which never reached the queue as failed
+// and deletes their spooled files.
+func failUploadJobs(jobs []models.UploadJob, errorMessage string) {
+	for _, job := range jobs {
+		_ = abstractions.UploadJobRepositoryInstance.UpdateJobStatus(
+			job.Id, models.UploadJobFailed, errorMessage)
+		_ = abstractions.BlobStoreInstance.Delete(songFileKey(job.SongId))
+	}
+}
+
+// Fills the metadata the uploader left empty from the tags.
+func applyAudioTags(input *models.AddSongInput, tags *utility.AudioTags) {
+	if input.Name == "" {
+		input.Name = tags.Title
+	}
+	if input.Artist == "" {
+		input.Artist = tags.Artist
+	}
+	if input.Album == "" {
+		input.Album = tags.Album
+	}
+	if input.TrackNumber == 0 {
+		input.TrackNumber = tags.Track
+	}
+	if input.Year == 0 {
+		input.Year = tags.Year
+	}
+	if input.Genre == "" {
+		input.Genre = tags.Genre
+	}
+}
+
+// Fills the metadata of a song still missing from the tags
+// ffprobe read, values that do not fit the columns are dropped.
+func applyProbedTags(song *models.Song, tags utility.AudioTags) {
+	if song.Artist == "" && len(tags.Artist) <= 100 {
+		song.Artist = tags.Artist
+	}
+	if song.Album == "" && len(tags.Album) <= 100 {
+		song.Album = tags.Album
+	}
+	if song.TrackNumber == 0 && tags.Track <= 999 {
+		song.TrackNumber = tags.Track
+	}
+	if song.Year == 0 && tags.Year <= 9999 {
+		song.Year = tags.Year
+	}
+	if song.Genre == "" && len(tags.Genre) <= 50 {
+		song.Genre = tags.Genre
+	}
+}
+
+// Transcodes and chunks the song of an upload job, then saves it.
+// Returns the saved song.
+func ingestSong(task ingestionTask) (*models.Song, error) {
+	var albumCover []byte = nil
+	var err error
+	if task.picture != nil {
+		if len(task.picture) == 0 || len(task.picture) > 2*1024*1024 {
+			return nil, &customerrors.ErrInvalidInput{
+				Message: "invalid album cover, must be a png or a jpg (under 2 megabytes)"}
+		}
+
+		albumCover, err = utility.ConvertAndResizeImageToWebP(task.picture, 512, 512)
+		if err != nil {
+			return nil, &customerrors.ErrInvalidInput{
+				Message: "invalid album cover, must be a png or a jpg (under 2 megabytes)"}
+		}
+	}
+
+	song := task.song
+	createdAt := time.Now().UTC()
+	song.CreatedAt = &createdAt
+
 	// Split the song into chunks
 	tempDir, err := os.MkdirTemp("", "song_chunking")
 	if err != nil {
-		return &customerrors.ErrInternal{
+		return nil, &customerrors.ErrInternal{
 			Message: "cannot create temp directory",
 		}
 	}
 	defer os.RemoveAll(tempDir)
 
-	songFileName := tempDir + "/" + song.Id + ".mp3"
-	err = os.WriteFile(songFileName, input.File, 0777)
+	// The upload was spooled into the blob store when it was queued
+	songFileName := tempDir + "/" + song.Id + "." + task.format
+	if err := copyBlobToFile(songFileKey(song.Id), songFileName); err != nil {
+		return nil, err
+	}
+
+	probe, err := utility.ProbeAudio(songFileName)
+	if err != nil {
+		return nil, &customerrors.ErrInvalidInput{
+			Message: "cannot execute ffprobe for song analysis",
+		}
+	}
+	song.Duration = probe.Duration
+	applyProbedTags(&song, probe.Tags)
+
+	waveform, err := utility.ComputeWaveform(songFileName, utility.WaveformPoints)
+	if err != nil {
+		return nil, &customerrors.ErrInvalidInput{
+			Message: "cannot execute ffmpeg for waveform generation",
+		}
+	}
+
+	// First loudnorm pass, the second one runs while encoding
+	loudness, err := utility.MeasureLoudness(songFileName, targetLoudness)
+	if err != nil {
+		return nil, &customerrors.ErrInvalidInput{
+			Message: "cannot execute ffmpeg for loudness analysis",
+		}
+	}
+	filter := ""
+	if loudness != nil {
+		trackGain := loudness.TrackGain()
+		song.Loudness, song.TruePeak, song.TrackGain =
+			&loudness.Integrated, &loudness.TruePeak, &trackGain
+		if normalizeLoudness {
+			filter = loudness.NormalizationFilter(targetLoudness)
+			song.Normalized = true
+		}
+	}
+
+	for _, rendition := range songRenditions {
+		playlist, err := chunkSongRendition(tempDir, songFileName, song.Id, rendition, filter)
+		if err != nil {
+			return nil, err
+		}
+
+		if song.Length == 0 {
+			song.Length, err = utility.GetSongLengthFromM3U8(playlist)
+			if err != nil {
+				return nil, &customerrors.ErrInternal{
+					Message: "cannot get song length from a m3u8",
+				}
+			}
+		}
+	}
+
+	err = abstractions.SongChunkRepositoryInstance.AddSongChunk(
+		song.Id, masterRendition, -1,
+		utility.BuildHLSMasterPlaylist(song.Id, songRenditions))
 	if err != nil {
-		return &customerrors.ErrInternal{
-			Message: "cannot put song in the temp directory",
+		return nil, err
+	}
+
+	if albumCover != nil {
+		if err := putBlob(songPictureKey(song.Id), albumCover); err != nil {
+			return nil, err
 		}
 	}
 
-	cmd := exec.Command(
-		"ffmpeg",
+	// The account may have been deleted while the song was processed,
+	// its files are then deleted along with the failed job
+	if _, err := abstractions.UserRepositoryInstance.GetUser(song.CreatorId); err != nil {
+		if customerrors.IsNotFound(err) {
+			return nil, &customerrors.ErrNotFound{Message: "uploader no longer exists"}
+		}
+		return nil, err
+	}
+
+	err = abstractions.SongRepositoryInstance.AddSong(song, waveform)
+	if err != nil {
+		return nil, err
+	}
+
+	return &song, nil
+}
+
+// Encodes a song into a rendition split into 5 second chunks
+// and saves the rendition playlist and chunks.
+// A non empty audio filter is applied while encoding.
+// Returns the rendition playlist.
+func chunkSongRendition(tempDir string, songFileName string, songId string, rendition utility.HLSRendition, filter string) ([]byte, error) {
+	prefix := tempDir + "/" + songId + "_" + rendition.Name
+	args := []string{
 		"-i", songFileName,
-		"-c:a", "libmp3lame",
-		"-b:a", "128k",
-		"-map", "0:0",
+		"-vn",
+		"-map", "0:a:0",
+	}
+	if filter != "" {
+		// loudnorm upsamples to 192 kHz
+		args = append(args, "-af", filter, "-ar", "48000")
+	}
+	args = append(args,
+		"-c:a", "aac",
+		"-b:a", strconv.Itoa(rendition.Bitrate/1000)+"k",
 		"-f", "segment",
 		"-segment_time", "5",
-		"-segment_list", tempDir+"/"+song.Id+".m3u8",
+		"-segment_list", prefix+".m3u8",
 		"-segment_format", "mpegts",
-		tempDir+"/"+song.Id+"_"+"%d.ts",
+		prefix+"_%d.ts",
 	)
-	err = cmd.Run()
+	err := exec.Command("ffmpeg", args...).Run()
 	if err != nil {
-		return &customerrors.ErrInvalidInput{
+		return nil, &customerrors.ErrInvalidInput{
 			Message: "cannot execute ffmpeg for song chunking",
 		}
 	}
 
 	// Save header
-	headerFile, err := os.ReadFile(tempDir + "/" + song.Id + ".m3u8")
+	headerFile, err := os.ReadFile(prefix + ".m3u8")
 	if err != nil {
-		return &customerrors.ErrInternal{
+		return nil, &customerrors.ErrInternal{
 			Message: "cannot read song header file",
 		}
 	}
 
-	song.Length, err = utility.GetSongLengthFromM3U8(headerFile)
-	if err != nil {
-		return &customerrors.ErrInternal{
-			Message: "cannot get song length from a m3u8",
-		}
-	}
-
 	err = abstractions.SongChunkRepositoryInstance.
-		AddSongChunk(song.Id, -1, headerFile)
+		AddSongChunk(songId, rendition.Name, -1, headerFile)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Save chunks
 	for i := 0; true; i++ {
-		headerFile, err := os.ReadFile(tempDir + "/" + song.Id + "_" + strconv.Itoa(i) + ".ts")
+		chunk, err := os.ReadFile(prefix + "_" + strconv.Itoa(i) + ".ts")
 		if err != nil {
 			break
 		}
 		err = abstractions.SongChunkRepositoryInstance.
-			AddSongChunk(song.Id, i, headerFile)
+			AddSongChunk(songId, rendition.Name, i, chunk)
 		if err != nil {
-			return err
+			return nil, err
 		}
 	}
 
-	err = abstractions.SongRepositoryInstance.AddSong(song, albumCover, input.File)
-	if err != nil {
-		return err
-	}
-
-	return nil
+	return headerFile, nil
 }
 
 func UpdateSongName(input models.UpdateSongNameInput) error {
@@ -254,8 +597,7 @@ func UpdateSongName(input models.UpdateSongNameInput) error {
 	}
 	valid := utility.IsValidStructField(input, "Name")
 	if !valid {
-		return &customerrors.ErrInvalidInput{
-			Message: "invalid \"new_name\" (must be 5-100 chars long)"}
+		return customerrors.InvalidField("new_name", "must be 5-100 chars long")
 	}
 
 	song, err := abstractions.SongRepositoryInstance.
@@ -264,8 +606,8 @@ func UpdateSongName(input models.UpdateSongNameInput) error {
 		return err
 	}
 
-	if song.CreatorId != input.UserId {
-		return &customerrors.ErrInvalidInput{
+	if !isOwnerOrAdmin(song.CreatorId, input.UserId, input.UserRole) {
+		return &customerrors.ErrForbidden{
 			Message: "you are not the creator"}
 	}
 
@@ -277,6 +619,14 @@ func UpdateSongName(input models.UpdateSongNameInput) error {
 	return nil
 }
 
+// A use case to get an HLS file of a song, the master playlist
+// <id>.m3u8, a rendition playlist <id>_<rendition>.m3u8
+// or a chunk <id>_<rendition>_<index>.ts. Songs uploaded before
+// adaptive bitrate streaming only have a single rendition
+// served as <id>.m3u8 and <id>_<index>.ts.
+// Fetching the master playlist counts as a stream.
+// Hidden songs are only streamed to their creator, moderators and admins.
+// May return ErrInvalidInput, ErrInternal, ErrNotFound on failure.
 func GetSongChunk(input models.GetSongChunkInput) ([]byte, error) {
 	parts := strings.Split(input.FileName, ".")
 	if len(parts) != 2 {
@@ -284,35 +634,68 @@ func GetSongChunk(input models.GetSongChunkInput) ([]byte, error) {
 			Message: "invalid file name"}
 	}
 
+	leftparts := strings.Split(parts[0], "_")
+	if check := utility.IsValidUUID(leftparts[0]); !check {
+		return nil, &customerrors.ErrInvalidInput{
+			Message: "invalid song id"}
+	}
+	songId := leftparts[0]
+	if _, err := getVisibleSong(songId, input.UserId, input.UserRole); err != nil {
+		return nil, err
+	}
+
 	var resultFile []byte
 	var err error
-	if parts[1] == "m3u8" {
-		if check := utility.IsValidUUID(parts[0]); !check {
-			return nil, &customerrors.ErrInvalidInput{
-				Message: "invalid song id"}
-		}
+	if parts[1] == "m3u8" && len(leftparts) == 1 {
 		resultFile, err = abstractions.SongChunkRepositoryInstance.
-			GetSongChunk(parts[0], -1)
+			GetSongChunk(songId, masterRendition, -1)
+		if customerrors.IsNotFound(err) {
+			resultFile, err = abstractions.SongChunkRepositoryInstance.
+				GetSongChunk(songId, "", -1)
+		}
 		if err != nil {
 			return nil, err
 		}
-		err = abstractions.SongRepositoryInstance.IncrementStreams(parts[0])
+		err = abstractions.SongRepositoryInstance.IncrementStreams(songId)
 		if err != nil {
 			return nil, err
 		}
-	} else if parts[1] == "ts" {
-		leftparts := strings.Split(parts[0], "_")
-		if check := utility.IsValidUUID(leftparts[0]); !check {
+		if input.UserId != "" {
+			err = abstractions.ListenRepositoryInstance.AddListen(models.LastStream{
+				UserId: input.UserId,
+				SongId: songId,
+				Time:   time.Now().UTC().UnixMicro(),
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+	} else if parts[1] == "m3u8" && len(leftparts) == 2 {
+		if !isSongRendition(leftparts[1]) {
 			return nil, &customerrors.ErrInvalidInput{
-				Message: "invalid song id"}
+				Message: "invalid rendition"}
 		}
-		index, err := strconv.Atoi(leftparts[1])
+		resultFile, err = abstractions.SongChunkRepositoryInstance.
+			GetSongChunk(songId, leftparts[1], -1)
 		if err != nil {
+			return nil, err
+		}
+	} else if parts[1] == "ts" && (len(leftparts) == 2 || len(leftparts) == 3) {
+		rendition := ""
+		if len(leftparts) == 3 {
+			rendition = leftparts[1]
+			if !isSongRendition(rendition) {
+				return nil, &customerrors.ErrInvalidInput{
+					Message: "invalid rendition"}
+			}
+		}
+		index, err := strconv.Atoi(leftparts[len(leftparts)-1])
+		if err != nil || index < 0 {
 			return nil, &customerrors.ErrInvalidInput{
 				Message: "invalid chunk id"}
 		}
 		resultFile, err = abstractions.SongChunkRepositoryInstance.
-			GetSongChunk(leftparts[0], index)
+			GetSongChunk(songId, rendition, index)
 		if err != nil {
 			return nil, err
 		}
@@ -323,3 +706,13 @@ func GetSongChunk(input models.GetSongChunkInput) ([]byte, error) {
 
 	return resultFile, nil
 }
+
+// Returns true if the name is one of the song renditions.
+func isSongRendition(name string) bool {
+	for _, r := range songRenditions {
+		if r.Name == name {
+			return true
+		}
+	}
+	return false
+}
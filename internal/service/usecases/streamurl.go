@@ -0,0 +1,123 @@
+package usecases
+
+import (
+	"crypto/rand"
+	"fmt"
+	"log"
+	"spotigram/internal/config"
+	"spotigram/internal/customerrors"
+	"spotigram/internal/service/models"
+	"spotigram/internal/utility"
+	"strings"
+	"time"
+)
+
+// Key signing the HLS file urls handed out by GetSongStreamUrl.
+var streamUrlSecret []byte
+
+// How long signed HLS file urls stay valid on top of the song length.
+var streamUrlTTL = 15 * time.Minute
+
+// Sets the secret and lifetime of signed HLS file urls.
+// Panics without a configured secret outside of development,
+// in development a random one is generated, the urls then
+// do not survive a restart and are only valid on the instance
+// which signed them.
+func SetupStreamUrls(cfg *config.Config) {
+	if cfg.StreamUrl.TTL > 0 {
+		streamUrlTTL = cfg.StreamUrl.TTL
+	}
+	if cfg.StreamUrl.Secret != "" {
+		streamUrlSecret = []byte(cfg.StreamUrl.Secret)
+		return
+	}
+	if cfg.App.Environment != config.EnvironmentDevelopment {
+		panic(fmt.Errorf("stream_url.secret must be set outside of the %s environment",
+			config.EnvironmentDevelopment))
+	}
+	log.Printf("WARNING: stream_url.secret is empty, signed stream urls " +
+		"use a random secret and break on restart and across instances")
+	streamUrlSecret = make([]byte, 32)
+	if _, err := rand.Read(streamUrlSecret); err != nil {
+		panic(err)
+	}
+}
+
+// A use case to get the playlist of a song for players
+// which cannot send the access token cookie. Every url of the
+// playlist is signed for the user and expires after the song
+// length plus the configured lifetime.
+// Counts as a stream just like fetching the master playlist.
+// May return ErrInvalidInput, ErrInternal, ErrNotFound on failure.
+func GetSongStreamUrl(input models.GetSongStreamUrlInput) ([]byte, error) {
+	if check := utility.IsValidUUID(input.SongId); !check {
+		return nil, customerrors.InvalidField("id", "")
+	}
+	song, err := getVisibleSong(input.SongId, input.UserId, input.UserRole)
+	if err != nil {
+		return nil, err
+	}
+
+	playlist, err := GetSongChunk(models.GetSongChunkInput{
+		FileName: song.Id + ".m3u8",
+		UserId:   input.UserId,
+		UserRole: input.UserRole,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	expires := time.Now().Add(streamUrlTTL).
+		Add(time.Duration(song.Length) * time.Second).Unix()
+	query := utility.SignHLSQuery(song.Id, input.UserId, expires, streamUrlSecret)
+	// The playlist is served under /song/, the signed files under /song/signed/
+	return utility.SignHLSPlaylist(playlist, "signed/", query), nil
+}
+
+// A use case to get a rendition playlist or a chunk of a song
+// through a url signed by GetSongStreamUrl, without the access token.
+// Rendition playlists are returned with their chunk urls signed alike.
+// The user must still be active and the song visible to them.
+// May return ErrInvalidInput, ErrForbidden, ErrUnauthorized, ErrInternal,
+// ErrNotFound on failure.
+func GetSignedSongChunk(input models.GetSignedSongChunkInput) ([]byte, error) {
+	name, extension, _ := strings.Cut(input.FileName, ".")
+	songId, rest, _ := strings.Cut(name, "_")
+	if check := utility.IsValidUUID(songId); !check {
+		return nil, &customerrors.ErrInvalidInput{
+			Message: "invalid song id"}
+	}
+	if rest == "" && extension == "m3u8" {
+		// The master playlist is only handed out by GetSongStreamUrl
+		return nil, &customerrors.ErrInvalidInput{
+			Message: "invalid file name"}
+	}
+
+	if !utility.ValidHLSSignature(songId, input.UserId, input.Expires,
+		input.Signature, streamUrlSecret) {
+		return nil, &customerrors.ErrForbidden{Message: "invalid signature"}
+	}
+	if time.Now().Unix() > input.Expires {
+		return nil, &customerrors.ErrForbidden{Message: "url expired"}
+	}
+
+	// The url outlives the token it was signed for, so the user
+	// is looked up again to refuse suspended and deleted accounts
+	user, err := getActiveUser(input.UserId)
+	if err != nil {
+		return nil, err
+	}
+	file, err := GetSongChunk(models.GetSongChunkInput{
+		FileName: input.FileName,
+		UserId:   user.Id,
+		UserRole: user.Role,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if extension == "m3u8" {
+		query := utility.SignHLSQuery(songId, input.UserId, input.Expires, streamUrlSecret)
+		return utility.SignHLSPlaylist(file, "", query), nil
+	}
+	return file, nil
+}
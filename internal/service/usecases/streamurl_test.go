@@ -0,0 +1,94 @@
+package usecases
+
+import (
+	"net/url"
+	"spotigram/internal/config"
+	"spotigram/internal/customerrors"
+	"spotigram/internal/service/models"
+	"spotigram/internal/utility"
+	"testing"
+	"time"
+)
+
+const (
+	testSongId = "7f1d4a5e-0c55-4b4e-9a8f-2d1c6b0e3a11"
+	testUserId = "0b6f9c2e-52a4-4d2b-8f47-9e3d1a7c5b20"
+)
+
+func TestSetupStreamUrlsRequiresSecretOutsideDevelopment(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("empty secret was accepted in production")
+		}
+	}()
+	SetupStreamUrls(&config.Config{App: config.App{Environment: "production"}})
+}
+
+func TestSetupStreamUrlsGeneratesSecretInDevelopment(t *testing.T) {
+	SetupStreamUrls(&config.Config{App: config.App{Environment: config.EnvironmentDevelopment}})
+	if len(streamUrlSecret) != 32 {
+		t.Fatalf("generated secret has %d bytes, want 32", len(streamUrlSecret))
+	}
+
+	SetupStreamUrls(&config.Config{StreamUrl: config.StreamUrl{Secret: "configured"}})
+	if string(streamUrlSecret) != "configured" {
+		t.Fatalf("secret is %q, want the configured one", streamUrlSecret)
+	}
+}
+
+// The checks below fail before any repository is used.
+func TestGetSignedSongChunkRejectsInvalidUrls(t *testing.T) {
+	streamUrlSecret = []byte("test secret")
+	fileName := testSongId + "_128k_0.ts"
+	valid := time.Now().Add(time.Hour).Unix()
+	expired := time.Now().Add(-time.Minute).Unix()
+	sign := func(songId, userId string, expires int64) models.GetSignedSongChunkInput {
+		values, err := url.ParseQuery(utility.SignHLSQuery(songId, userId, expires, streamUrlSecret))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return models.GetSignedSongChunkInput{
+			FileName:  fileName,
+			UserId:    values.Get("user"),
+			Expires:   expires,
+			Signature: values.Get("signature"),
+		}
+	}
+
+	tampered := func(input models.GetSignedSongChunkInput, f func(*models.GetSignedSongChunkInput)) models.GetSignedSongChunkInput {
+		f(&input)
+		return input
+	}
+
+	tests := []struct {
+		name  string
+		input models.GetSignedSongChunkInput
+		code  string
+	}{
+		{"expired", sign(testSongId, testUserId, expired), customerrors.CodeForbidden},
+		{"other user", tampered(sign(testSongId, testUserId, valid), func(i *models.GetSignedSongChunkInput) {
+			i.UserId = "9a3c2b1d-8e7f-4a6b-9c5d-1e2f3a4b5c6d"
+		}), customerrors.CodeForbidden},
+		{"other song", tampered(sign(testSongId, testUserId, valid), func(i *models.GetSignedSongChunkInput) {
+			i.FileName = "9a3c2b1d-8e7f-4a6b-9c5d-1e2f3a4b5c6d_128k_0.ts"
+		}), customerrors.CodeForbidden},
+		{"extended expiry", tampered(sign(testSongId, testUserId, valid), func(i *models.GetSignedSongChunkInput) {
+			i.Expires += 3600
+		}), customerrors.CodeForbidden},
+		{"master playlist", tampered(sign(testSongId, testUserId, valid), func(i *models.GetSignedSongChunkInput) {
+			i.FileName = testSongId + ".m3u8"
+		}), customerrors.CodeInvalidInput},
+		{"invalid song id", tampered(sign(testSongId, testUserId, valid), func(i *models.GetSignedSongChunkInput) {
+			i.FileName = "song_128k_0.ts"
+		}), customerrors.CodeInvalidInput},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := GetSignedSongChunk(tt.input)
+			if code := customerrors.Code(err); code != tt.code {
+				t.Fatalf("GetSignedSongChunk() error = %v (%s), want %s", err, code, tt.code)
+			}
+		})
+	}
+}
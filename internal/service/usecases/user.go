@@ -10,18 +10,17 @@ import (
 // A use case to get a user list.
 // Expects access token deserialization beforehand.
 // May return ErrInvalidInput, ErrInternal, ErrNotFound on failure.
-func GetUsers(input models.GetUsersInput) ([]models.User, error) {
-	if check := input.Offset >= 0; !check {
-		return nil, &customerrors.ErrInvalidInput{
-			Message: "invalid \"offset\""}
+func GetUsers(input models.GetUsersInput) (*models.Page[models.User], error) {
+	limit, err := pageLimit(input.Limit)
+	if err != nil {
+		return nil, err
 	}
 	if !utility.IsValidStructField(input, "UserNameFilter") {
-		return nil, &customerrors.ErrInvalidInput{
-			Message: "invalid \"username_filter\" (empty or under 100 chars)"}
+		return nil, customerrors.InvalidField("username_filter", "empty or under 100 chars")
 	}
 	users, err :=
 		abstractions.UserRepositoryInstance.GetUsers(
-			input.Offset, input.UserNameFilter)
+			input.Cursor, limit, input.UserNameFilter, input.RequesterUUID)
 	if err != nil {
 		return nil, err
 	}
@@ -31,12 +30,20 @@ func GetUsers(input models.GetUsersInput) ([]models.User, error) {
 
 // A use case for current user info.
 // Expects access token deserialization beforehand.
-// Validates the passed uuid.
+// Validates the passed uuid or handle.
 // May return ErrInvalidInput, ErrInternal, ErrNotFound on failure.
 func GetUserInfo(mii models.GetUserInfoInput) (*models.User, error) {
-	if check := utility.IsValidUUID(mii.UserUUID); !check {
-		return nil, &customerrors.ErrInvalidInput{
-			Message: "invalid \"uuid\""}
+	userId, err := resolveUserId(mii.UserUUID)
+	if err != nil {
+		return nil, err
+	}
+	mii.UserUUID = userId
+	if mii.RequesterUUID != "" && mii.RequesterUUID != mii.UserUUID {
+		err := checkNotBlocked(mii.RequesterUUID, mii.UserUUID,
+			&customerrors.ErrNotFound{Message: "user not found"})
+		if err != nil {
+			return nil, err
+		}
 	}
 	user, err :=
 		abstractions.UserRepositoryInstance.GetUser(mii.UserUUID)
@@ -48,12 +55,20 @@ func GetUserInfo(mii models.GetUserInfoInput) (*models.User, error) {
 
 // A use case for current user's public key.
 // Expects access token deserialization beforehand.
-// Validates the passed uuid.
+// Validates the passed uuid or handle.
 // May return ErrInvalidInput, ErrInternal, ErrNotFound on failure.
 func GetPublicKey(mii models.GetPublicKeyInput) (string, error) {
-	if check := utility.IsValidUUID(mii.UserUUID); !check {
-		return "", &customerrors.ErrInvalidInput{
-			Message: "invalid \"uuid\""}
+	userId, err := resolveUserId(mii.UserUUID)
+	if err != nil {
+		return "", err
+	}
+	mii.UserUUID = userId
+	if mii.RequesterUUID != "" && mii.RequesterUUID != mii.UserUUID {
+		err := checkNotBlocked(mii.RequesterUUID, mii.UserUUID,
+			&customerrors.ErrNotFound{Message: "user not found"})
+		if err != nil {
+			return "", err
+		}
 	}
 	key, err :=
 		abstractions.UserRepositoryInstance.GetPublicKey(mii.UserUUID)
@@ -63,17 +78,37 @@ func GetPublicKey(mii models.GetPublicKeyInput) (string, error) {
 	return key, nil
 }
 
-// A use case for current user's public key.
+// A use case for a user's picture, hidden by the user's
+// picture visibility from other requesters.
 // Expects access token deserialization beforehand.
-// Validates the passed uuid.
+// Validates the passed uuid or handle.
 // May return ErrInvalidInput, ErrInternal, ErrNotFound on failure.
 func GetPicture(mii models.GetPictureInput) ([]byte, error) {
-	if check := utility.IsValidUUID(mii.UserUUID); !check {
-		return nil, &customerrors.ErrInvalidInput{
-			Message: "invalid \"uuid\""}
+	userId, err := resolveUserId(mii.UserUUID)
+	if err != nil {
+		return nil, err
+	}
+	mii.UserUUID = userId
+	if mii.RequesterUUID != "" && mii.RequesterUUID != mii.UserUUID {
+		err := checkNotBlocked(mii.RequesterUUID, mii.UserUUID,
+			&customerrors.ErrNotFound{Message: "user not found"})
+		if err != nil {
+			return nil, err
+		}
+
+		privacy, err := abstractions.UserRepositoryInstance.GetPrivacy(mii.UserUUID)
+		if err != nil {
+			return nil, err
+		}
+		visible, err := isVisibleTo(privacy.PictureVisibility, mii.UserUUID, mii.RequesterUUID)
+		if err != nil {
+			return nil, err
+		}
+		if !visible {
+			return nil, &customerrors.ErrNotFound{Message: "picture not found"}
+		}
 	}
-	pic, err :=
-		abstractions.UserRepositoryInstance.GetPicture(mii.UserUUID)
+	pic, err := readBlob(userPictureKey(mii.UserUUID))
 	if err != nil {
 		return nil, err
 	}
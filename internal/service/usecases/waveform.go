@@ -0,0 +1,92 @@
+package usecases
+
+import (
+	"os"
+	"spotigram/internal/customerrors"
+	"spotigram/internal/service/abstractions"
+	"spotigram/internal/service/models"
+	"spotigram/internal/utility"
+)
+
+// A use case to get the waveform peaks of a song as signed bytes,
+// min0, max0, min1, max1...
+// Validates the passed uuid and format.
+// May return ErrInvalidInput, ErrInternal, ErrNotFound on failure.
+func GetSongWaveform(input models.GetSongWaveformInput) ([]byte, error) {
+	if check := utility.IsValidUUID(input.SongId); !check {
+		return nil, customerrors.InvalidField("id", "")
+	}
+	if input.Format != "" && input.Format != "binary" && input.Format != "json" {
+		return nil, customerrors.InvalidField("format", "binary or json")
+	}
+	if _, err := getVisibleSong(input.SongId, input.UserId, input.UserRole); err != nil {
+		return nil, err
+	}
+
+	waveform, err := abstractions.SongRepositoryInstance.GetWaveform(input.SongId)
+	if err != nil {
+		return nil, err
+	}
+	if len(waveform) == 0 {
+		return nil, &customerrors.ErrNotFound{Message: "waveform not found"}
+	}
+	return waveform, nil
+}
+
+// Computes the waveform peaks of every song uploaded without them,
+// report is called with the error of every song that fails.
+// Returns the number of songs given a waveform.
+// May return ErrInternal on failure.
+func BackfillWaveforms(report func(songId string, err error)) (int, error) {
+	done := 0
+	afterId := ""
+	for {
+		ids, err := abstractions.SongRepositoryInstance.
+			GetSongIdsWithoutWaveform(afterId, 100)
+		if err != nil {
+			return done, err
+		}
+		if len(ids) == 0 {
+			return done, nil
+		}
+
+		for _, id := range ids {
+			if err := backfillWaveform(id); err != nil {
+				report(id, err)
+				continue
+			}
+			done++
+		}
+		afterId = ids[len(ids)-1]
+	}
+}
+
+// Computes and saves the waveform peaks of a song from its file.
+func backfillWaveform(songId string) error {
+	file, err := readBlob(songFileKey(songId))
+	if err != nil {
+		return err
+	}
+	if file == nil {
+		return &customerrors.ErrNotFound{Message: "song file not found"}
+	}
+
+	tempFile, err := os.CreateTemp("", "song_waveform")
+	if err != nil {
+		return &customerrors.ErrInternal{Message: "cannot create temp file"}
+	}
+	defer os.Remove(tempFile.Name())
+
+	_, err = tempFile.Write(file)
+	tempFile.Close()
+	if err != nil {
+		return &customerrors.ErrInternal{Message: "cannot put song in the temp file"}
+	}
+
+	waveform, err := utility.ComputeWaveform(tempFile.Name(), utility.WaveformPoints)
+	if err != nil {
+		return &customerrors.ErrInternal{Message: "cannot execute ffmpeg for waveform generation"}
+	}
+
+	return abstractions.SongRepositoryInstance.UpdateWaveform(songId, waveform)
+}
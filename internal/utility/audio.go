@@ -0,0 +1,293 @@
+package utility
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	dhowden "github.com/dhowden/tag"
+)
+
+// Audio formats accepted for upload.
+const (
+	AudioFormatMP3  = "mp3"
+	AudioFormatFLAC = "flac"
+	AudioFormatOGG  = "ogg"
+	AudioFormatOpus = "opus"
+	AudioFormatWAV  = "wav"
+	AudioFormatM4A  = "m4a"
+	AudioFormatAAC  = "aac"
+)
+
+// MIME types of the accepted audio formats.
+var AudioMIMETypes = map[string]string{
+	AudioFormatMP3:  "audio/mpeg",
+	AudioFormatFLAC: "audio/flac",
+	AudioFormatOGG:  "audio/ogg",
+	AudioFormatOpus: "audio/ogg; codecs=opus",
+	AudioFormatWAV:  "audio/wav",
+	AudioFormatM4A:  "audio/mp4",
+	AudioFormatAAC:  "audio/aac",
+}
+
+// Tags and embedded cover art of an audio file,
+// fields missing from the file are left empty.
+type AudioTags struct {
+	Title   string
+	Artist  string
+	Album   string
+	Genre   string
+	Year    int
+	Track   int
+	Picture []byte
+}
+
+// Returns the format of an audio file detected
+// by its content, false if it is not supported.
+func DetectAudioFormat(file []byte) (string, bool) {
+	data := skipID3v2(file)
+
+	switch {
+	case hasPrefixAt(data, 0, "fLaC"):
+		return AudioFormatFLAC, true
+
+	case hasPrefixAt(data, 0, "OggS"):
+		// The codec header starts the first page, right after the 28 byte page header
+		if hasPrefixAt(data, 28, "OpusHead") {
+			return AudioFormatOpus, true
+		}
+		if hasPrefixAt(data, 28, "\x01vorbis") {
+			return AudioFormatOGG, true
+		}
+		return "", false
+
+	case hasPrefixAt(data, 0, "RIFF") && hasPrefixAt(data, 8, "WAVE"):
+		return AudioFormatWAV, true
+
+	case hasPrefixAt(data, 4, "ftyp"):
+		if len(data) < 12 {
+			return "", false
+		}
+		switch string(data[8:12]) {
+		case "M4A ", "M4B ", "mp41", "mp42", "isom", "iso2", "dash":
+			return AudioFormatM4A, true
+		}
+		return "", false
+
+	case len(data) > 1 && data[0] == 0xFF && data[1]&0xF6 == 0xF0:
+		// ADTS frame sync with layer 0
+		return AudioFormatAAC, true
+
+	case len(data) > 2 && data[0] == 0xFF && data[1]&0xE0 == 0xE0 &&
+		(data[1]>>1)&0x03 == 0x01 && data[2]>>4 != 0x0F:
+		// MPEG frame sync, layer III, valid bitrate index
+		return AudioFormatMP3, true
+
+	case len(data) != len(file):
+		// ID3v2 tags are only found in front of mp3 frames
+		return AudioFormatMP3, true
+	}
+
+	return "", false
+}
+
+// Returns the tags and cover art of an audio file,
+// files without tags return empty ones.
+func ReadAudioTags(file []byte) (*AudioTags, error) {
+	metadata, err := dhowden.ReadFrom(bytes.NewReader(file))
+	if err == dhowden.ErrNoTagsFound {
+		return &AudioTags{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	track, _ := metadata.Track()
+	tags := &AudioTags{
+		Title:  strings.TrimSpace(metadata.Title()),
+		Artist: strings.TrimSpace(metadata.Artist()),
+		Album:  strings.TrimSpace(metadata.Album()),
+		Genre:  strings.TrimSpace(metadata.Genre()),
+		Year:   metadata.Year(),
+		Track:  track,
+	}
+	if pic := metadata.Picture(); pic != nil {
+		tags.Picture = pic.Data
+	}
+	return tags, nil
+}
+
+// Duration and container tags of an audio file read by ffprobe.
+type AudioProbe struct {
+	Duration float64
+	Tags     AudioTags
+}
+
+// Runs ffprobe on an audio file, the tags fill the gaps
+// of formats dhowden/tag cannot read, such as wav.
+func ProbeAudio(path string) (*AudioProbe, error) {
+	out, err := exec.Command(
+		"ffprobe",
+		"-v", "error",
+		"-show_entries", "format=duration:format_tags",
+		"-of", "json",
+		path,
+	).Output()
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Format struct {
+			Duration string            `json:"duration"`
+			Tags     map[string]string `json:"tags"`
+		} `json:"format"`
+	}
+	if err := json.Unmarshal(out, &result); err != nil {
+		return nil, err
+	}
+
+	probe := &AudioProbe{}
+	probe.Duration, _ = strconv.ParseFloat(result.Format.Duration, 64)
+
+	// Tag keys are upper case in vorbis comments and lower case elsewhere
+	tags := map[string]string{}
+	for k, v := range result.Format.Tags {
+		tags[strings.ToLower(k)] = strings.TrimSpace(v)
+	}
+	probe.Tags.Title = tags["title"]
+	probe.Tags.Artist = tags["artist"]
+	probe.Tags.Album = tags["album"]
+	probe.Tags.Genre = tags["genre"]
+	probe.Tags.Year = leadingInt(tags["date"])
+	probe.Tags.Track = leadingInt(tags["track"])
+	return probe, nil
+}
+
+// Loudness of an audio file measured by the loudnorm filter,
+// loudness values in LUFS, peaks in dBTP.
+type Loudness struct {
+	Integrated float64
+	TruePeak   float64
+	Range      float64
+	Threshold  float64
+	Offset     float64
+}
+
+// The loudness ReplayGain 2.0 track gains are relative to, in LUFS.
+const ReplayGainReference = -18.0
+
+// Returns the ReplayGain style gain in dB bringing the track
+// to the reference loudness.
+func (l *Loudness) TrackGain() float64 {
+	return ReplayGainReference - l.Integrated
+}
+
+// Returns the second pass loudnorm filter normalizing
+// the measured file to the target loudness in LUFS.
+func (l *Loudness) NormalizationFilter(target float64) string {
+	return fmt.Sprintf("loudnorm=I=%.1f:TP=-1.5:LRA=11:"+
+		"measured_I=%.2f:measured_TP=%.2f:measured_LRA=%.2f:"+
+		"measured_thresh=%.2f:offset=%.2f:linear=true",
+		target, l.Integrated, l.TruePeak, l.Range, l.Threshold, l.Offset)
+}
+
+// Runs the first loudnorm pass over an audio file,
+// the EBU R128 measurement of the whole file.
+// Returns nil for silent files, which have no loudness.
+func MeasureLoudness(path string, target float64) (*Loudness, error) {
+	cmd := exec.Command(
+		"ffmpeg",
+		"-hide_banner", "-nostats",
+		"-i", path,
+		"-vn",
+		"-af", fmt.Sprintf("loudnorm=I=%.1f:TP=-1.5:LRA=11:print_format=json", target),
+		"-f", "null", "-",
+	)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, err
+	}
+
+	// The measurement is the last json object of the log
+	out := stderr.Bytes()
+	start := bytes.LastIndexByte(out, '{')
+	end := bytes.LastIndexByte(out, '}')
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("no loudnorm measurement in the ffmpeg output")
+	}
+
+	var result struct {
+		InputI      string `json:"input_i"`
+		InputTP     string `json:"input_tp"`
+		InputLRA    string `json:"input_lra"`
+		InputThresh string `json:"input_thresh"`
+		Offset      string `json:"target_offset"`
+	}
+	if err := json.Unmarshal(out[start:end+1], &result); err != nil {
+		return nil, err
+	}
+
+	l := &Loudness{}
+	values := []struct {
+		s string
+		f *float64
+	}{
+		{result.InputI, &l.Integrated},
+		{result.InputTP, &l.TruePeak},
+		{result.InputLRA, &l.Range},
+		{result.InputThresh, &l.Threshold},
+		{result.Offset, &l.Offset},
+	}
+	for _, v := range values {
+		f, err := strconv.ParseFloat(v.s, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid loudnorm measurement %q", v.s)
+		}
+		// Silent files measure as -inf
+		if math.IsInf(f, 0) || math.IsNaN(f) {
+			return nil, nil
+		}
+		*v.f = f
+	}
+	return l, nil
+}
+
+// Returns the integer a string starts with, 0 if none,
+// "2003-05-01" gives 2003 and "3/12" gives 3.
+func leadingInt(s string) int {
+	end := 0
+	for end < len(s) && s[end] >= '0' && s[end] <= '9' {
+		end++
+	}
+	n, _ := strconv.Atoi(s[:end])
+	return n
+}
+
+// Returns true if the data has the prefix at the offset.
+func hasPrefixAt(data []byte, offset int, prefix string) bool {
+	return len(data) >= offset+len(prefix) &&
+		string(data[offset:offset+len(prefix)]) == prefix
+}
+
+// Returns the file without its leading ID3v2 tag.
+func skipID3v2(file []byte) []byte {
+	if !hasPrefixAt(file, 0, "ID3") || len(file) < 10 {
+		return file
+	}
+
+	// The tag size is a 28 bit syncsafe integer excluding the header
+	size := 10 + (int(file[6]&0x7F)<<21 | int(file[7]&0x7F)<<14 |
+		int(file[8]&0x7F)<<7 | int(file[9]&0x7F))
+	if file[5]&0x10 != 0 {
+		size += 10
+	}
+	if size > len(file) {
+		return file[len(file):]
+	}
+	return file[size:]
+}
@@ -0,0 +1,63 @@
+package utility
+
+import (
+	"strings"
+	"testing"
+)
+
+// Returns the parts joined into a fixture file.
+func fixture(parts ...string) []byte {
+	return []byte(strings.Join(parts, ""))
+}
+
+// An ID3v2.4 tag with a 10 byte body, flags as given.
+func id3Tag(flags string) string {
+	return "ID3\x04\x00" + flags + "\x00\x00\x00\x0a" + strings.Repeat("\x00", 10)
+}
+
+// An ogg page header followed by the codec header of the first packet.
+func oggPage(codecHeader string) string {
+	return "OggS" + strings.Repeat("\x00", 24) + codecHeader
+}
+
+func TestDetectAudioFormat(t *testing.T) {
+	mp3Frame := "\xff\xfb\x90\x64" + strings.Repeat("\x00", 8)
+
+	tests := []struct {
+		name   string
+		file   []byte
+		format string
+		ok     bool
+	}{
+		{"flac", fixture("fLaC\x00\x00\x00\x22"), AudioFormatFLAC, true},
+		{"ogg vorbis", fixture(oggPage("\x01vorbis\x00\x00")), AudioFormatOGG, true},
+		{"ogg opus", fixture(oggPage("OpusHead\x01\x02")), AudioFormatOpus, true},
+		{"ogg other codec", fixture(oggPage("Speex   ")), "", false},
+		{"wav", fixture("RIFF\x24\x08\x00\x00WAVEfmt "), AudioFormatWAV, true},
+		{"riff avi", fixture("RIFF\x24\x08\x00\x00AVI LIST"), "", false},
+		{"m4a", fixture("\x00\x00\x00\x20ftypM4A \x00\x00\x02\x00"), AudioFormatM4A, true},
+		{"mp4 isom", fixture("\x00\x00\x00\x20ftypisom\x00\x00\x02\x00"), AudioFormatM4A, true},
+		{"quicktime", fixture("\x00\x00\x00\x14ftypqt  \x00\x00\x00\x00"), "", false},
+		{"truncated ftyp", fixture("\x00\x00\x00\x14ftyp"), "", false},
+		{"aac adts", fixture("\xff\xf1\x50\x80\x02\x1f\xfc"), AudioFormatAAC, true},
+		{"mp3 frame", fixture(mp3Frame), AudioFormatMP3, true},
+		{"mp3 bad bitrate", fixture("\xff\xfb\xf0\x64"), "", false},
+		{"mpeg layer ii", fixture("\xff\xfd\x90\x64"), "", false},
+		{"id3 and mp3 frame", fixture(id3Tag("\x00"), mp3Frame), AudioFormatMP3, true},
+		{"id3 with footer", fixture(id3Tag("\x10"), strings.Repeat("\x00", 10), mp3Frame), AudioFormatMP3, true},
+		{"id3 and flac", fixture(id3Tag("\x00"), "fLaC\x00\x00\x00\x22"), AudioFormatFLAC, true},
+		{"id3 only", fixture(id3Tag("\x00")), AudioFormatMP3, true},
+		{"empty", nil, "", false},
+		{"text", fixture("hello, this is not audio"), "", false},
+		{"png", fixture("\x89PNG\r\n\x1a\n\x00\x00\x00\x0dIHDR"), "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			format, ok := DetectAudioFormat(tt.file)
+			if format != tt.format || ok != tt.ok {
+				t.Errorf("DetectAudioFormat() = %q, %v, want %q, %v", format, ok, tt.format, tt.ok)
+			}
+		})
+	}
+}
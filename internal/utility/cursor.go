@@ -0,0 +1,35 @@
+package utility
+
+import (
+	"encoding/base64"
+	"encoding/json"
+)
+
+// Encodes the keyset values of the last item of a page into an opaque cursor.
+func EncodeCursor(keys ...any) string {
+	b, err := json.Marshal(keys)
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// Decodes a cursor made by EncodeCursor into the pointed keys.
+// Returns false if the cursor is malformed or holds a different number of keys.
+func DecodeCursor(cursor string, keys ...any) bool {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return false
+	}
+
+	var values []json.RawMessage
+	if err := json.Unmarshal(b, &values); err != nil || len(values) != len(keys) {
+		return false
+	}
+	for i, v := range values {
+		if err := json.Unmarshal(v, keys[i]); err != nil {
+			return false
+		}
+	}
+	return true
+}
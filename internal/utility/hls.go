@@ -0,0 +1,82 @@
+package utility
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// An audio rendition of a song served over HLS.
+type HLSRendition struct {
+	Name    string
+	Bitrate int
+}
+
+// Returns the master playlist of a song referencing
+// the media playlist of every rendition.
+func BuildHLSMasterPlaylist(songId string, renditions []HLSRendition) []byte {
+	var sb strings.Builder
+	sb.WriteString("#EXTM3U\n#EXT-X-VERSION:3\n")
+	for _, r := range renditions {
+		sb.WriteString("#EXT-X-STREAM-INF:BANDWIDTH=" + strconv.Itoa(r.Bitrate) +
+			",CODECS=\"mp4a.40.2\"\n")
+		sb.WriteString(songId + "_" + r.Name + ".m3u8\n")
+	}
+	return []byte(sb.String())
+}
+
+// Returns the url query granting a user access to the HLS files
+// of a song until the expiry unix time, signed with the secret.
+func SignHLSQuery(songId string, userId string, expires int64, secret []byte) string {
+	return url.Values{
+		"user":      {userId},
+		"expires":   {strconv.FormatInt(expires, 10)},
+		"signature": {hlsSignature(songId, userId, expires, secret)},
+	}.Encode()
+}
+
+// Reports whether the signature was made by SignHLSQuery with the secret
+// for the song, user and expiry. The expiry itself is not checked.
+func ValidHLSSignature(songId string, userId string, expires int64, signature string, secret []byte) bool {
+	return hmac.Equal([]byte(signature),
+		[]byte(hlsSignature(songId, userId, expires, secret)))
+}
+
+func hlsSignature(songId string, userId string, expires int64, secret []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(songId + "\n" + userId + "\n" + strconv.FormatInt(expires, 10)))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// Rewrites every uri of a playlist into prefix + uri + "?" + query,
+// tags and blank lines are kept as they are.
+func SignHLSPlaylist(playlist []byte, prefix string, query string) []byte {
+	lines := strings.Split(string(playlist), "\n")
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			lines[i] = prefix + line + "?" + query
+		}
+	}
+	return []byte(strings.Join(lines, "\n"))
+}
+
+// Returns the length of a playlist in seconds,
+// the sum of the durations of its segments.
+func GetSongLengthFromM3U8(file []byte) (int, error) {
+	lines := strings.Split(string(file), "\n")
+	var length float64 = 0
+	for i, line := range lines {
+		if strings.HasPrefix(line, "#EXTINF:") {
+			partLength, err := strconv.ParseFloat(strings.Split(lines[i][8:], ",")[0], 64)
+			if err != nil {
+				return 0, err
+			}
+			length += partLength
+		}
+	}
+	return int(length), nil
+}
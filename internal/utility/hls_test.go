@@ -0,0 +1,95 @@
+package utility
+
+import (
+	"net/url"
+	"strconv"
+	"testing"
+)
+
+const (
+	testSongId = "7f1d4a5e-0c55-4b4e-9a8f-2d1c6b0e3a11"
+	testUserId = "0b6f9c2e-52a4-4d2b-8f47-9e3d1a7c5b20"
+)
+
+var testSecret = []byte("test secret")
+
+// Returns the user, expiry and signature of a signed query.
+func parseHLSQuery(t *testing.T, query string) (string, int64, string) {
+	t.Helper()
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		t.Fatalf("query %q does not parse: %v", query, err)
+	}
+	expires, err := strconv.ParseInt(values.Get("expires"), 10, 64)
+	if err != nil {
+		t.Fatalf("query %q has an invalid expiry: %v", query, err)
+	}
+	return values.Get("user"), expires, values.Get("signature")
+}
+
+func TestSignHLSQueryRoundTrip(t *testing.T) {
+	query := SignHLSQuery(testSongId, testUserId, 1700000000, testSecret)
+	userId, expires, signature := parseHLSQuery(t, query)
+
+	if userId != testUserId || expires != 1700000000 || signature == "" {
+		t.Fatalf("query %q lost its fields", query)
+	}
+	if !ValidHLSSignature(testSongId, userId, expires, signature, testSecret) {
+		t.Fatal("signature of the query is not valid")
+	}
+}
+
+func TestValidHLSSignatureRejectsTampering(t *testing.T) {
+	const expires = 1700000000
+	signature := hlsSignature(testSongId, testUserId, expires, testSecret)
+
+	tests := []struct {
+		name      string
+		songId    string
+		userId    string
+		expires   int64
+		signature string
+		secret    []byte
+	}{
+		{"other song", "9a3c2b1d-8e7f-4a6b-9c5d-1e2f3a4b5c6d", testUserId, expires, signature, testSecret},
+		{"other user", testSongId, "9a3c2b1d-8e7f-4a6b-9c5d-1e2f3a4b5c6d", expires, signature, testSecret},
+		{"later expiry", testSongId, testUserId, expires + 3600, signature, testSecret},
+		{"other secret", testSongId, testUserId, expires, signature, []byte("other secret")},
+		{"altered signature", testSongId, testUserId, expires, "A" + signature[1:], testSecret},
+		{"empty signature", testSongId, testUserId, expires, "", testSecret},
+		// The fields are separated, moving characters between them changes the signature
+		{"shifted fields", testSongId + "\n" + testUserId[:4], testUserId[4:], expires, signature, testSecret},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if ValidHLSSignature(tt.songId, tt.userId, tt.expires, tt.signature, tt.secret) {
+				t.Error("tampered signature is valid")
+			}
+		})
+	}
+}
+
+func TestSignHLSPlaylist(t *testing.T) {
+	playlist := "#EXTM3U\n" +
+		"#EXT-X-TARGETDURATION:10\n" +
+		"\n" +
+		"#EXTINF:10.0,\n" +
+		"song_128k_0.ts\n" +
+		"#EXTINF:4.5,\n" +
+		"  song_128k_1.ts  \n" +
+		"#EXT-X-ENDLIST\n"
+	want := "#EXTM3U\n" +
+		"#EXT-X-TARGETDURATION:10\n" +
+		"\n" +
+		"#EXTINF:10.0,\n" +
+		"signed/song_128k_0.ts?user=u&expires=1\n" +
+		"#EXTINF:4.5,\n" +
+		"signed/song_128k_1.ts?user=u&expires=1\n" +
+		"#EXT-X-ENDLIST\n"
+
+	got := string(SignHLSPlaylist([]byte(playlist), "signed/", "user=u&expires=1"))
+	if got != want {
+		t.Fatalf("SignHLSPlaylist() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,107 @@
+package utility
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// Returned by ParseByteRange for ranges starting past the end of the file.
+var ErrRangeNotSatisfiable = errors.New("range not satisfiable")
+
+// Returns the first and last byte of a single "bytes=first-last",
+// "bytes=first-" or "bytes=-suffix" range over size bytes,
+// the last byte is clamped to the end of the file.
+// ok is false when the header is empty, malformed or asks for
+// several ranges, the whole file should be sent then.
+// May return ErrRangeNotSatisfiable.
+func ParseByteRange(header string, size int64) (first int64, last int64, ok bool, err error) {
+	header = strings.TrimSpace(header)
+	if !strings.HasPrefix(header, "bytes=") {
+		return 0, 0, false, nil
+	}
+	spec := strings.TrimPrefix(header, "bytes=")
+	if strings.Contains(spec, ",") {
+		return 0, 0, false, nil
+	}
+	firstStr, lastStr, found := strings.Cut(strings.TrimSpace(spec), "-")
+	if !found {
+		return 0, 0, false, nil
+	}
+
+	if firstStr == "" {
+		suffix, err := strconv.ParseInt(lastStr, 10, 64)
+		if err != nil || suffix < 0 {
+			return 0, 0, false, nil
+		}
+		if suffix == 0 || size == 0 {
+			return 0, 0, false, ErrRangeNotSatisfiable
+		}
+		if suffix > size {
+			suffix = size
+		}
+		return size - suffix, size - 1, true, nil
+	}
+
+	first, err = strconv.ParseInt(firstStr, 10, 64)
+	if err != nil || first < 0 {
+		return 0, 0, false, nil
+	}
+	last = size - 1
+	if lastStr != "" {
+		last, err = strconv.ParseInt(lastStr, 10, 64)
+		if err != nil || last < first {
+			return 0, 0, false, nil
+		}
+		if last > size-1 {
+			last = size - 1
+		}
+	}
+	if first >= size {
+		return 0, 0, false, ErrRangeNotSatisfiable
+	}
+	return first, last, true, nil
+}
+
+// Reports whether an If-None-Match header matches the entity tag,
+// weak tags in the header are compared by their opaque part.
+func MatchETag(header string, etag string) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns a Content-Disposition header value offering a download
+// under the file name, with an ASCII fallback for older clients.
+func ContentDisposition(fileName string) string {
+	fallback := strings.Map(func(r rune) rune {
+		if r < 0x20 || r > 0x7E || r == '"' || r == '\\' || r == '/' {
+			return '_'
+		}
+		return r
+	}, fileName)
+	return `attachment; filename="` + fallback + `"; filename*=UTF-8''` +
+		encodeExtValue(fileName)
+}
+
+// Percent-encodes every byte outside of the RFC 5987 attr-char set.
+func encodeExtValue(value string) string {
+	const hex = "0123456789ABCDEF"
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' ||
+			strings.IndexByte("!#$&+-.^_`|~", c) >= 0 {
+			b.WriteByte(c)
+			continue
+		}
+		b.WriteByte('%')
+		b.WriteByte(hex[c>>4])
+		b.WriteByte(hex[c&0x0F])
+	}
+	return b.String()
+}
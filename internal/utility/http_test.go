@@ -0,0 +1,89 @@
+package utility
+
+import "testing"
+
+func TestParseByteRange(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		size   int64
+		first  int64
+		last   int64
+		ok     bool
+		err    error
+	}{
+		{"empty header", "", 100, 0, 0, false, nil},
+		{"other unit", "items=0-10", 100, 0, 0, false, nil},
+		{"closed range", "bytes=10-19", 100, 10, 19, true, nil},
+		{"open range", "bytes=10-", 100, 10, 99, true, nil},
+		{"last clamped", "bytes=90-200", 100, 90, 99, true, nil},
+		{"single byte", "bytes=0-0", 1, 0, 0, true, nil},
+		{"spaces", "  bytes= 5-6 ", 100, 5, 6, true, nil},
+		{"suffix", "bytes=-10", 100, 90, 99, true, nil},
+		{"suffix over size", "bytes=-500", 100, 0, 99, true, nil},
+		{"zero suffix", "bytes=-0", 100, 0, 0, false, ErrRangeNotSatisfiable},
+		{"suffix of empty file", "bytes=-10", 0, 0, 0, false, ErrRangeNotSatisfiable},
+		{"open range of empty file", "bytes=0-", 0, 0, 0, false, ErrRangeNotSatisfiable},
+		{"first at size", "bytes=100-", 100, 0, 0, false, ErrRangeNotSatisfiable},
+		{"first past size", "bytes=150-200", 100, 0, 0, false, ErrRangeNotSatisfiable},
+		{"multiple ranges", "bytes=0-10,20-30", 100, 0, 0, false, nil},
+		{"last before first", "bytes=20-10", 100, 0, 0, false, nil},
+		{"no dash", "bytes=10", 100, 0, 0, false, nil},
+		{"not a number", "bytes=a-10", 100, 0, 0, false, nil},
+		{"negative suffix", "bytes=--5", 100, 0, 0, false, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			first, last, ok, err := ParseByteRange(tt.header, tt.size)
+			if first != tt.first || last != tt.last || ok != tt.ok || err != tt.err {
+				t.Errorf("ParseByteRange(%q, %d) = %d, %d, %v, %v, want %d, %d, %v, %v",
+					tt.header, tt.size, first, last, ok, err, tt.first, tt.last, tt.ok, tt.err)
+			}
+		})
+	}
+}
+
+func TestMatchETag(t *testing.T) {
+	tests := []struct {
+		header string
+		etag   string
+		want   bool
+	}{
+		{`"abc"`, `"abc"`, true},
+		{`"abd"`, `"abc"`, false},
+		{`W/"abc"`, `"abc"`, true},
+		{`"x", W/"abc"`, `"abc"`, true},
+		{`"x","y"`, `"abc"`, false},
+		{`*`, `"abc"`, true},
+		{``, `"abc"`, false},
+		{`abc`, `"abc"`, false},
+	}
+
+	for _, tt := range tests {
+		if got := MatchETag(tt.header, tt.etag); got != tt.want {
+			t.Errorf("MatchETag(%q, %q) = %v, want %v", tt.header, tt.etag, got, tt.want)
+		}
+	}
+}
+
+func TestContentDisposition(t *testing.T) {
+	tests := []struct {
+		fileName string
+		want     string
+	}{
+		{"song.mp3", `attachment; filename="song.mp3"; filename*=UTF-8''song.mp3`},
+		{"my song.mp3", `attachment; filename="my song.mp3"; filename*=UTF-8''my%20song.mp3`},
+		{"Dvořák.flac", `attachment; filename="Dvo__k.flac"; filename*=UTF-8''Dvo%C5%99%C3%A1k.flac`},
+		{"песня.ogg", `attachment; filename="_____.ogg"; filename*=UTF-8''%D0%BF%D0%B5%D1%81%D0%BD%D1%8F.ogg`},
+		{`a"b\c/d.wav`, `attachment; filename="a_b_c_d.wav"; filename*=UTF-8''a%22b%5Cc%2Fd.wav`},
+		{"it's=1:2@x.mp3", `attachment; filename="it's=1:2@x.mp3"; filename*=UTF-8''it%27s%3D1%3A2%40x.mp3`},
+		{"line\nbreak.mp3", `attachment; filename="line_break.mp3"; filename*=UTF-8''line%0Abreak.mp3`},
+	}
+
+	for _, tt := range tests {
+		if got := ContentDisposition(tt.fileName); got != tt.want {
+			t.Errorf("ContentDisposition(%q) = %s, want %s", tt.fileName, got, tt.want)
+		}
+	}
+}
@@ -1,6 +1,12 @@
 package utility
 
-import "golang.org/x/crypto/bcrypt"
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+
+	"golang.org/x/crypto/bcrypt"
+)
 
 // Hashes a password using bcrypt.
 // Password's max length is 72.
@@ -14,3 +20,12 @@ func HashPassword(password string) (string, error) {
 func ValidatePassword(hash []byte, password []byte) error {
 	return bcrypt.CompareHashAndPassword(hash, password)
 }
+
+// Generates a random url-safe password of 24 chars.
+func GenerateRandomPassword() string {
+	b := make([]byte, 18)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Errorf("error generating password: %v", err))
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,79 @@
+package utility
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Decodes url query arguments into the struct pointed by v,
+// matching the fields by their json tag names.
+// Supports string, int and bool fields and pointers to them,
+// arguments for fields of other types are rejected.
+func DecodeQuery(args map[string]string, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("cannot decode query into %T", v)
+	}
+	return decodeQueryStruct(args, rv.Elem())
+}
+
+func decodeQueryStruct(args map[string]string, rv reflect.Value) error {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			if err := decodeQueryStruct(args, rv.Field(i)); err != nil {
+				return err
+			}
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		value, ok := args[name]
+		if !ok {
+			continue
+		}
+
+		if err := setQueryValue(rv.Field(i), value); err != nil {
+			return fmt.Errorf("invalid %q: %v", name, err)
+		}
+	}
+	return nil
+}
+
+// Parses the value into the field, pointer fields are allocated.
+func setQueryValue(f reflect.Value, value string) error {
+	switch f.Kind() {
+	case reflect.Pointer:
+		elem := reflect.New(f.Type().Elem())
+		if err := setQueryValue(elem.Elem(), value); err != nil {
+			return err
+		}
+		f.Set(elem)
+	case reflect.String:
+		f.SetString(value)
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, f.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("not an integer")
+		}
+		f.SetInt(n)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("not a boolean")
+		}
+		f.SetBool(b)
+	default:
+		return fmt.Errorf("cannot be passed in the query string")
+	}
+	return nil
+}
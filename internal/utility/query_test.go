@@ -0,0 +1,62 @@
+package utility
+
+import "testing"
+
+type QueryPage struct {
+	Cursor string `json:"cursor"`
+	Limit  int    `json:"limit"`
+}
+
+type queryInput struct {
+	QueryPage
+	UserId    string   `json:"-"`
+	Query     string   `json:"query"`
+	Suspended *bool    `json:"suspended"`
+	Verified  *bool    `json:"verified,omitempty"`
+	Hidden    bool     `json:"hidden"`
+	Links     []string `json:"links"`
+}
+
+func TestDecodeQuery(t *testing.T) {
+	var in queryInput
+	err := DecodeQuery(map[string]string{
+		"cursor": "abc", "limit": "20", "query": "bob",
+		"suspended": "true", "verified": "false", "hidden": "1", "-": "x",
+	}, &in)
+	if err != nil {
+		t.Fatalf("DecodeQuery: %v", err)
+	}
+	if in.Cursor != "abc" || in.Limit != 20 || in.Query != "bob" || !in.Hidden || in.UserId != "" {
+		t.Errorf("decoded %+v", in)
+	}
+	if in.Suspended == nil || !*in.Suspended {
+		t.Errorf("suspended = %v, want true", in.Suspended)
+	}
+	if in.Verified == nil || *in.Verified {
+		t.Errorf("verified = %v, want false", in.Verified)
+	}
+
+	var empty queryInput
+	if err := DecodeQuery(map[string]string{}, &empty); err != nil {
+		t.Fatalf("DecodeQuery: %v", err)
+	}
+	if empty.Suspended != nil || empty.Verified != nil {
+		t.Errorf("missing pointer arguments were set: %+v", empty)
+	}
+}
+
+func TestDecodeQueryRejects(t *testing.T) {
+	for _, args := range []map[string]string{
+		{"limit": "ten"},
+		{"suspended": "maybe"},
+		{"links": "a,b"},
+	} {
+		var in queryInput
+		if err := DecodeQuery(args, &in); err == nil {
+			t.Errorf("DecodeQuery(%v) = nil, want an error", args)
+		}
+	}
+	if err := DecodeQuery(map[string]string{}, queryInput{}); err == nil {
+		t.Error("DecodeQuery into a non pointer = nil, want an error")
+	}
+}
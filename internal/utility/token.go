@@ -12,16 +12,18 @@ type TokenDetails struct {
 	Token     string
 	TokenUUID string
 	UserUUID  string
+	Role      string
 	ExpiresIn int64
 }
 
-// Creates a JWT, returning its details.
-func CreateToken(uuid string, ttl time.Duration, privateKey []byte) (*TokenDetails, error) {
+// Creates a JWT with the user's role as a claim, returning its details.
+func CreateToken(uuid string, role string, ttl time.Duration, privateKey []byte) (*TokenDetails, error) {
 	now := time.Now().UTC()
 	td := &TokenDetails{
 		ExpiresIn: now.Add(ttl).Unix(),
 		TokenUUID: GenerateUUID(),
 		UserUUID:  uuid,
+		Role:      role,
 	}
 
 	key, err := jwt.ParseRSAPrivateKeyFromPEM(privateKey)
@@ -31,6 +33,7 @@ func CreateToken(uuid string, ttl time.Duration, privateKey []byte) (*TokenDetai
 
 	atClaims := make(jwt.MapClaims)
 	atClaims["sub"] = uuid
+	atClaims["role"] = role
 	atClaims["token_uuid"] = td.TokenUUID
 	atClaims["exp"] = td.ExpiresIn
 	atClaims["iat"] = now.Unix()
@@ -44,7 +47,8 @@ func CreateToken(uuid string, ttl time.Duration, privateKey []byte) (*TokenDetai
 }
 
 // Validates a JWT, returning its details, containing
-// only UserUUID and TokenUUID.
+// only UserUUID, Role and TokenUUID.
+// Role is empty for tokens issued without one.
 func ValidateToken(token string, publicKey []byte) (*TokenDetails, error) {
 	key, err := jwt.ParseRSAPublicKeyFromPEM(publicKey)
 	if err != nil {
@@ -67,8 +71,11 @@ func ValidateToken(token string, publicKey []byte) (*TokenDetails, error) {
 		return nil, fmt.Errorf("validation error: invalid token")
 	}
 
+	role, _ := claims["role"].(string)
+
 	return &TokenDetails{
 		UserUUID:  fmt.Sprint(claims["sub"]),
+		Role:      role,
 		TokenUUID: fmt.Sprint(claims["token_uuid"]),
 	}, nil
 }
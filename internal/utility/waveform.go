@@ -0,0 +1,59 @@
+package utility
+
+import (
+	"encoding/binary"
+	"os/exec"
+)
+
+// The number of min/max pairs of a song waveform.
+const WaveformPoints = 1000
+
+// Decodes an audio file and returns at most points min/max pairs
+// of its samples, as signed bytes: min0, max0, min1, max1...
+func ComputeWaveform(path string, points int) ([]byte, error) {
+	// Mono 16 bit samples at a rate far above what a waveform shows
+	out, err := exec.Command(
+		"ffmpeg",
+		"-v", "error",
+		"-i", path,
+		"-vn",
+		"-ac", "1",
+		"-ar", "8000",
+		"-f", "s16le",
+		"-",
+	).Output()
+	if err != nil {
+		return nil, err
+	}
+
+	samples := len(out) / 2
+	if samples < points {
+		points = samples
+	}
+
+	peaks := make([]byte, 0, points*2)
+	for i := 0; i < points; i++ {
+		start, end := i*samples/points, (i+1)*samples/points
+		min, max := int16(0), int16(0)
+		for j := start; j < end; j++ {
+			s := int16(binary.LittleEndian.Uint16(out[j*2:]))
+			if s < min {
+				min = s
+			}
+			if s > max {
+				max = s
+			}
+		}
+		peaks = append(peaks, byte(int8(min>>8)), byte(int8(max>>8)))
+	}
+	return peaks, nil
+}
+
+// Returns the min/max pairs of a waveform computed by ComputeWaveform.
+func WaveformPairs(peaks []byte) [][2]int8 {
+	pairs := make([][2]int8, 0, len(peaks)/2)
+	for i := 0; i+1 < len(peaks); i += 2 {
+		pairs = append(pairs, [2]int8{int8(peaks[i]), int8(peaks[i+1])})
+	}
+	return pairs
+}
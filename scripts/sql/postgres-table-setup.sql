@@ -5,9 +5,19 @@ CREATE TABLE IF NOT EXISTS users (
   name VARCHAR(100) NOT NULL,
   email VARCHAR(100) NOT NULL UNIQUE,
   password BYTEA NOT NULL,
-  picture BYTEA,
   verified BOOLEAN,
-  public_key BYTEA
+  public_key BYTEA,
+  role VARCHAR(20) NOT NULL DEFAULT 'user',
+  suspended BOOLEAN NOT NULL DEFAULT FALSE,
+  handle VARCHAR(30) UNIQUE,
+  handle_changed_at TIMESTAMP,
+  bio VARCHAR(500) NOT NULL DEFAULT '',
+  location VARCHAR(100) NOT NULL DEFAULT '',
+  links TEXT[] NOT NULL DEFAULT '{}',
+  discoverable BOOLEAN NOT NULL DEFAULT TRUE,
+  friend_requests_from VARCHAR(20) NOT NULL DEFAULT 'everyone',
+  picture_visibility VARCHAR(20) NOT NULL DEFAULT 'everyone',
+  activity_visibility VARCHAR(20) NOT NULL DEFAULT 'friends'
 );
 
 CREATE TABLE IF NOT EXISTS friend_requests (
@@ -28,14 +38,35 @@ CREATE TABLE IF NOT EXISTS friendships (
   FOREIGN KEY (user2_id) REFERENCES users (id)
 );
 
+CREATE TABLE IF NOT EXISTS albums (
+  id UUID NOT NULL PRIMARY KEY,
+  creator_id UUID NOT NULL REFERENCES users (id),
+  name VARCHAR(100) NOT NULL,
+  type VARCHAR(10) NOT NULL,
+  release_date DATE NOT NULL,
+  created_at TIMESTAMP NOT NULL
+);
+
 CREATE TABLE IF NOT EXISTS songs (
   id UUID NOT NULL PRIMARY KEY,
   creator_id UUID REFERENCES users (id),
   name VARCHAR(100) NOT NULL,
   length INTEGER NOT NULL,
+  artist VARCHAR(100) NOT NULL DEFAULT '',
+  album VARCHAR(100) NOT NULL DEFAULT '',
+  track_number INTEGER NOT NULL DEFAULT 0,
+  year INTEGER NOT NULL DEFAULT 0,
+  genre VARCHAR(50) NOT NULL DEFAULT '',
+  duration DOUBLE PRECISION NOT NULL DEFAULT 0,
+  album_id UUID REFERENCES albums (id) ON DELETE SET NULL,
+  loudness DOUBLE PRECISION,
+  true_peak DOUBLE PRECISION,
+  track_gain DOUBLE PRECISION,
+  normalized BOOLEAN NOT NULL DEFAULT FALSE,
   streams INTEGER,
-  picture BYTEA,
-  file BYTEA
+  waveform BYTEA,
+  hidden BOOLEAN NOT NULL DEFAULT FALSE,
+  created_at TIMESTAMP
 );
 
 CREATE TABLE IF NOT EXISTS playlists (
@@ -51,4 +82,149 @@ CREATE TABLE IF NOT EXISTS read_times (
   PRIMARY KEY (user_id, chat_id),
   FOREIGN KEY (user_id) REFERENCES users (id),
   FOREIGN KEY (chat_id) REFERENCES friendships (chat_id)
-);
\ No newline at end of file
+);
+
+CREATE TABLE IF NOT EXISTS blocks (
+  blocker_id UUID NOT NULL,
+  blocked_id UUID NOT NULL,
+  PRIMARY KEY (blocker_id, blocked_id),
+  FOREIGN KEY (blocker_id) REFERENCES users (id),
+  FOREIGN KEY (blocked_id) REFERENCES users (id)
+);
+
+CREATE TABLE IF NOT EXISTS chat_mutes (
+  user_id UUID NOT NULL,
+  chat_id UUID NOT NULL,
+  PRIMARY KEY (user_id, chat_id),
+  FOREIGN KEY (user_id) REFERENCES users (id),
+  FOREIGN KEY (chat_id) REFERENCES friendships (chat_id)
+);
+
+CREATE TABLE IF NOT EXISTS listens (
+  user_id UUID NOT NULL REFERENCES users (id),
+  song_id UUID NOT NULL REFERENCES songs (id) ON DELETE CASCADE,
+  listened_at BIGINT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS listens_user_id_idx ON listens (user_id, listened_at DESC);
+
+CREATE INDEX IF NOT EXISTS listens_song_id_idx ON listens (song_id, user_id);
+
+CREATE INDEX IF NOT EXISTS friendships_user2_id_idx ON friendships (user2_id);
+
+CREATE TABLE IF NOT EXISTS follows (
+  follower_id UUID NOT NULL,
+  followee_id UUID NOT NULL,
+  created_at TIMESTAMP NOT NULL,
+  PRIMARY KEY (follower_id, followee_id),
+  FOREIGN KEY (follower_id) REFERENCES users (id),
+  FOREIGN KEY (followee_id) REFERENCES users (id)
+);
+
+CREATE INDEX IF NOT EXISTS follows_followee_id_idx ON follows (followee_id);
+
+CREATE TABLE IF NOT EXISTS upload_jobs (
+  id UUID NOT NULL PRIMARY KEY,
+  user_id UUID NOT NULL REFERENCES users (id),
+  song_id UUID NOT NULL,
+  song_name VARCHAR(100) NOT NULL,
+  status VARCHAR(20) NOT NULL,
+  error TEXT NOT NULL DEFAULT '',
+  created_at TIMESTAMP NOT NULL,
+  updated_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS upload_jobs_status_idx ON upload_jobs (status);
+
+CREATE TABLE IF NOT EXISTS reports (
+  id UUID NOT NULL PRIMARY KEY,
+  reporter_id UUID NOT NULL REFERENCES users (id),
+  target_type VARCHAR(20) NOT NULL,
+  target_id UUID NOT NULL,
+  message_id BIGINT NOT NULL DEFAULT 0,
+  reason VARCHAR(1000) NOT NULL,
+  status VARCHAR(20) NOT NULL DEFAULT 'open',
+  created_at TIMESTAMP NOT NULL,
+  resolved_by UUID REFERENCES users (id),
+  resolution VARCHAR(1000) NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS audit_log (
+  id UUID NOT NULL PRIMARY KEY,
+  moderator_id UUID REFERENCES users (id),
+  action VARCHAR(50) NOT NULL,
+  target_type VARCHAR(20) NOT NULL,
+  target_id UUID NOT NULL,
+  details VARCHAR(1000) NOT NULL DEFAULT '',
+  created_at TIMESTAMP NOT NULL
+);
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role VARCHAR(20) NOT NULL DEFAULT 'user';
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS suspended BOOLEAN NOT NULL DEFAULT FALSE;
+
+ALTER TABLE songs ADD COLUMN IF NOT EXISTS hidden BOOLEAN NOT NULL DEFAULT FALSE;
+
+ALTER TABLE audit_log ALTER COLUMN moderator_id DROP NOT NULL;
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS handle VARCHAR(30) UNIQUE;
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS handle_changed_at TIMESTAMP;
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS bio VARCHAR(500) NOT NULL DEFAULT '';
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS location VARCHAR(100) NOT NULL DEFAULT '';
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS links TEXT[] NOT NULL DEFAULT '{}';
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS discoverable BOOLEAN NOT NULL DEFAULT TRUE;
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS friend_requests_from VARCHAR(20) NOT NULL DEFAULT 'everyone';
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS picture_visibility VARCHAR(20) NOT NULL DEFAULT 'everyone';
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS activity_visibility VARCHAR(20) NOT NULL DEFAULT 'friends';
+
+ALTER TABLE songs ADD COLUMN IF NOT EXISTS created_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS songs_creator_id_idx ON songs (creator_id, created_at DESC);
+
+CREATE INDEX IF NOT EXISTS songs_streams_idx ON songs ((COALESCE(streams, 0)) DESC, id DESC);
+
+CREATE INDEX IF NOT EXISTS playlists_user_id_idx ON playlists (user_id, id);
+
+CREATE INDEX IF NOT EXISTS friend_requests_recipient_id_idx ON friend_requests (recipient_id, sender_id);
+
+ALTER TABLE songs ADD COLUMN IF NOT EXISTS artist VARCHAR(100) NOT NULL DEFAULT '';
+
+ALTER TABLE songs ADD COLUMN IF NOT EXISTS album VARCHAR(100) NOT NULL DEFAULT '';
+
+ALTER TABLE songs ADD COLUMN IF NOT EXISTS track_number INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE songs ADD COLUMN IF NOT EXISTS year INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE songs ADD COLUMN IF NOT EXISTS genre VARCHAR(50) NOT NULL DEFAULT '';
+
+ALTER TABLE songs ADD COLUMN IF NOT EXISTS duration DOUBLE PRECISION NOT NULL DEFAULT 0;
+
+ALTER TABLE songs ADD COLUMN IF NOT EXISTS album_id UUID REFERENCES albums (id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS songs_album_id_idx ON songs (album_id, track_number);
+
+CREATE INDEX IF NOT EXISTS albums_release_date_idx ON albums (release_date DESC, id DESC);
+
+ALTER TABLE songs ADD COLUMN IF NOT EXISTS loudness DOUBLE PRECISION;
+
+ALTER TABLE songs ADD COLUMN IF NOT EXISTS true_peak DOUBLE PRECISION;
+
+ALTER TABLE songs ADD COLUMN IF NOT EXISTS track_gain DOUBLE PRECISION;
+
+ALTER TABLE songs ADD COLUMN IF NOT EXISTS normalized BOOLEAN NOT NULL DEFAULT FALSE;
+
+ALTER TABLE songs ADD COLUMN IF NOT EXISTS waveform BYTEA;
+
+CREATE TABLE IF NOT EXISTS account_cleanups (
+  kind VARCHAR(20) NOT NULL,
+  target_id UUID NOT NULL,
+  PRIMARY KEY (kind, target_id)
+);